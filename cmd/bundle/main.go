@@ -6,6 +6,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -20,14 +21,33 @@ import (
 	"github.com/spf13/pflag"
 
 	"github.com/shipwright-io/build/pkg/bundle"
+	"github.com/shipwright-io/build/pkg/image"
+)
+
+// progressInterval is the number of bytes after which the extraction progress is logged
+const progressInterval = 256 * 1024 * 1024
+
+// Reasons of a failed bundle step, which are written to the error reason result
+const (
+	reasonDigestMismatch    = "BundleDigestMismatch"
+	reasonSignatureNotFound = "BundleSignatureNotFound"
+	reasonSignatureInvalid  = "BundleSignatureInvalid"
+	reasonError             = "BundleError"
 )
 
 type settings struct {
-	help                  bool
-	image                 string
-	target                string
-	secretPath            string
-	resultFileImageDigest string
+	help                   bool
+	image                  string
+	target                 string
+	digest                 string
+	publicKey              string
+	prune                  bool
+	secretPath             string
+	caBundle               string
+	resultFileImageDigest  string
+	resultFileVerification string
+	resultFileErrorMessage string
+	resultFileErrorReason  string
 }
 
 var flagValues settings
@@ -40,12 +60,27 @@ func init() {
 	pflag.StringVar(&flagValues.image, "image", "", "Location of the bundle image (mandatory)")
 	pflag.StringVar(&flagValues.target, "target", "/workspace/source", "The target directory to place the code")
 	pflag.StringVar(&flagValues.resultFileImageDigest, "result-file-image-digest", "", "A file to write the image digest")
+	pflag.BoolVar(&flagValues.prune, "prune", false, "Delete the bundle image from the registry after it was pulled")
+
+	// Flags for the verification of the bundle image before it is unpacked
+	pflag.StringVar(&flagValues.digest, "digest", "", "The expected digest of the bundle image in the format sha256:<hex> (optional)")
+	pflag.StringVar(&flagValues.publicKey, "public-key", "", "A file with the PEM encoded public key that the bundle image must be signed with (optional)")
+	pflag.StringVar(&flagValues.resultFileVerification, "result-file-verification", "", "A file to write the comma separated list of verifications that succeeded")
+
+	// Flags with paths for writing error related information
+	pflag.StringVar(&flagValues.resultFileErrorMessage, "result-file-error-message", "", "A file to write the error message to")
+	pflag.StringVar(&flagValues.resultFileErrorReason, "result-file-error-reason", "", "A file to write the error reason to")
 
 	pflag.StringVar(&flagValues.secretPath, "secret-path", "", "A directory that contains access credentials (optional)")
+	pflag.StringVar(&flagValues.caBundle, "ca-bundle", "", "A file with PEM encoded certificates to trust in addition to the system certificates (optional)")
 }
 
 func main() {
 	if err := Do(context.Background()); err != nil {
+		if err := writeErrorResults(errorReason(err), err.Error()); err != nil {
+			log.Printf("Could not write error results: %s", err.Error())
+		}
+
 		log.Fatal(err.Error())
 	}
 }
@@ -74,19 +109,72 @@ func Do(ctx context.Context) error {
 		return err
 	}
 
+	transport, err := image.NewTransport(flagValues.caBundle)
+	if err != nil {
+		return err
+	}
+
+	options := []remote.Option{
+		remote.WithContext(ctx),
+		remote.WithAuth(auth),
+		remote.WithTransport(transport),
+	}
+
+	verifyOptions := bundle.VerifyOptions{Digest: flagValues.digest}
+	if flagValues.publicKey != "" {
+		data, err := ioutil.ReadFile(flagValues.publicKey)
+		if err != nil {
+			return err
+		}
+
+		if verifyOptions.PublicKey, err = bundle.LoadPublicKey(data); err != nil {
+			return err
+		}
+	}
+
+	// the image is verified before anything is unpacked, and pulled by its
+	// digest afterwards, so that it cannot change in between
+	verification, err := bundle.Verify(ref, verifyOptions, options...)
+	if err != nil {
+		return err
+	}
+
+	if verification.DigestVerified {
+		log.Printf("Verified the digest %s of image %q", flagValues.digest, ref)
+	}
+
+	if verification.SignatureVerified {
+		log.Printf("Verified the signature of image %q", ref)
+	}
+
 	log.Printf("Pulling image %q", ref)
 
-	img, err := bundle.PullAndUnpack(
-		ref,
+	img, err := bundle.PullAndUnpackWithProgress(
+		verification.Reference,
 		flagValues.target,
-		remote.WithContext(ctx),
-		remote.WithAuth(auth))
+		logProgress(),
+		options...)
 	if err != nil {
 		return err
 	}
 
 	log.Printf("Image content was extracted to %s\n", flagValues.target)
 
+	if flagValues.prune {
+		// the image is not needed anymore once its content is extracted, a
+		// registry that does not support deletion does not fail the build
+		switch err := bundle.Prune(ref, verification.Reference, options...); {
+		case errors.Is(err, bundle.ErrDeletionUnsupported):
+			log.Printf("Warning: image %q was not deleted: %v\n", ref, err)
+
+		case err != nil:
+			return fmt.Errorf("deleting bundle image: %w", err)
+
+		default:
+			log.Printf("Deleted image %q\n", ref)
+		}
+	}
+
 	if flagValues.resultFileImageDigest != "" {
 		digest, err := (*img).Digest()
 		if err != nil {
@@ -98,9 +186,73 @@ func Do(ctx context.Context) error {
 		}
 	}
 
+	if flagValues.resultFileVerification != "" {
+		var verified []string
+		if verification.DigestVerified {
+			verified = append(verified, "digest")
+		}
+
+		if verification.SignatureVerified {
+			verified = append(verified, "signature")
+		}
+
+		if err = ioutil.WriteFile(flagValues.resultFileVerification, []byte(strings.Join(verified, ",")), 0644); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// errorReason returns the reason of an error that is written to the error
+// reason result
+func errorReason(err error) string {
+	switch {
+	case errors.Is(err, bundle.ErrDigestMismatch):
+		return reasonDigestMismatch
+
+	case errors.Is(err, bundle.ErrSignatureNotFound):
+		return reasonSignatureNotFound
+
+	case errors.Is(err, bundle.ErrSignatureInvalid):
+		return reasonSignatureInvalid
+
+	default:
+		return reasonError
+	}
+}
+
+func writeErrorResults(reason string, message string) error {
+	if flagValues.resultFileErrorReason == "" || flagValues.resultFileErrorMessage == "" {
+		return nil
+	}
+
+	messageToWrite := message
+	messageLengthThreshold := 300
+
+	if len(messageToWrite) > messageLengthThreshold {
+		messageToWrite = messageToWrite[:messageLengthThreshold-3] + "..."
+	}
+
+	if err := ioutil.WriteFile(flagValues.resultFileErrorMessage, []byte(strings.TrimSpace(messageToWrite)), 0666); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(flagValues.resultFileErrorReason, []byte(reason), 0666)
+}
+
+// logProgress returns a progress function that logs whenever a further
+// progressInterval of bytes was extracted
+func logProgress() bundle.ProgressFunc {
+	var reported int64
+	return func(processed int64) {
+		if processed-reported >= progressInterval {
+			reported = processed
+			log.Printf("Extracted %d MiB of the image content\n", processed/(1024*1024))
+		}
+	}
+}
+
 func resolveAuthBasedOnTarget(ref name.Reference) (authn.Authenticator, error) {
 	// In case no secret is mounted, use anonymous
 	if flagValues.secretPath == "" {
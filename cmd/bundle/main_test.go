@@ -6,10 +6,17 @@ package main_test
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
 	"io/ioutil"
 	"log"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -17,8 +24,11 @@ import (
 	. "github.com/shipwright-io/build/cmd/bundle"
 
 	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
 	containerreg "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
+
+	"github.com/shipwright-io/build/pkg/bundle"
 )
 
 var _ = Describe("Bundle Loader", func() {
@@ -121,4 +131,113 @@ var _ = Describe("Bundle Loader", func() {
 			})
 		})
 	})
+
+	Context("Verifying the image", func() {
+		var withPushedBundle = func(f func(ref name.Reference, digest name.Digest)) {
+			server := httptest.NewServer(registry.New(registry.Logger(log.New(ioutil.Discard, "", 0))))
+			defer server.Close()
+
+			ref, err := name.ParseReference(strings.TrimPrefix(server.URL, "http://") + "/test/bundle:latest")
+			Expect(err).ToNot(HaveOccurred())
+
+			digest, err := bundle.PackAndPush(ref, "../../test/bundle")
+			Expect(err).ToNot(HaveOccurred())
+
+			f(ref, digest)
+		}
+
+		var withPublicKeyFile = func(key *ecdsa.PrivateKey, f func(filename string)) {
+			data, err := x509.MarshalPKIXPublicKey(key.Public())
+			Expect(err).ToNot(HaveOccurred())
+
+			withTempFile("cosign.pub", func(filename string) {
+				Expect(ioutil.WriteFile(filename, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: data}), 0644)).To(Succeed())
+				f(filename)
+			})
+		}
+
+		It("should verify the digest and the signature before unpacking", func() {
+			withPushedBundle(func(ref name.Reference, digest name.Digest) {
+				key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(bundle.Sign(digest, key)).To(Succeed())
+
+				withPublicKeyFile(key, func(publicKey string) {
+					withTempDir(func(target string) {
+						withTempFile("verification", func(filename string) {
+							Expect(run(
+								"--image", ref.String(),
+								"--target", target,
+								"--digest", digest.DigestStr(),
+								"--public-key", publicKey,
+								"--result-file-verification", filename,
+							)).ToNot(HaveOccurred())
+
+							Expect(filecontent(filename)).To(Equal("digest,signature"))
+							Expect(filepath.Join(target, "README.md")).To(BeAnExistingFile())
+						})
+					})
+				})
+			})
+		})
+
+		It("should fail without unpacking in case the digest does not match", func() {
+			withPushedBundle(func(ref name.Reference, _ name.Digest) {
+				withTempDir(func(target string) {
+					err := run(
+						"--image", ref.String(),
+						"--target", target,
+						"--digest", "sha256:"+strings.Repeat("0", 64),
+					)
+					Expect(err).To(MatchError(bundle.ErrDigestMismatch))
+					Expect(filepath.Join(target, "README.md")).ToNot(BeAnExistingFile())
+				})
+			})
+		})
+
+		It("should fail without unpacking in case the image is not signed", func() {
+			withPushedBundle(func(ref name.Reference, _ name.Digest) {
+				key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+				Expect(err).ToNot(HaveOccurred())
+
+				withPublicKeyFile(key, func(publicKey string) {
+					withTempDir(func(target string) {
+						err := run(
+							"--image", ref.String(),
+							"--target", target,
+							"--public-key", publicKey,
+						)
+						Expect(err).To(MatchError(bundle.ErrSignatureNotFound))
+						Expect(filepath.Join(target, "README.md")).ToNot(BeAnExistingFile())
+					})
+				})
+			})
+		})
+	})
+
+	Context("Pruning the image", func() {
+		It("should delete the image after it was pulled", func() {
+			server := httptest.NewServer(registry.New(registry.Logger(log.New(ioutil.Discard, "", 0))))
+			defer server.Close()
+
+			ref, err := name.ParseReference(strings.TrimPrefix(server.URL, "http://") + "/test/bundle:latest")
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = bundle.PackAndPush(ref, "../../test/bundle")
+			Expect(err).ToNot(HaveOccurred())
+
+			withTempDir(func(target string) {
+				Expect(run(
+					"--image", ref.String(),
+					"--target", target,
+					"--prune",
+				)).ToNot(HaveOccurred())
+
+				Expect(filepath.Join(target, "README.md")).To(BeAnExistingFile())
+
+				_, err := remote.Head(ref)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
 })
@@ -54,6 +54,7 @@ type settings struct {
 	resultFileCommitAuthor string
 	resultFileBranchName   string
 	secretPath             string
+	caBundle               string
 	skipValidation         bool
 	gitURLRewrite          bool
 	resultFileErrorMessage string
@@ -63,6 +64,16 @@ type settings struct {
 var flagValues settings
 
 var (
+	// well-known locations of the system certificates of different distributions
+	systemCABundles = []string{
+		"/etc/ssl/certs/ca-certificates.crt",
+		"/etc/pki/tls/certs/ca-bundle.crt",
+		"/etc/ssl/ca-bundle.pem",
+		"/etc/pki/tls/cacert.pem",
+		"/etc/pki/ca-trust/extracted/pem/tls-ca-bundle.pem",
+		"/etc/ssl/cert.pem",
+	}
+
 	sshGitURLRegEx = regexp.MustCompile(`^(git@|ssh:\/\/).+$`)
 	commitShaRegEx = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
 )
@@ -81,6 +92,7 @@ func init() {
 	pflag.StringVar(&flagValues.resultFileCommitAuthor, "result-file-commit-author", "", "A file to write the commit author to.")
 	pflag.StringVar(&flagValues.resultFileBranchName, "result-file-branch-name", "", "A file to write the branch name to.")
 	pflag.StringVar(&flagValues.secretPath, "secret-path", "", "A directory that contains a secret. Either username and password for basic authentication. Or a SSH private key and optionally a known hosts file. Optional.")
+	pflag.StringVar(&flagValues.caBundle, "ca-bundle", "", "A file with PEM encoded certificates to verify the Git server with. Optional.")
 
 	// Flags with paths for writing error related information
 	pflag.StringVar(&flagValues.resultFileErrorMessage, "result-file-error-message", "", "A file to write the error message to.")
@@ -257,6 +269,22 @@ func clone(ctx context.Context) error {
 	}
 
 	var addtlGitArgs []string
+	if flagValues.caBundle != "" {
+		// Git replaces its default certificates with the configured ones,
+		// therefore combine both so that public servers remain trusted
+		caBundleFile, err := combineCABundle(flagValues.caBundle)
+		if err != nil {
+			return err
+		}
+
+		defer os.Remove(caBundleFile)
+
+		addtlGitArgs = append(addtlGitArgs,
+			"-c",
+			fmt.Sprintf("http.sslCAInfo=%s", caBundleFile),
+		)
+	}
+
 	if flagValues.secretPath != "" {
 		credType, err := checkCredentials()
 		if err != nil {
@@ -452,6 +480,42 @@ func git(ctx context.Context, args ...string) (string, error) {
 	return output, err
 }
 
+// combineCABundle writes a temporary file with the system certificates and the
+// certificates of the provided CA bundle, and returns its name
+func combineCABundle(caBundle string) (string, error) {
+	data, err := ioutil.ReadFile(caBundle)
+	if err != nil {
+		return "", err
+	}
+
+	for _, systemCABundle := range systemCABundles {
+		if systemData, err := ioutil.ReadFile(systemCABundle); err == nil {
+			data = append(append(systemData, '\n'), data...)
+			break
+		}
+	}
+
+	caBundleFile, err := ioutil.TempFile(os.TempDir(), "ca-bundle")
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := caBundleFile.Write(data); err != nil {
+		caBundleFile.Close()
+		return "", err
+	}
+
+	if err := caBundleFile.Close(); err != nil {
+		return "", err
+	}
+
+	if err := os.Chmod(caBundleFile.Name(), 0400); err != nil {
+		return "", err
+	}
+
+	return caBundleFile.Name(), nil
+}
+
 func hasFile(elem ...string) bool {
 	_, err := os.Stat(filepath.Join(elem...))
 	return !os.IsNotExist(err)
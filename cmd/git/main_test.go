@@ -103,6 +103,16 @@ var _ = Describe("Git Resource", func() {
 				})
 			})
 		})
+
+		It("should fail in case the CA bundle does not exist", func() {
+			withTempDir(func(target string) {
+				Expect(run(
+					"--url", "https://github.com/foo/bar",
+					"--target", target,
+					"--ca-bundle", filepath.Join(target, "does-not-exist.crt"),
+				)).To(HaveOccurred())
+			})
+		})
 	})
 
 	Context("cloning publically available repositories", func() {
@@ -0,0 +1,158 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Supported archive formats
+const (
+	formatTar   = "tar"
+	formatTarGz = "tar.gz"
+	formatZip   = "zip"
+)
+
+// extract unpacks the archive file of the given format into the target directory
+func extract(file *os.File, format string, target string) error {
+	switch format {
+	case formatTar:
+		return extractTar(file, target)
+
+	case formatTarGz:
+		gzipReader, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer gzipReader.Close()
+
+		return extractTar(gzipReader, target)
+
+	case formatZip:
+		info, err := file.Stat()
+		if err != nil {
+			return err
+		}
+
+		return extractZip(file, info.Size(), target)
+	}
+
+	return fmt.Errorf("unsupported archive format %q", format)
+}
+
+func extractTar(in io.Reader, target string) error {
+	tarReader := tar.NewReader(in)
+	for {
+		header, err := tarReader.Next()
+		switch {
+		case err == io.EOF:
+			return nil
+
+		case err != nil:
+			return err
+		}
+
+		// the pax_global_header entry of archives of git archive, for example the source
+		// tarballs of GitHub and GitLab, only holds metadata
+		if header.Typeflag == tar.TypeXGlobalHeader {
+			continue
+		}
+
+		path, err := safeJoin(target, header.Name)
+		if err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, 0755); err != nil {
+				return err
+			}
+
+		case tar.TypeReg:
+			if err := writeFile(path, os.FileMode(header.Mode), tarReader); err != nil {
+				return err
+			}
+
+		default:
+			// links and special files are not supported in downloaded archives
+			return fmt.Errorf("unsupported type of entry %s", header.Name)
+		}
+	}
+}
+
+func extractZip(in io.ReaderAt, size int64, target string) error {
+	zipReader, err := zip.NewReader(in, size)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range zipReader.File {
+		path, err := safeJoin(target, entry.Name)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case entry.FileInfo().IsDir():
+			if err := os.MkdirAll(path, 0755); err != nil {
+				return err
+			}
+
+		case entry.Mode().IsRegular():
+			reader, err := entry.Open()
+			if err != nil {
+				return err
+			}
+
+			err = writeFile(path, entry.Mode(), reader)
+			reader.Close()
+			if err != nil {
+				return err
+			}
+
+		default:
+			return fmt.Errorf("unsupported type of entry %s", entry.Name)
+		}
+	}
+
+	return nil
+}
+
+// safeJoin joins the name of an archive entry onto the target directory and
+// rejects names that would end up outside of it
+func safeJoin(target string, name string) (string, error) {
+	path := filepath.Join(target, name)
+	if path != filepath.Clean(target) && !strings.HasPrefix(path, filepath.Clean(target)+string(os.PathSeparator)) {
+		return "", fmt.Errorf("entry %s points outside of the target directory", name)
+	}
+
+	return path, nil
+}
+
+func writeFile(path string, mode os.FileMode, in io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode.Perm()|0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, in); err != nil {
+		return err
+	}
+
+	return file.Close()
+}
@@ -0,0 +1,456 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/shipwright-io/build/pkg/image"
+)
+
+// Reasons of a failed download, which are written to the error reason result
+const (
+	reasonNotFound             = "HTTPNotFound"
+	reasonAuthenticationFailed = "HTTPAuthenticationFailed"
+	reasonServerError          = "HTTPServerError"
+	reasonConnectionFailed     = "HTTPConnectionFailed"
+	reasonDigestMismatch       = "HTTPDigestMismatch"
+	reasonExtractionFailed     = "HTTPExtractionFailed"
+	reasonError                = "HTTPError"
+)
+
+// ExitError is an error which has an exit code to be used in os.Exit() to
+// return both an exit code and an error message
+type ExitError struct {
+	Code    int
+	Message string
+	Reason  string
+	Cause   error
+}
+
+func (e ExitError) Error() string {
+	return fmt.Sprintf("%s (exit code %d)", e.Message, e.Code)
+}
+
+type settings struct {
+	help                   bool
+	url                    string
+	target                 string
+	sha256                 string
+	extract                string
+	header                 []string
+	secretPath             string
+	caBundle               string
+	retries                uint
+	retryDelay             time.Duration
+	timeout                time.Duration
+	resultFileDigest       string
+	resultFileErrorMessage string
+	resultFileErrorReason  string
+}
+
+var flagValues settings
+
+func init() {
+	// Explicitly define the help flag so that --help can be invoked and returns status code 0
+	pflag.BoolVar(&flagValues.help, "help", false, "Print the help")
+
+	// Main flags for the download
+	pflag.StringVar(&flagValues.url, "url", "", "The URL of the file to download")
+	pflag.StringVar(&flagValues.target, "target", "", "The target directory to place the file, or the content of the archive, in")
+	pflag.StringVar(&flagValues.sha256, "sha256", "", "The expected SHA-256 digest of the file, in hex encoding. Optional.")
+	pflag.StringVar(&flagValues.extract, "extract", "", "The archive format to extract the file with, one of tar, tar.gz, or zip. Optional.")
+	pflag.StringArrayVar(&flagValues.header, "header", nil, "A request header in the form 'Name: value'. Optional, can be specified multiple times.")
+	pflag.StringVar(&flagValues.secretPath, "secret-path", "", "A directory that contains a secret. Either username and password for basic authentication, a token for bearer authentication, or a headers file. Optional.")
+	pflag.StringVar(&flagValues.caBundle, "ca-bundle", "", "A file with PEM encoded certificates to trust in addition to the system certificates. Optional.")
+	pflag.StringVar(&flagValues.resultFileDigest, "result-file-digest", "", "A file to write the SHA-256 digest of the downloaded file to.")
+
+	// Flags with paths for writing error related information
+	pflag.StringVar(&flagValues.resultFileErrorMessage, "result-file-error-message", "", "A file to write the error message to.")
+	pflag.StringVar(&flagValues.resultFileErrorReason, "result-file-error-reason", "", "A file to write the error reason to.")
+
+	// Flags to control the request behavior
+	pflag.UintVar(&flagValues.retries, "retries", 3, "The number of retries for connection failures and server errors")
+	pflag.DurationVar(&flagValues.retryDelay, "retry-delay", time.Second, "The delay before the first retry, it doubles with every retry")
+	pflag.DurationVar(&flagValues.timeout, "timeout", 0, "The maximum duration of the download including all retries, zero means no timeout")
+}
+
+func main() {
+	if err := Execute(context.Background()); err != nil {
+		var exitcode = 1
+		var reason = reasonError
+		switch err := err.(type) {
+		case *ExitError:
+			exitcode = err.Code
+			reason = err.Reason
+		}
+
+		if err := writeErrorResults(reason, err.Error()); err != nil {
+			log.Printf("Could not write error results: %s", err.Error())
+		}
+
+		log.Print(err.Error())
+		os.Exit(exitcode)
+	}
+}
+
+// Execute performs flag parsing, input validation and the download
+func Execute(ctx context.Context) error {
+	flagValues = settings{retries: 3, retryDelay: time.Second}
+	pflag.Parse()
+
+	if flagValues.help {
+		pflag.Usage()
+		return nil
+	}
+
+	if flagValues.url == "" {
+		return &ExitError{Code: 100, Message: "the 'url' argument must not be empty", Reason: reasonError}
+	}
+
+	if flagValues.target == "" {
+		return &ExitError{Code: 100, Message: "the 'target' argument must not be empty", Reason: reasonError}
+	}
+
+	switch flagValues.extract {
+	case "", formatTar, formatTarGz, formatZip:
+	default:
+		return &ExitError{Code: 100, Message: fmt.Sprintf("unsupported archive format %q", flagValues.extract), Reason: reasonError}
+	}
+
+	if flagValues.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, flagValues.timeout)
+		defer cancel()
+	}
+
+	return runDownload(ctx)
+}
+
+func runDownload(ctx context.Context) error {
+	header, err := requestHeader()
+	if err != nil {
+		return err
+	}
+
+	transport, err := image.NewTransport(flagValues.caBundle)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(flagValues.target, 0755); err != nil {
+		return err
+	}
+
+	file, err := ioutil.TempFile(os.TempDir(), "download")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	digest, err := downloadWithRetries(ctx, &http.Client{Transport: transport}, header, file)
+	if err != nil {
+		return err
+	}
+
+	if flagValues.sha256 != "" && !strings.EqualFold(strings.TrimPrefix(flagValues.sha256, "sha256:"), digest) {
+		return &ExitError{
+			Code:    120,
+			Message: fmt.Sprintf("the SHA-256 digest %s of %s does not match the expected digest %s", digest, flagValues.url, flagValues.sha256),
+			Reason:  reasonDigestMismatch,
+		}
+	}
+
+	if flagValues.extract != "" {
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+
+		if err := extract(file, flagValues.extract, flagValues.target); err != nil {
+			return &ExitError{
+				Code:    121,
+				Message: fmt.Sprintf("failed to extract %s as %s: %v", flagValues.url, flagValues.extract, err),
+				Reason:  reasonExtractionFailed,
+				Cause:   err,
+			}
+		}
+
+		log.Printf("Successfully extracted %s into %s\n", flagValues.url, flagValues.target)
+
+	} else {
+		fileName, err := targetFileName(flagValues.url)
+		if err != nil {
+			return err
+		}
+
+		if err := copyFile(file.Name(), filepath.Join(flagValues.target, fileName)); err != nil {
+			return err
+		}
+
+		log.Printf("Successfully downloaded %s into %s\n", flagValues.url, filepath.Join(flagValues.target, fileName))
+	}
+
+	if flagValues.resultFileDigest != "" {
+		if err := ioutil.WriteFile(flagValues.resultFileDigest, []byte("sha256:"+digest), 0644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// downloadWithRetries downloads the URL into the file, and retries on connection
+// failures and server errors with an exponential backoff
+func downloadWithRetries(ctx context.Context, client *http.Client, header http.Header, file *os.File) (string, error) {
+	delay := flagValues.retryDelay
+
+	for attempt := uint(0); ; attempt++ {
+		digest, err := download(ctx, client, header, file)
+		if err == nil {
+			return digest, nil
+		}
+
+		var exitError *ExitError
+		if !errors.As(err, &exitError) || !isRetryable(exitError) || attempt >= flagValues.retries {
+			return "", err
+		}
+
+		log.Printf("Download of %s failed, retrying in %s: %s\n", flagValues.url, delay, exitError.Message)
+
+		select {
+		case <-ctx.Done():
+			return "", err
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+	}
+}
+
+func download(ctx context.Context, client *http.Client, header http.Header, file *os.File) (string, error) {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	if err := file.Truncate(0); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, flagValues.url, nil)
+	if err != nil {
+		return "", &ExitError{Code: 100, Message: err.Error(), Reason: reasonError, Cause: err}
+	}
+
+	req.Header = header.Clone()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", &ExitError{Code: 110, Message: err.Error(), Reason: reasonConnectionFailed, Cause: err}
+	}
+	defer resp.Body.Close()
+
+	if err := checkStatus(resp); err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(file, hash), resp.Body); err != nil {
+		return "", &ExitError{Code: 110, Message: err.Error(), Reason: reasonConnectionFailed, Cause: err}
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func checkStatus(resp *http.Response) error {
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+
+	case resp.StatusCode == http.StatusNotFound:
+		return &ExitError{
+			Code:    111,
+			Message: fmt.Sprintf("%s does not exist (%s)", flagValues.url, resp.Status),
+			Reason:  reasonNotFound,
+		}
+
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return &ExitError{
+			Code:    112,
+			Message: fmt.Sprintf("access to %s was denied (%s), check the provided credentials", flagValues.url, resp.Status),
+			Reason:  reasonAuthenticationFailed,
+		}
+
+	case resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests:
+		return &ExitError{
+			Code:    113,
+			Message: fmt.Sprintf("the server failed to provide %s (%s)", flagValues.url, resp.Status),
+			Reason:  reasonServerError,
+		}
+
+	default:
+		return &ExitError{
+			Code:    114,
+			Message: fmt.Sprintf("unexpected response for %s (%s)", flagValues.url, resp.Status),
+			Reason:  reasonError,
+		}
+	}
+}
+
+func isRetryable(err *ExitError) bool {
+	return err.Reason == reasonConnectionFailed || err.Reason == reasonServerError
+}
+
+// requestHeader combines the headers of the --header flag and the mounted secret
+func requestHeader() (http.Header, error) {
+	header := http.Header{}
+
+	lines := append([]string{}, flagValues.header...)
+
+	if flagValues.secretPath != "" {
+		hasUsername := hasFile(flagValues.secretPath, "username")
+		hasPassword := hasFile(flagValues.secretPath, "password")
+
+		switch {
+		case hasUsername && hasPassword:
+			username, err := readSecretFile("username")
+			if err != nil {
+				return nil, err
+			}
+
+			password, err := readSecretFile("password")
+			if err != nil {
+				return nil, err
+			}
+
+			header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(username+":"+password)))
+
+		case hasUsername || hasPassword:
+			return nil, &ExitError{
+				Code:    110,
+				Message: "Basic Auth incomplete: Both username and password need to be configured.",
+				Reason:  reasonAuthenticationFailed,
+			}
+
+		case hasFile(flagValues.secretPath, "token"):
+			token, err := readSecretFile("token")
+			if err != nil {
+				return nil, err
+			}
+
+			header.Set("Authorization", "Bearer "+token)
+		}
+
+		if hasFile(flagValues.secretPath, "headers") {
+			headers, err := readSecretFile("headers")
+			if err != nil {
+				return nil, err
+			}
+
+			lines = append(lines, strings.Split(headers, "\n")...)
+		}
+	}
+
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, &ExitError{Code: 100, Message: "invalid header, expected the form 'Name: value'", Reason: reasonError}
+		}
+
+		header.Add(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+	}
+
+	return header, nil
+}
+
+func readSecretFile(name string) (string, error) {
+	data, err := ioutil.ReadFile(filepath.Join(flagValues.secretPath, name))
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
+
+// targetFileName returns the name of the file from the path of the URL, like wget does
+func targetFileName(rawURL string) (string, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	fileName := path.Base(parsed.Path)
+	if fileName == "." || fileName == "/" {
+		return "index.html", nil
+	}
+
+	return fileName, nil
+}
+
+func copyFile(source string, target string) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, in); err != nil {
+		return err
+	}
+
+	return out.Close()
+}
+
+func hasFile(elem ...string) bool {
+	_, err := os.Stat(filepath.Join(elem...))
+	return err == nil
+}
+
+func writeErrorResults(reason string, message string) (err error) {
+	if flagValues.resultFileErrorReason == "" || flagValues.resultFileErrorMessage == "" {
+		return nil
+	}
+
+	messageToWrite := message
+	messageLengthThreshold := 300
+
+	if len(messageToWrite) > messageLengthThreshold {
+		messageToWrite = messageToWrite[:messageLengthThreshold-3] + "..."
+	}
+
+	if err = os.WriteFile(flagValues.resultFileErrorMessage, []byte(strings.TrimSpace(messageToWrite)), 0666); err != nil {
+		return err
+	}
+
+	return os.WriteFile(flagValues.resultFileErrorReason, []byte(reason), 0666)
+}
@@ -0,0 +1,17 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestHTTPCmd(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "HTTP Command Suite")
+}
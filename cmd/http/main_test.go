@@ -0,0 +1,376 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/shipwright-io/build/cmd/http"
+)
+
+var _ = Describe("HTTP Download", func() {
+	const content = "Hello, Shipwright!"
+
+	var (
+		server        *httptest.Server
+		flakyRequests int
+	)
+
+	var run = func(args ...string) error {
+		// discard log output
+		log.SetOutput(ioutil.Discard)
+
+		os.Args = append([]string{"tool", "--retry-delay", "1ms"}, args...)
+		return Execute(context.TODO())
+	}
+
+	var withTempDir = func(f func(target string)) {
+		path, err := ioutil.TempDir(os.TempDir(), "http")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(path)
+
+		f(path)
+	}
+
+	var filecontent = func(path string) string {
+		data, err := ioutil.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		return string(data)
+	}
+
+	var tarball = func(gzipped bool, entries map[string]string) []byte {
+		var buf bytes.Buffer
+		var tarWriter *tar.Writer
+
+		var gzipWriter *gzip.Writer
+		if gzipped {
+			gzipWriter = gzip.NewWriter(&buf)
+			tarWriter = tar.NewWriter(gzipWriter)
+		} else {
+			tarWriter = tar.NewWriter(&buf)
+		}
+
+		for name, data := range entries {
+			Expect(tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), Typeflag: tar.TypeReg})).To(Succeed())
+			_, err := tarWriter.Write([]byte(data))
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		Expect(tarWriter.Close()).To(Succeed())
+		if gzipWriter != nil {
+			Expect(gzipWriter.Close()).To(Succeed())
+		}
+
+		return buf.Bytes()
+	}
+
+	// gitArchive returns a gzipped tarball like git archive creates it, which starts
+	// with a pax global header that holds the commit of the archive
+	var gitArchive = func(prefix string, entries map[string]string) []byte {
+		var buf bytes.Buffer
+		gzipWriter := gzip.NewWriter(&buf)
+		tarWriter := tar.NewWriter(gzipWriter)
+
+		Expect(tarWriter.WriteHeader(&tar.Header{
+			Name:       "pax_global_header",
+			Typeflag:   tar.TypeXGlobalHeader,
+			PAXRecords: map[string]string{"comment": "8b6ff8ce1b6b7a3d3c4e9e6c8d4f5b1f0a9e2d13"},
+			Format:     tar.FormatPAX,
+		})).To(Succeed())
+		Expect(tarWriter.WriteHeader(&tar.Header{Name: prefix, Mode: 0755, Typeflag: tar.TypeDir})).To(Succeed())
+
+		for name, data := range entries {
+			Expect(tarWriter.WriteHeader(&tar.Header{Name: prefix + name, Mode: 0644, Size: int64(len(data)), Typeflag: tar.TypeReg})).To(Succeed())
+			_, err := tarWriter.Write([]byte(data))
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		Expect(tarWriter.Close()).To(Succeed())
+		Expect(gzipWriter.Close()).To(Succeed())
+		return buf.Bytes()
+	}
+
+	var zipball = func(entries map[string]string) []byte {
+		var buf bytes.Buffer
+		zipWriter := zip.NewWriter(&buf)
+		for name, data := range entries {
+			writer, err := zipWriter.Create(name)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = writer.Write([]byte(data))
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		Expect(zipWriter.Close()).To(Succeed())
+		return buf.Bytes()
+	}
+
+	BeforeEach(func() {
+		flakyRequests = 0
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/files/hello.txt", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(content))
+		})
+		mux.HandleFunc("/files/private.txt", func(w http.ResponseWriter, r *http.Request) {
+			if username, password, ok := r.BasicAuth(); !ok || username != "shipwright" || password != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(content))
+		})
+		mux.HandleFunc("/files/header.txt", func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Private-Token") != "secret" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			_, _ = w.Write([]byte(content))
+		})
+		mux.HandleFunc("/files/flaky.txt", func(w http.ResponseWriter, r *http.Request) {
+			flakyRequests++
+			if flakyRequests < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write([]byte(content))
+		})
+		mux.HandleFunc("/files/archive.tar", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write(tarball(false, map[string]string{"dir/hello.txt": content}))
+		})
+		mux.HandleFunc("/files/archive.tar.gz", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write(tarball(true, map[string]string{"dir/hello.txt": content}))
+		})
+		mux.HandleFunc("/files/archive.zip", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write(zipball(map[string]string{"dir/hello.txt": content}))
+		})
+		mux.HandleFunc("/files/git-archive.tar.gz", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write(gitArchive("sample-go-main/", map[string]string{"dir/hello.txt": content}))
+		})
+		mux.HandleFunc("/files/evil.tar", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write(tarball(false, map[string]string{"../evil.txt": content}))
+		})
+
+		server = httptest.NewServer(mux)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Context("validations and error cases", func() {
+		It("should succeed in case the help is requested", func() {
+			Expect(run("--help")).To(Succeed())
+		})
+
+		It("should fail in case mandatory arguments are missing", func() {
+			Expect(run()).To(HaveOccurred())
+		})
+
+		It("should fail in case the archive format is not supported", func() {
+			withTempDir(func(target string) {
+				Expect(run(
+					"--url", server.URL+"/files/hello.txt",
+					"--target", target,
+					"--extract", "rar",
+				)).To(HaveOccurred())
+			})
+		})
+
+		It("should fail with a dedicated reason in case the file does not exist", func() {
+			withTempDir(func(target string) {
+				err := run(
+					"--url", server.URL+"/files/does-not-exist.txt",
+					"--target", target,
+				)
+				Expect(err).To(HaveOccurred())
+				Expect(err.(*ExitError).Reason).To(Equal("HTTPNotFound"))
+			})
+		})
+
+		It("should fail with a dedicated reason in case credentials are missing", func() {
+			withTempDir(func(target string) {
+				err := run(
+					"--url", server.URL+"/files/private.txt",
+					"--target", target,
+				)
+				Expect(err).To(HaveOccurred())
+				Expect(err.(*ExitError).Reason).To(Equal("HTTPAuthenticationFailed"))
+			})
+		})
+
+		It("should fail with a dedicated reason in case the digest does not match", func() {
+			withTempDir(func(target string) {
+				err := run(
+					"--url", server.URL+"/files/hello.txt",
+					"--target", target,
+					"--sha256", "0000000000000000000000000000000000000000000000000000000000000000",
+				)
+				Expect(err).To(HaveOccurred())
+				Expect(err.(*ExitError).Reason).To(Equal("HTTPDigestMismatch"))
+				Expect(filepath.Join(target, "hello.txt")).ToNot(BeAnExistingFile())
+			})
+		})
+
+		It("should fail in case an archive entry points outside of the target directory", func() {
+			withTempDir(func(target string) {
+				err := run(
+					"--url", server.URL+"/files/evil.tar",
+					"--target", filepath.Join(target, "sub"),
+					"--extract", "tar",
+				)
+				Expect(err).To(HaveOccurred())
+				Expect(err.(*ExitError).Reason).To(Equal("HTTPExtractionFailed"))
+				Expect(filepath.Join(target, "evil.txt")).ToNot(BeAnExistingFile())
+			})
+		})
+
+		It("should give up after the configured number of retries", func() {
+			withTempDir(func(target string) {
+				err := run(
+					"--url", server.URL+"/files/flaky.txt",
+					"--target", target,
+					"--retries", "1",
+				)
+				Expect(err).To(HaveOccurred())
+				Expect(err.(*ExitError).Reason).To(Equal("HTTPServerError"))
+				Expect(flakyRequests).To(Equal(2))
+			})
+		})
+	})
+
+	Context("downloading files", func() {
+		It("should download a file into the target directory", func() {
+			withTempDir(func(target string) {
+				Expect(run(
+					"--url", server.URL+"/files/hello.txt",
+					"--target", target,
+				)).To(Succeed())
+
+				Expect(filecontent(filepath.Join(target, "hello.txt"))).To(Equal(content))
+			})
+		})
+
+		It("should verify the digest and write it into the result file", func() {
+			hash := sha256.Sum256([]byte(content))
+			digest := hex.EncodeToString(hash[:])
+
+			withTempDir(func(target string) {
+				resultFile := filepath.Join(target, "digest")
+
+				Expect(run(
+					"--url", server.URL+"/files/hello.txt",
+					"--target", filepath.Join(target, "source"),
+					"--sha256", digest,
+					"--result-file-digest", resultFile,
+				)).To(Succeed())
+
+				Expect(filecontent(resultFile)).To(Equal("sha256:" + digest))
+			})
+		})
+
+		It("should retry in case of server errors", func() {
+			withTempDir(func(target string) {
+				Expect(run(
+					"--url", server.URL+"/files/flaky.txt",
+					"--target", target,
+				)).To(Succeed())
+
+				Expect(flakyRequests).To(Equal(3))
+				Expect(filecontent(filepath.Join(target, "flaky.txt"))).To(Equal(content))
+			})
+		})
+
+		It("should use the provided headers", func() {
+			withTempDir(func(target string) {
+				Expect(run(
+					"--url", server.URL+"/files/header.txt",
+					"--target", target,
+					"--header", "Private-Token: secret",
+				)).To(Succeed())
+
+				Expect(filecontent(filepath.Join(target, "header.txt"))).To(Equal(content))
+			})
+		})
+
+		It("should use the credentials of the secret", func() {
+			withTempDir(func(secret string) {
+				Expect(ioutil.WriteFile(filepath.Join(secret, "username"), []byte("shipwright"), 0644)).To(Succeed())
+				Expect(ioutil.WriteFile(filepath.Join(secret, "password"), []byte("secret\n"), 0644)).To(Succeed())
+
+				withTempDir(func(target string) {
+					Expect(run(
+						"--url", server.URL+"/files/private.txt",
+						"--target", target,
+						"--secret-path", secret,
+					)).To(Succeed())
+
+					Expect(filecontent(filepath.Join(target, "private.txt"))).To(Equal(content))
+				})
+			})
+		})
+
+		It("should use the headers of the secret", func() {
+			withTempDir(func(secret string) {
+				Expect(ioutil.WriteFile(filepath.Join(secret, "headers"), []byte("Private-Token: secret\n"), 0644)).To(Succeed())
+
+				withTempDir(func(target string) {
+					Expect(run(
+						"--url", server.URL+"/files/header.txt",
+						"--target", target,
+						"--secret-path", secret,
+					)).To(Succeed())
+
+					Expect(filecontent(filepath.Join(target, "header.txt"))).To(Equal(content))
+				})
+			})
+		})
+	})
+
+	Context("extracting archives", func() {
+		for _, format := range []string{"tar", "tar.gz", "zip"} {
+			format := format
+
+			It("should extract a "+format+" archive into the target directory", func() {
+				withTempDir(func(target string) {
+					Expect(run(
+						"--url", server.URL+"/files/archive."+format,
+						"--target", filepath.Join(target, "extracted"),
+						"--extract", format,
+					)).To(Succeed())
+
+					Expect(filecontent(filepath.Join(target, "extracted", "dir", "hello.txt"))).To(Equal(content))
+					Expect(filepath.Join(target, "extracted", "archive."+format)).ToNot(BeAnExistingFile())
+				})
+			})
+		}
+
+		It("should extract a tar.gz archive of git archive with a pax global header", func() {
+			withTempDir(func(target string) {
+				Expect(run(
+					"--url", server.URL+"/files/git-archive.tar.gz",
+					"--target", filepath.Join(target, "extracted"),
+					"--extract", "tar.gz",
+				)).To(Succeed())
+
+				Expect(filecontent(filepath.Join(target, "extracted", "sample-go-main", "dir", "hello.txt"))).To(Equal(content))
+				Expect(filepath.Join(target, "extracted", "pax_global_header")).ToNot(BeAnExistingFile())
+			})
+		})
+	})
+})
@@ -9,7 +9,6 @@ package main
 
 import (
 	"context"
-	"crypto/tls"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -17,6 +16,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/docker/cli/cli/config"
 	"github.com/google/go-containerregistry/pkg/crane"
@@ -24,6 +24,8 @@ import (
 	containerreg "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/mutate"
 	"github.com/spf13/pflag"
+
+	"github.com/shipwright-io/build/pkg/image"
 )
 
 // ExitError is an error which has an exit code to be used in os.Exit() to
@@ -58,11 +60,17 @@ func (ht *headerTransport) RoundTrip(in *http.Request) (*http.Response, error) {
 	return ht.inner.RoundTrip(in)
 }
 
+// annotationImageCreated is the OCI standard annotation for the creation time of the image
+const annotationImageCreated = "org.opencontainers.image.created"
+
 type settings struct {
-	help bool
+	help,
+	annotationCreated bool
 	annotation,
+	annotationFile,
 	label *[]string
 	image,
+	caBundle,
 	resultFileImageDigest,
 	resultFileImageSize string
 }
@@ -77,6 +85,43 @@ func getAnnotation() []string {
 	return annotation
 }
 
+// getAnnotationFromFile returns the annotations whose values are read from a
+// file, the annotation is skipped in case the file does not exist or is empty
+func getAnnotationFromFile() ([]string, error) {
+	var annotation []string
+
+	if flagValues.annotationFile == nil {
+		return annotation, nil
+	}
+
+	keyFiles, err := splitKeyVals(*flagValues.annotationFile)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, file := range keyFiles {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			if os.IsNotExist(err) {
+				log.Printf("Skipping annotation %s, file %s does not exist\n", key, file)
+				continue
+			}
+
+			return nil, err
+		}
+
+		value := strings.TrimSpace(string(data))
+		if value == "" {
+			log.Printf("Skipping annotation %s, file %s is empty\n", key, file)
+			continue
+		}
+
+		annotation = append(annotation, fmt.Sprintf("%s=%s", key, value))
+	}
+
+	return annotation, nil
+}
+
 func getLabel() []string {
 	var label []string
 
@@ -97,7 +142,10 @@ func initializeFlag() {
 	// the flag `image` will always be used.
 	pflag.StringVar(&flagValues.image, "image", "", "The name of image in container registry")
 	flagValues.annotation = pflag.StringArray("annotation", nil, "New annotations to add")
+	flagValues.annotationFile = pflag.StringArray("annotation-file", nil, "New annotations to add, in the form key=path with the value read from the file")
+	pflag.BoolVar(&flagValues.annotationCreated, "annotation-created", false, "Add the "+annotationImageCreated+" annotation with the current time")
 	flagValues.label = pflag.StringArray("label", nil, "New labels to add")
+	pflag.StringVar(&flagValues.caBundle, "ca-bundle", "", "A file with PEM encoded certificates to trust in addition to the system certificates")
 	pflag.StringVar(&flagValues.resultFileImageDigest, "result-file-image-digest", "", "A file to write the image digest to")
 	pflag.StringVar(&flagValues.resultFileImageSize, "result-file-image-size", "", "A file to write the image size to")
 }
@@ -137,7 +185,21 @@ func runMutateImage(ctx context.Context) error {
 		return &ExitError{Code: 100, Message: "the 'image' argument must not be empty"}
 	}
 
-	options := getOptions(ctx)
+	fileAnnotation, err := getAnnotationFromFile()
+	if err != nil {
+		return err
+	}
+	annotation = append(annotation, fileAnnotation...)
+
+	if flagValues.annotationCreated {
+		annotation = append(annotation, fmt.Sprintf("%s=%s", annotationImageCreated, time.Now().UTC().Format(time.RFC3339)))
+	}
+
+	options, err := getOptions(ctx)
+	if err != nil {
+		return err
+	}
+
 	ref := flagValues.image
 
 	if len(annotation) != 0 {
@@ -272,15 +334,14 @@ func splitKeyVals(kvPairs []string) (map[string]string, error) {
 	return m, nil
 }
 
-func getOptions(ctx context.Context) *[]crane.Option {
+func getOptions(ctx context.Context) (*[]crane.Option, error) {
 	var options []crane.Option
 
 	options = append(options, crane.WithContext(ctx))
 
-	transport := http.DefaultTransport.(*http.Transport).Clone()
-	transport.TLSClientConfig = &tls.Config{
-		InsecureSkipVerify: false,
-		MinVersion:         tls.VersionTLS12,
+	transport, err := image.NewTransport(flagValues.caBundle)
+	if err != nil {
+		return nil, err
 	}
 
 	var rt http.RoundTripper = transport
@@ -297,5 +358,5 @@ func getOptions(ctx context.Context) *[]crane.Option {
 
 	options = append(options, crane.WithTransport(rt))
 
-	return &options
+	return &options, nil
 }
@@ -11,6 +11,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
@@ -285,6 +286,56 @@ var _ = Describe("Image Mutate Resource", func() {
 		})
 	})
 
+	Context("mutating the image with source annotations", func() {
+		It("should mutate an image with an annotation read from a file", func() {
+			tag := pushImage("test10")
+
+			withTempFile("commit-sha", func(filename string) {
+				Expect(ioutil.WriteFile(filename, []byte("0e0583421a5e4bf562ffe33f3651e16ba0c78591\n"), 0644)).To(Succeed())
+
+				Expect(run(
+					"--image",
+					tag.String(),
+					"--annotation-file",
+					fmt.Sprintf("org.opencontainers.image.revision=%s", filename),
+				)).To(BeNil())
+
+				Expect(getImageAnnotation(tag.String(), "org.opencontainers.image.revision")).
+					To(Equal("0e0583421a5e4bf562ffe33f3651e16ba0c78591"))
+			})
+		})
+
+		It("should skip an annotation when the file does not exist", func() {
+			tag := pushImage("test11")
+
+			Expect(run(
+				"--image",
+				tag.String(),
+				"--annotation",
+				"org.opencontainers.image.url=https://my-company.com/images",
+				"--annotation-file",
+				"org.opencontainers.image.revision=/tmp/does-not-exist",
+			)).To(BeNil())
+
+			Expect(getImageAnnotation(tag.String(), "org.opencontainers.image.revision")).
+				To(BeEmpty())
+		})
+
+		It("should mutate an image with the created annotation", func() {
+			tag := pushImage("test12")
+
+			Expect(run(
+				"--image",
+				tag.String(),
+				"--annotation-created",
+			)).To(BeNil())
+
+			created, err := time.Parse(time.RFC3339, getImageAnnotation(tag.String(), "org.opencontainers.image.created"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(created).To(BeTemporally("~", time.Now(), time.Minute))
+		})
+	})
+
 	Context("store result after image mutation", func() {
 		It("should store image digest into file specified in --result-file-image-digest flags", func() {
 			tag := pushImage("test8")
@@ -12,6 +12,9 @@ import (
 	"os"
 	"runtime"
 
+	// Embed the time zone database for the time zones of Build schedules
+	_ "time/tzdata"
+
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 
 	"github.com/spf13/pflag"
@@ -0,0 +1,71 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// directoryChecksum returns the number of regular files in a directory, and
+// the checksum of their content. The checksum is the SHA-256 of the output that
+// "sha256sum" prints for all regular files sorted by their path, which allows
+// clients to calculate it with standard tools.
+func directoryChecksum(directory string) (int, string, error) {
+	var paths []string
+	err := filepath.WalkDir(directory, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.Type().IsRegular() {
+			rel, err := filepath.Rel(directory, path)
+			if err != nil {
+				return err
+			}
+
+			paths = append(paths, "./"+filepath.ToSlash(rel))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, "", err
+	}
+
+	sort.Strings(paths)
+
+	checksum := sha256.New()
+	for _, path := range paths {
+		fileChecksum, err := fileChecksum(filepath.Join(directory, filepath.FromSlash(path)))
+		if err != nil {
+			return 0, "", err
+		}
+
+		fmt.Fprintf(checksum, "%s  %s\n", fileChecksum, path)
+	}
+
+	return len(paths), "sha256:" + hex.EncodeToString(checksum.Sum(nil)), nil
+}
+
+func fileChecksum(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	checksum := sha256.New()
+	if _, err := io.Copy(checksum, file); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(checksum.Sum(nil)), nil
+}
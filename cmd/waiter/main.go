@@ -9,12 +9,24 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+
+	"github.com/shipwright-io/build/pkg/upload"
 )
 
 // settings composed by command-line flag values.
 type settings struct {
-	lockFile string        // path to lock file
-	timeout  time.Duration // how long wait for 'done'
+	lockFile               string        // path to lock file
+	readyFile              string        // path to the ready marker
+	timeout                time.Duration // how long wait for 'done'
+	directory              string        // directory with the source code
+	expectedFileCount      int           // number of files 'done' expects in the directory
+	expectedChecksum       string        // checksum 'done' expects for the directory
+	uploadTokenFile        string        // path to the file with the upload token
+	uploadPort             int           // port the upload is received on
+	resultFileFileCount    string        // path to the file count result file
+	resultFileChecksum     string        // path to the checksum result file
+	resultFileErrorMessage string        // path to the error message result file
+	resultFileErrorReason  string        // path to the error reason result file
 }
 
 const longDesc = `
@@ -38,6 +50,24 @@ Or, alternatively:
 
 	$ rm -f <lock-file>
 
+Before signalling "done", the content of the directory can be verified, the
+waiter keeps waiting when the verification fails:
+
+	$ waiter done --expected-file-count 42 --expected-checksum sha256:<hex>
+
+The checksum is the SHA-256 of the "sha256sum" output of all regular files,
+sorted by path:
+
+	$ cd <directory> && find . -type f -print0 | LC_ALL=C sort -z | xargs -0 sha256sum | sha256sum
+
+Once the waiter is ready, it writes the ready marker, which can be checked with:
+
+	$ waiter ready
+
+When --upload-token-file is set, the waiter also receives the source code as a
+tar stream on the upload port, unpacks it into --directory, and stops when the
+upload succeeded. Only requests with the token as bearer token are accepted.
+
 ## Return-Code
 
 In the case of timeout, the waiter will return error, it only exits gracefully via
@@ -48,6 +78,7 @@ var (
 	rootCmd  = newRootCmd()
 	startCmd = newStartCmd()
 	doneCmd  = newDoneCmd()
+	readyCmd = newReadyCmd()
 )
 
 // defaultTimeout default timeout duration.
@@ -56,6 +87,9 @@ var defaultTimeout = 60 * time.Second
 // defaultLockFile default location of the lock-file.
 var defaultLockFile = "/tmp/waiter.lock"
 
+// defaultReadyFile default location of the ready marker.
+var defaultReadyFile = "/tmp/waiter.ready"
+
 // flagValues receives the command-line flag values.
 var flagValues = settings{}
 
@@ -64,10 +98,22 @@ func init() {
 	flags := rootCmd.PersistentFlags()
 
 	flags.StringVar(&flagValues.lockFile, "lock-file", defaultLockFile, "lock file full path")
+	flags.StringVar(&flagValues.readyFile, "ready-file", defaultReadyFile, "ready marker full path")
 	flags.DurationVar(&flagValues.timeout, "timeout", defaultTimeout, "how long to wait until 'done'")
+	flags.StringVar(&flagValues.directory, "directory", "", "directory with the source code, defaults to the one of the waiting process for 'done'")
+	flags.StringVar(&flagValues.resultFileFileCount, "result-file-file-count", "", "A file to write the number of files in the directory to.")
+	flags.StringVar(&flagValues.resultFileChecksum, "result-file-checksum", "", "A file to write the checksum of the directory to.")
+	flags.StringVar(&flagValues.resultFileErrorMessage, "result-file-error-message", "", "A file to write the error message to.")
+	flags.StringVar(&flagValues.resultFileErrorReason, "result-file-error-reason", "", "A file to write the error reason to.")
+	flags.StringVar(&flagValues.uploadTokenFile, "upload-token-file", "", "file with the token that uploads must authenticate with, enables the upload")
+	flags.IntVar(&flagValues.uploadPort, "upload-port", upload.ReceiverPort, "port the upload is received on")
+
+	doneCmd.Flags().IntVar(&flagValues.expectedFileCount, "expected-file-count", -1, "number of regular files expected in the directory")
+	doneCmd.Flags().StringVar(&flagValues.expectedChecksum, "expected-checksum", "", "checksum expected for the directory, in the format sha256:<hex>")
 
 	rootCmd.AddCommand(startCmd)
 	rootCmd.AddCommand(doneCmd)
+	rootCmd.AddCommand(readyCmd)
 }
 
 func newRootCmd() *cobra.Command {
@@ -103,6 +149,18 @@ func newDoneCmd() *cobra.Command {
 	}
 }
 
+func newReadyCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:          "ready",
+		Short:        "Checks whether the waiting started, to be used as readiness probe.",
+		SilenceUsage: true,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			w := NewWaiter(flagValues)
+			return w.Ready()
+		},
+	}
+}
+
 // main waiter's entrypoint.
 func main() {
 	if err := rootCmd.Execute(); err != nil {
@@ -5,8 +5,13 @@ package main
 
 import (
 	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/onsi/gomega/gbytes"
@@ -15,6 +20,8 @@ import (
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+
+	"github.com/shipwright-io/build/pkg/bundle"
 )
 
 var _ = Describe("Waiter", func() {
@@ -131,4 +138,165 @@ var _ = Describe("Waiter", func() {
 			Eventually(startCh, defaultTimeout).Should(BeClosed())
 		})
 	})
+
+	Describe("expect to succeed when the upload is received before timeout", func() {
+		var (
+			startCh   = make(chan interface{})
+			directory string
+			target    string
+		)
+
+		BeforeEach(func() {
+			var err error
+			directory, err = ioutil.TempDir("", "waiter")
+			Expect(err).ToNot(HaveOccurred())
+
+			tokenFile := filepath.Join(directory, "token")
+			Expect(ioutil.WriteFile(tokenFile, []byte("upload-token\n"), 0600)).To(Succeed())
+
+			target = filepath.Join(directory, "target")
+			Expect(os.Mkdir(target, 0755)).To(Succeed())
+
+			session := run("start", "--upload-token-file", tokenFile, "--directory", target, "--upload-port", "18090",
+				"--result-file-file-count", filepath.Join(directory, "file-count"),
+				"--result-file-checksum", filepath.Join(directory, "checksum"),
+			)
+
+			go inspectSession(session, startCh, gexec.Exit(0))
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(directory)).To(Succeed())
+		})
+
+		It("stops when the upload was unpacked", func() {
+			source, err := bundle.Pack("../../test/bundle")
+			Expect(err).ToNot(HaveOccurred())
+			defer source.Close()
+
+			req, err := http.NewRequest(http.MethodPut, "http://127.0.0.1:18090/upload", source)
+			Expect(err).ToNot(HaveOccurred())
+			req.Header.Set("Authorization", "Bearer upload-token")
+
+			resp, err := http.DefaultClient.Do(req)
+			Expect(err).ToNot(HaveOccurred())
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			Eventually(startCh, defaultTimeout).Should(BeClosed())
+			Expect(filepath.Join(target, "README.md")).To(BeAnExistingFile())
+
+			fileCount, err := ioutil.ReadFile(filepath.Join(directory, "file-count"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(fileCount)).To(Equal("4"))
+
+			checksum, err := ioutil.ReadFile(filepath.Join(directory, "checksum"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(checksum)).To(Equal(expectedChecksum(target)))
+		})
+	})
+
+	Describe("expect to report the readiness", func() {
+		var startCh = make(chan interface{})
+
+		It("is not ready before the waiting started", func() {
+			Eventually(run("ready"), defaultTimeout).Should(gexec.Exit(1))
+		})
+
+		It("is ready while waiting", func() {
+			go inspectSession(run("start"), startCh, gexec.Exit(0))
+
+			Eventually(run("ready"), defaultTimeout).Should(gexec.Exit(0))
+			Eventually(run("done"), defaultTimeout).Should(gexec.Exit(0))
+			Eventually(startCh, defaultTimeout).Should(BeClosed())
+		})
+	})
+
+	Describe("expect to verify the directory when `done` is issued", func() {
+		var (
+			startCh   = make(chan interface{})
+			directory string
+		)
+
+		BeforeEach(func() {
+			var err error
+			directory, err = ioutil.TempDir("", "waiter")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ioutil.WriteFile(filepath.Join(directory, "a.txt"), []byte("a\n"), 0644)).To(Succeed())
+			Expect(os.Mkdir(filepath.Join(directory, "sub"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(directory, "sub", "b.txt"), []byte("b\n"), 0644)).To(Succeed())
+
+			go inspectSession(run("start", "--directory", directory), startCh, gexec.Exit(0))
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(directory)).To(Succeed())
+		})
+
+		It("keeps waiting when the verification fails, and stops when it succeeds", func() {
+			session := run("done", "--expected-file-count", "3")
+			Eventually(session, defaultTimeout).Should(gexec.Exit(1))
+			Expect(session.Err).To(gbytes.Say("expected 3 files"))
+			Expect(defaultLockFile).To(BeAnExistingFile())
+
+			checksum := "sha256:0000000000000000000000000000000000000000000000000000000000000000"
+			session = run("done", "--expected-file-count", "2", "--expected-checksum", checksum)
+			Eventually(session, defaultTimeout).Should(gexec.Exit(1))
+			Expect(session.Err).To(gbytes.Say("expected checksum"))
+
+			checksum = expectedChecksum(directory)
+			Eventually(run("done", "--expected-file-count", "2", "--expected-checksum", checksum), defaultTimeout).Should(gexec.Exit(0))
+			Eventually(startCh, defaultTimeout).Should(BeClosed())
+		})
+	})
+
+	Describe("expect to write the error results when timeout is reached", func() {
+		var (
+			startCh   = make(chan interface{})
+			directory string
+		)
+
+		BeforeEach(func() {
+			var err error
+			directory, err = ioutil.TempDir("", "waiter")
+			Expect(err).ToNot(HaveOccurred())
+
+			session := run("start", "--timeout", "2s",
+				"--result-file-error-message", filepath.Join(directory, "error-message"),
+				"--result-file-error-reason", filepath.Join(directory, "error-reason"),
+			)
+
+			go inspectSession(session, startCh, gexec.Exit(1))
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(directory)).To(Succeed())
+		})
+
+		It("writes the UploadTimeout reason", func() {
+			Eventually(startCh, defaultTimeout).Should(BeClosed())
+
+			reason, err := ioutil.ReadFile(filepath.Join(directory, "error-reason"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(reason)).To(Equal("UploadTimeout"))
+
+			message, err := ioutil.ReadFile(filepath.Join(directory, "error-message"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(message)).To(ContainSubstring("not provided within"))
+		})
+	})
 })
+
+// expectedChecksum calculates the checksum of a directory like a client would,
+// with the output of sha256sum for all files
+func expectedChecksum(directory string) string {
+	cmd := exec.Command("sh", "-c", "find . -type f -print0 | LC_ALL=C sort -z | xargs -0 sha256sum | sha256sum")
+	cmd.Dir = directory
+
+	output, err := cmd.Output()
+	Expect(err).ToNot(HaveOccurred())
+
+	return "sha256:" + strings.Fields(string(output))[0]
+}
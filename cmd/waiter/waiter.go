@@ -4,12 +4,18 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
+	"net"
+	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"time"
+
+	"github.com/shipwright-io/build/pkg/upload"
 )
 
 // Waiter represents the actor that will wait for timeout, using a lock-file to keep it actively
@@ -21,6 +27,18 @@ type Waiter struct {
 // ErrTimeout emitted when timeout is reached.
 var ErrTimeout = errors.New("timeout waiting for condition")
 
+// ErrNotReady emitted when the waiting did not start yet.
+var ErrNotReady = errors.New("waiter is not ready")
+
+// ErrVerification emitted when the content of the directory does not match the expectation.
+var ErrVerification = errors.New("verification of the directory failed")
+
+// reasonUploadTimeout is the error reason when the source code was not provided in time.
+const reasonUploadTimeout = "UploadTimeout"
+
+// progressInterval is the number of bytes after which the upload progress is logged.
+const progressInterval = 64 * 1024 * 1024
+
 // save writes the lock-file with informed PID.
 func (w *Waiter) save(pid int) error {
 	return os.WriteFile(w.flagValues.lockFile, []byte(strconv.Itoa(pid)), 0600)
@@ -62,6 +80,51 @@ func (w *Waiter) retry() error {
 
 }
 
+// receive starts the server that receives the upload, it returns the function
+// to stop the server.
+func (w *Waiter) receive() (func(), error) {
+	token, err := os.ReadFile(w.flagValues.uploadTokenFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if w.flagValues.directory == "" {
+		return nil, errors.New("the directory to unpack the upload into is not set")
+	}
+
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", w.flagValues.uploadPort))
+	if err != nil {
+		return nil, err
+	}
+
+	receiver := upload.NewReceiver(w.flagValues.directory, strings.TrimSpace(string(token)), func() {
+		log.Printf("Upload received in '%s'\n", w.flagValues.directory)
+		_ = os.Remove(w.flagValues.lockFile)
+	})
+
+	var reported int64
+	receiver.Progress = func(processed int64) {
+		if processed-reported >= progressInterval {
+			reported = processed
+			log.Printf("Received %d MiB of the upload\n", processed/1024/1024)
+		}
+	}
+
+	server := &http.Server{Handler: receiver}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("[ERROR] Receiving the upload failed: %v\n", err)
+		}
+	}()
+
+	log.Printf("Receiving the upload on port %d\n", w.flagValues.uploadPort)
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(ctx)
+	}, nil
+}
+
 // Wait wait for the lock-file to be removed, or timeout.
 func (w *Waiter) Wait() error {
 	pid := os.Getpid()
@@ -69,24 +132,148 @@ func (w *Waiter) Wait() error {
 		return err
 	}
 
+	// the source code can be stored in a sub-directory of the source root
+	if w.flagValues.directory != "" {
+		if err := os.MkdirAll(w.flagValues.directory, 0755); err != nil {
+			_ = os.RemoveAll(w.flagValues.lockFile)
+			return err
+		}
+	}
+
+	// receiving the upload, which removes the lock-file when it succeeded
+	if w.flagValues.uploadTokenFile != "" {
+		stop, err := w.receive()
+		if err != nil {
+			_ = os.RemoveAll(w.flagValues.lockFile)
+			return err
+		}
+		defer stop()
+	}
+
+	// the ready marker contains the directory, so that "done" can verify it
+	if err := os.WriteFile(w.flagValues.readyFile, []byte(w.flagValues.directory), 0600); err != nil {
+		_ = os.RemoveAll(w.flagValues.lockFile)
+		return err
+	}
+	defer os.RemoveAll(w.flagValues.readyFile)
+
 	// waiting for the lock-file removal...
 	err := w.retry()
 	if err != nil {
 		_ = os.RemoveAll(w.flagValues.lockFile)
+
+		if errors.Is(err, ErrTimeout) {
+			message := fmt.Sprintf("the source code was not provided within %s", w.flagValues.timeout)
+			if err := w.writeErrorResults(reasonUploadTimeout, message); err != nil {
+				log.Printf("[ERROR] Failed to write the error results: %v\n", err)
+			}
+		}
+		return err
 	}
-	return err
+
+	return w.writeResults()
 }
 
-// Done removes the lock-file.
+// writeResults writes the number of files and the checksum of the directory
+// into the result files, if configured.
+func (w *Waiter) writeResults() error {
+	if w.flagValues.directory == "" || (w.flagValues.resultFileFileCount == "" && w.flagValues.resultFileChecksum == "") {
+		return nil
+	}
+
+	fileCount, checksum, err := directoryChecksum(w.flagValues.directory)
+	if err != nil {
+		return err
+	}
+
+	if w.flagValues.resultFileFileCount != "" {
+		if err := os.WriteFile(w.flagValues.resultFileFileCount, []byte(strconv.Itoa(fileCount)), 0666); err != nil {
+			return err
+		}
+	}
+
+	if w.flagValues.resultFileChecksum != "" {
+		if err := os.WriteFile(w.flagValues.resultFileChecksum, []byte(checksum), 0666); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Ready checks whether the ready marker exists.
+func (w *Waiter) Ready() error {
+	if _, err := os.Stat(w.flagValues.readyFile); err != nil {
+		return fmt.Errorf("%w: %v", ErrNotReady, err)
+	}
+	return nil
+}
+
+// Done verifies the content of the directory, if expected, and removes the
+// lock-file.
 func (w *Waiter) Done() error {
 	pid, err := w.read()
 	if err != nil {
 		return err
 	}
+
+	if w.flagValues.expectedFileCount >= 0 || w.flagValues.expectedChecksum != "" {
+		if err := w.verify(); err != nil {
+			return err
+		}
+	}
+
 	log.Printf("Removing lock-file at '%s' (%d PID)", w.flagValues.lockFile, pid)
 	return os.Remove(w.flagValues.lockFile)
 }
 
+// verify compares the file count and the checksum of the directory with the
+// expected values.
+func (w *Waiter) verify() error {
+	directory := w.flagValues.directory
+	if directory == "" {
+		data, err := os.ReadFile(w.flagValues.readyFile)
+		if err != nil {
+			return fmt.Errorf("failed to determine the directory to verify: %w", err)
+		}
+		directory = string(data)
+	}
+
+	if directory == "" {
+		return errors.New("the directory to verify is not set")
+	}
+
+	fileCount, checksum, err := directoryChecksum(directory)
+	if err != nil {
+		return err
+	}
+
+	if w.flagValues.expectedFileCount >= 0 && fileCount != w.flagValues.expectedFileCount {
+		return fmt.Errorf("%w: expected %d files in '%s', found %d", ErrVerification, w.flagValues.expectedFileCount, directory, fileCount)
+	}
+
+	if w.flagValues.expectedChecksum != "" && !strings.EqualFold(checksum, w.flagValues.expectedChecksum) {
+		return fmt.Errorf("%w: expected checksum %s for '%s', found %s", ErrVerification, w.flagValues.expectedChecksum, directory, checksum)
+	}
+
+	log.Printf("Verified %d files in '%s' with checksum %s\n", fileCount, directory, checksum)
+	return nil
+}
+
+// writeErrorResults writes the reason and the message of a failure into the
+// result files, if configured.
+func (w *Waiter) writeErrorResults(reason string, message string) error {
+	if w.flagValues.resultFileErrorReason == "" || w.flagValues.resultFileErrorMessage == "" {
+		return nil
+	}
+
+	if err := os.WriteFile(w.flagValues.resultFileErrorMessage, []byte(message), 0666); err != nil {
+		return err
+	}
+
+	return os.WriteFile(w.flagValues.resultFileErrorReason, []byte(reason), 0666)
+}
+
 // NewWaiter instantiate a new waiter, making sure the timeout informed is acceptable.
 func NewWaiter(flagValues settings) *Waiter {
 	if flagValues.timeout <= time.Second {
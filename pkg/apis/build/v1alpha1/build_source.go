@@ -5,6 +5,7 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -19,8 +20,26 @@ const LocalCopy BuildSourceType = "LocalCopy"
 // the build process starts. Represents a remote dependency.
 const HTTP BuildSourceType = "HTTP"
 
+// Git defines an additional Git repository, which will be cloned into a sub-directory of the source
+// directory, next to the repository of spec.source.
+const Git BuildSourceType = "Git"
+
+// ArchiveFormat enumerates the formats of archives that a HTTP source can be extracted from.
+type ArchiveFormat string
+
+const (
+	// ArchiveFormatTar is an uncompressed tar archive
+	ArchiveFormatTar ArchiveFormat = "tar"
+
+	// ArchiveFormatTarGz is a gzip compressed tar archive
+	ArchiveFormatTarGz ArchiveFormat = "tar.gz"
+
+	// ArchiveFormatZip is a zip archive
+	ArchiveFormatZip ArchiveFormat = "zip"
+)
+
 // BuildSource remote artifact definition, also known as "sources". Simple "name" and "url" pairs,
-// initially without "credentials" (authentication) support yet.
+// with optional "credentials" for the download, or additional Git repositories.
 type BuildSource struct {
 	// Name instance entry.
 	Name string `json:"name"`
@@ -39,4 +58,42 @@ type BuildSource struct {
 	//
 	// +optional
 	URL string `json:"url,omitempty"`
+
+	// Revision describes the Git revision (e.g., branch, tag, commit SHA,
+	// etc.) to fetch for a Git source.
+	//
+	// If not defined, it will fallback to the repository's default branch.
+	//
+	// +optional
+	Revision *string `json:"revision,omitempty"`
+
+	// Credentials references a Secret that contains credentials to download
+	// the remote artifact. The Secret contains either the keys username and
+	// password, or the key token for bearer authentication. Additional
+	// request headers can be provided as "Name: value" lines in the key headers.
+	// For a Git source, the Secret contains the credentials to access the
+	// repository, like the credentials of spec.source.
+	//
+	// +optional
+	Credentials *corev1.LocalObjectReference `json:"credentials,omitempty"`
+
+	// Digest is the expected digest of the remote artifact in the format
+	// sha256:<hex>. The download fails if the artifact has a different digest.
+	//
+	// +optional
+	Digest string `json:"digest,omitempty"`
+
+	// Extract defines the format of the archive that the remote artifact is
+	// extracted from. Without it, the remote artifact is stored as it is.
+	//
+	// +kubebuilder:validation:Enum=tar;tar.gz;zip
+	// +optional
+	Extract *ArchiveFormat `json:"extract,omitempty"`
+
+	// TargetPath is the directory, relative to the source root, into which
+	// the remote artifact is downloaded or extracted, the Git repository is
+	// cloned, or the LocalCopy upload is stored. It is required for a Git source.
+	//
+	// +optional
+	TargetPath string `json:"targetPath,omitempty"`
 }
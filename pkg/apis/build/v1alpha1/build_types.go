@@ -54,6 +54,11 @@ const (
 	RemoteRepositoryUnreachable BuildReason = "RemoteRepositoryUnreachable"
 	// BuildNameInvalid indicates the build name is invalid
 	BuildNameInvalid BuildReason = "BuildNameInvalid"
+	// ScheduleInvalid indicates that the cron syntax or the time zone of the schedule is invalid
+	ScheduleInvalid BuildReason = "ScheduleInvalid"
+	// EmbeddedBuildSpecUnsupported indicates that the spec of a Build that is embedded in a BuildRun
+	// uses settings that only apply to a Build that exists in the cluster
+	EmbeddedBuildSpecUnsupported BuildReason = "EmbeddedBuildSpecUnsupported"
 	// AllValidationsSucceeded indicates a Build was successfully validated
 	AllValidationsSucceeded = "all validations succeeded"
 )
@@ -89,6 +94,14 @@ const (
 	// or has a value of 'true', the controller triggers the validation. A value of 'false' means the controller
 	// will bypass checking the remote repository.
 	AnnotationBuildVerifyRepository = BuildDomain + "/verify.repository"
+
+	// LabelBuildSchedule is a label key for BuildRuns that were created by the schedule of a Build, the value
+	// is the name of the Build
+	LabelBuildSchedule = BuildDomain + "/schedule"
+
+	// AnnotationBuildScheduledTime is an annotation key for BuildRuns that were created by the schedule of a
+	// Build, the value is the scheduled time in RFC 3339 format
+	AnnotationBuildScheduledTime = BuildDomain + "/scheduled-time"
 )
 
 // BuildSpec defines the desired state of Build
@@ -143,6 +156,48 @@ type BuildSpec struct {
 	//
 	// +optional
 	Retention *BuildRetention `json:"retention,omitempty"`
+
+	// Proxy defines the HTTP proxy that the steps, which retrieve the source code
+	// or mutate the output image, use. It replaces the cluster-wide proxy settings.
+	//
+	// +optional
+	Proxy *Proxy `json:"proxy,omitempty"`
+
+	// CABundle references a ConfigMap with PEM encoded certificates that the steps,
+	// which retrieve the source code or mutate the output image, trust in addition
+	// to the system certificates. It replaces the cluster-wide CA bundle.
+	//
+	// +optional
+	CABundle *CABundle `json:"caBundle,omitempty"`
+
+	// Retry defines whether and how often a failed BuildRun of this Build is
+	// retried with a new TaskRun.
+	//
+	// +optional
+	Retry *RetryPolicy `json:"retry,omitempty"`
+
+	// ConcurrencyPolicy defines whether BuildRuns of this Build run at the same
+	// time. Allow runs them concurrently, Forbid queues a BuildRun until the
+	// earlier BuildRuns completed, and Replace cancels the earlier BuildRuns and
+	// queues the BuildRun until they completed. Defaults to Allow.
+	//
+	// +optional
+	// +kubebuilder:validation:Enum=Allow;Forbid;Replace
+	ConcurrencyPolicy ConcurrencyPolicy `json:"concurrencyPolicy,omitempty"`
+
+	// Schedule defines when BuildRuns of this Build are created periodically.
+	//
+	// +optional
+	Schedule *BuildSchedule `json:"schedule,omitempty"`
+
+	// SkipIfUnchanged defines whether a BuildRun of this Build succeeds without
+	// a TaskRun if its inputs equal the ones of the last succeeded BuildRun. The
+	// inputs are the revisions of the sources, the generation of the strategy,
+	// the parameter values, the environment variables, and the digest of the
+	// builder image. Defaults to false.
+	//
+	// +optional
+	SkipIfUnchanged *bool `json:"skipIfUnchanged,omitempty"`
 }
 
 // StrategyName returns the name of the configured strategy, or 'undefined' in
@@ -175,6 +230,45 @@ type Image struct {
 	//
 	// +optional
 	Labels map[string]string `json:"labels,omitempty"`
+
+	// SourceAnnotations enables the automatic population of the OCI standard
+	// annotations org.opencontainers.image.source, org.opencontainers.image.revision
+	// and org.opencontainers.image.created, based on the source that was built.
+	// Annotations that are explicitly defined take precedence.
+	//
+	// +optional
+	SourceAnnotations *bool `json:"sourceAnnotations,omitempty"`
+}
+
+// Proxy defines the HTTP proxy settings
+type Proxy struct {
+	// HTTPProxy is the proxy for HTTP requests
+	//
+	// +optional
+	HTTPProxy string `json:"httpProxy,omitempty"`
+
+	// HTTPSProxy is the proxy for HTTPS requests
+	//
+	// +optional
+	HTTPSProxy string `json:"httpsProxy,omitempty"`
+
+	// NoProxy is a comma-separated list of hosts and domains for which
+	// no proxy is used
+	//
+	// +optional
+	NoProxy string `json:"noProxy,omitempty"`
+}
+
+// CABundle references a key in a ConfigMap that contains PEM encoded certificates
+type CABundle struct {
+	// ConfigMap is the name of the ConfigMap in the namespace of the BuildRun
+	ConfigMap string `json:"configMap"`
+
+	// Key is the key in the ConfigMap that holds the certificates, it
+	// defaults to ca-bundle.crt
+	//
+	// +optional
+	Key string `json:"key,omitempty"`
 }
 
 // BuildStatus defines the observed state of Build
@@ -190,6 +284,11 @@ type BuildStatus struct {
 	// The message of the registered Build, either an error or succeed message
 	// +optional
 	Message *string `json:"message,omitempty"`
+
+	// LastScheduleTime is the scheduled time of the last BuildRun that was
+	// created by the schedule of the Build
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
 }
 
 // +genclient
@@ -228,6 +327,79 @@ type BuildRetention struct {
 	TtlAfterSucceeded *metav1.Duration `json:"ttlAfterSucceeded,omitempty"`
 }
 
+// ConcurrencyPolicy defines how BuildRuns of the same Build run at the same time
+type ConcurrencyPolicy string
+
+const (
+	// ConcurrencyPolicyAllow runs BuildRuns of the same Build concurrently
+	ConcurrencyPolicyAllow ConcurrencyPolicy = "Allow"
+
+	// ConcurrencyPolicyForbid queues a BuildRun until the earlier BuildRuns of the same Build completed
+	ConcurrencyPolicyForbid ConcurrencyPolicy = "Forbid"
+
+	// ConcurrencyPolicyReplace cancels the earlier BuildRuns of the same Build, and queues a BuildRun
+	// until they completed
+	ConcurrencyPolicyReplace ConcurrencyPolicy = "Replace"
+)
+
+// BuildSchedule defines when BuildRuns of a Build are created periodically
+type BuildSchedule struct {
+	// Cron is the schedule in cron syntax with the fields minute, hour, day of
+	// month, month and day of week, for example "0 2 * * *" for every night at
+	// 2am. The macros @yearly, @monthly, @weekly, @daily and @hourly are
+	// supported as well.
+	Cron string `json:"cron"`
+
+	// TimeZone is the name of the time zone in which the schedule is
+	// interpreted, for example Europe/Berlin. Defaults to UTC.
+	//
+	// +optional
+	TimeZone *string `json:"timeZone,omitempty"`
+
+	// StartingDeadline is how long after its scheduled time a BuildRun is
+	// still created, for example after the controller was not running. Later,
+	// the scheduled time is skipped. By default, the most recent missed
+	// BuildRun is always created.
+	//
+	// +optional
+	// +kubebuilder:validation:Format=duration
+	StartingDeadline *metav1.Duration `json:"startingDeadline,omitempty"`
+
+	// SucceededHistoryLimit is the number of succeeded BuildRuns created by
+	// the schedule that are kept. By default, all are kept.
+	//
+	// +optional
+	SucceededHistoryLimit *uint `json:"succeededHistoryLimit,omitempty"`
+
+	// FailedHistoryLimit is the number of failed BuildRuns created by the
+	// schedule that are kept. By default, all are kept.
+	//
+	// +optional
+	FailedHistoryLimit *uint `json:"failedHistoryLimit,omitempty"`
+}
+
+// RetryPolicy defines how a BuildRun is retried after a transient failure.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first one.
+	//
+	// +kubebuilder:validation:Minimum=1
+	MaxAttempts int `json:"maxAttempts"`
+
+	// Backoff is the duration to wait before the first retry. It is doubled
+	// for every further retry. Defaults to 10s.
+	//
+	// +optional
+	// +kubebuilder:validation:Format=duration
+	Backoff *metav1.Duration `json:"backoff,omitempty"`
+
+	// Reasons are the failure reasons that are retried, for example PodEvicted,
+	// BuildRunTimeout, or the reasons of the Git source step like GitRateLimited.
+	// Defaults to PodEvicted, GitRateLimited, GitTimeout and GitHostNotResolved.
+	//
+	// +optional
+	Reasons []string `json:"reasons,omitempty"`
+}
+
 func init() {
 	SchemeBuilder.Register(&Build{}, &BuildList{})
 }
@@ -18,12 +18,31 @@ const (
 
 	// LabelBuildRunGeneration is a label key for BuildRuns to define the generation
 	LabelBuildRunGeneration = BuildRunDomain + "/generation"
+
+	// AnnotationBuildRunRerun is an annotation key for BuildRuns, if it is set to
+	// "true" on a completed BuildRun, a new BuildRun is created that runs it again
+	AnnotationBuildRunRerun = BuildRunDomain + "/rerun"
+
+	// AnnotationBuildRunReruns is an annotation key for BuildRuns that the controller
+	// sets to the number of BuildRuns that were created to rerun the BuildRun
+	AnnotationBuildRunReruns = BuildRunDomain + "/reruns"
+
+	// LabelBuildRunRerunOf is a label key for BuildRuns to define the name of the
+	// BuildRun that they rerun
+	LabelBuildRunRerunOf = BuildRunDomain + "/rerun-of"
 )
 
 // BuildRunSpec defines the desired state of BuildRun
 type BuildRunSpec struct {
-	// BuildRef refers to the Build
-	BuildRef BuildRef `json:"buildRef"`
+	// BuildRef refers to the Build, exactly one of BuildRef and BuildSpec
+	// must be set
+	// +optional
+	BuildRef *BuildRef `json:"buildRef,omitempty"`
+
+	// BuildSpec is an embedded Build spec for one-off builds without a Build,
+	// exactly one of BuildRef and BuildSpec must be set
+	// +optional
+	BuildSpec *BuildSpec `json:"buildSpec,omitempty"`
 
 	// Sources slice of BuildSource, defining external build artifacts complementary to VCS
 	// (`.spec.source`) data.
@@ -59,6 +78,38 @@ type BuildRunSpec struct {
 	// Env contains additional environment variables that should be passed to the build container
 	// +optional
 	Env []corev1.EnvVar `json:"env,omitempty"`
+
+	// Retry defines whether and how often this BuildRun is retried after a failure.
+	// It will overwrite the retry policy of the build spec
+	// +optional
+	Retry *RetryPolicy `json:"retry,omitempty"`
+
+	// SkipIfUnchanged defines whether this BuildRun succeeds without a TaskRun
+	// if its inputs equal the ones of the last succeeded BuildRun of the Build.
+	// It will overwrite the setting of the build spec
+	// +optional
+	SkipIfUnchanged *bool `json:"skipIfUnchanged,omitempty"`
+
+	// PinnedSources pin sources of the Build to a Git commit or a digest, they
+	// are set when a BuildRun is rerun so that it builds the same sources
+	// +optional
+	PinnedSources []PinnedSource `json:"pinnedSources,omitempty"`
+}
+
+// PinnedSource pins a source of the Build to a Git commit or a digest
+type PinnedSource struct {
+	// Name is the name of the source, default for spec.source of the Build,
+	// or the name of a source in spec.sources
+	Name string `json:"name"`
+
+	// Revision is the Git commit that a Git source is pinned to
+	// +optional
+	Revision string `json:"revision,omitempty"`
+
+	// Digest is the digest that a bundle image or a remote artifact is
+	// pinned to
+	// +optional
+	Digest string `json:"digest,omitempty"`
 }
 
 // BuildRunRequestedState defines the buildrun state the user can provide to override whatever is the current state.
@@ -79,6 +130,25 @@ const (
 	BuildRunStatePodEvicted = "PodEvicted"
 )
 
+// Reasons of the Kubernetes events of the controllers that are not a reason
+// of the Succeeded condition of a BuildRun or of the status of a Build
+const (
+	// EventReasonTaskRunCreated indicates that the TaskRun of a BuildRun was created
+	EventReasonTaskRunCreated = "TaskRunCreated"
+
+	// EventReasonBuildRunCreated indicates that a BuildRun of a Build was created
+	// according to the schedule of the Build
+	EventReasonBuildRunCreated = "BuildRunCreated"
+
+	// EventReasonBuildRunDeleted indicates that a BuildRun was deleted according
+	// to the retention of its Build
+	EventReasonBuildRunDeleted = "BuildRunDeleted"
+
+	// EventReasonRerunIgnored indicates that the annotation to rerun a BuildRun
+	// was removed, because the BuildRun did not complete yet
+	EventReasonRerunIgnored = "RerunIgnored"
+)
+
 // SourceResult holds the results emitted from the different sources
 type SourceResult struct {
 	// Name is the name of source
@@ -95,12 +165,49 @@ type SourceResult struct {
 	//
 	// +optional
 	Bundle *BundleSourceResult `json:"bundle,omitempty"`
+
+	// HTTP holds the results emitted from the
+	// step definition of a HTTP source
+	//
+	// +optional
+	HTTP *HTTPSourceResult `json:"http,omitempty"`
+
+	// LocalCopy holds the results emitted from the
+	// step definition of a LocalCopy source
+	//
+	// +optional
+	LocalCopy *LocalCopySourceResult `json:"localCopy,omitempty"`
 }
 
 // BundleSourceResult holds the results emitted from the bundle source
 type BundleSourceResult struct {
 	// Digest hold the image digest result
 	Digest string `json:"digest,omitempty"`
+
+	// DigestVerified is true when the digest of the image matched the
+	// digest that the Build pins
+	DigestVerified bool `json:"digestVerified,omitempty"`
+
+	// SignatureVerified is true when the signature of the image was verified
+	// with the public key that the Build references
+	SignatureVerified bool `json:"signatureVerified,omitempty"`
+}
+
+// HTTPSourceResult holds the results emitted from a HTTP source
+type HTTPSourceResult struct {
+	// Digest holds the digest of the downloaded remote artifact
+	Digest string `json:"digest,omitempty"`
+}
+
+// LocalCopySourceResult holds the results emitted from a LocalCopy source
+type LocalCopySourceResult struct {
+	// FileCount holds the number of regular files in the target path after
+	// the upload
+	FileCount int `json:"fileCount,omitempty"`
+
+	// Checksum holds the checksum of the regular files in the target path
+	// after the upload in the format sha256:<hex>
+	Checksum string `json:"checksum,omitempty"`
 }
 
 // GitSourceResult holds the results emitted from the git source
@@ -167,6 +274,103 @@ type BuildRunStatus struct {
 	// FailureDetails contains error details that are collected and surfaced from TaskRun
 	// +optional
 	FailureDetails *FailureDetails `json:"failureDetails,omitempty"`
+
+	// Steps holds the state of the steps of the latest TaskRun of this BuildRun
+	// +optional
+	Steps []BuildRunStep `json:"steps,omitempty"`
+
+	// Logs references the archived logs of the steps of this BuildRun, they
+	// are archived when the BuildRun completes if the controller is configured
+	// with a sink for the logs
+	// +optional
+	Logs *BuildRunLogs `json:"logs,omitempty"`
+
+	// Attempts holds the failed attempts of this BuildRun that were retried.
+	// The current attempt is referenced by LatestTaskRunRef.
+	// +optional
+	Attempts []BuildRunAttempt `json:"attempts,omitempty"`
+
+	// Fingerprint identifies the inputs of this BuildRun, it is recorded if
+	// the Build skips unchanged builds
+	// +optional
+	Fingerprint *BuildRunFingerprint `json:"fingerprint,omitempty"`
+
+	// RerunOf is the name of the BuildRun that this BuildRun reruns
+	// +optional
+	RerunOf string `json:"rerunOf,omitempty"`
+}
+
+// BuildRunFingerprint identifies the inputs of a BuildRun: the resolved
+// revisions of its sources, the generation of its strategy, its parameter
+// values, its environment variables, and the digest of its builder image
+type BuildRunFingerprint struct {
+	// Digest is the sha256 digest of the inputs
+	Digest string `json:"digest"`
+
+	// Revisions are the commits of the Git sources by the name of the source,
+	// as they were resolved before the TaskRun was created
+	// +optional
+	Revisions map[string]string `json:"revisions,omitempty"`
+}
+
+// BuildRunLogs references the archived logs of the steps of a BuildRun
+type BuildRunLogs struct {
+	// Sink is the kind of storage of the logs, one of pvc, oci or s3
+	Sink string `json:"sink"`
+
+	// Reference locates the logs in the sink: the path of the file in the
+	// PersistentVolumeClaim, the reference of the OCI artifact with its
+	// digest, or the s3:// URL of the object
+	Reference string `json:"reference"`
+}
+
+// BuildRunStep describes the state of a step of the TaskRun of a BuildRun
+type BuildRunStep struct {
+	// Name is the name of the step
+	Name string `json:"name"`
+
+	// StartTime is the time the step started
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is the time the step terminated
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// ExitCode is the exit code of the step, it is set once the step terminated
+	// +optional
+	ExitCode *int32 `json:"exitCode,omitempty"`
+
+	// Reason is the reason of the termination of the step, for example
+	// Completed or Error
+	// +optional
+	Reason string `json:"reason,omitempty"`
+}
+
+// BuildRunAttempt describes a failed attempt of a BuildRun that was retried
+type BuildRunAttempt struct {
+	// TaskRunName is the name of the TaskRun of the attempt
+	TaskRunName string `json:"taskRunName"`
+
+	// Reason is the reason of the failure
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// Message describes the failure
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// StartTime is the time the attempt started
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is the time the attempt failed
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Logs references the archived logs of the steps of the attempt
+	// +optional
+	Logs *BuildRunLogs `json:"logs,omitempty"`
 }
 
 // FailedAt describes the location where the failure happened
@@ -250,6 +454,20 @@ func (br *BuildRun) IsCanceled() bool {
 	return br.Spec.State != nil && *br.Spec.State == BuildRunStateCancel
 }
 
+// BuildName returns the name of the referenced Build, or an empty string if the BuildRun embeds its Build spec.
+func (br *BuildRun) BuildName() string {
+	if br.Spec.BuildRef == nil {
+		return ""
+	}
+
+	return br.Spec.BuildRef.Name
+}
+
+// HasEmbeddedBuildSpec returns true if the BuildRun embeds its Build spec instead of referencing a Build.
+func (br *BuildRun) HasEmbeddedBuildSpec() bool {
+	return br.Spec.BuildSpec != nil
+}
+
 // Conditions defines a list of Condition
 type Conditions []Condition
 
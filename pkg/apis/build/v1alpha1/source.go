@@ -8,10 +8,55 @@ import (
 	corev1 "k8s.io/api/core/v1"
 )
 
+// PruneOption defines whether a bundle image is deleted from the registry
+type PruneOption string
+
+const (
+	// PruneNever keeps the bundle image in the registry
+	PruneNever PruneOption = "Never"
+
+	// PruneAfterPull deletes the bundle image from the registry after it
+	// was pulled successfully
+	PruneAfterPull PruneOption = "AfterPull"
+)
+
 // BundleContainer describes the source code bundle container to pull
 type BundleContainer struct {
 	// Image reference, i.e. quay.io/org/image:tag
 	Image string `json:"image"`
+
+	// Digest pins the expected digest of the bundle image in the format
+	// sha256:<hex>. The build fails if the image has a different digest.
+	//
+	// +kubebuilder:validation:Pattern=`^sha256:[a-f0-9]{64}$`
+	// +optional
+	Digest string `json:"digest,omitempty"`
+
+	// PublicKey references the public key that the bundle image must be
+	// signed with. The signature is expected in the format of cosign.
+	//
+	// +optional
+	PublicKey *PublicKeyReference `json:"publicKey,omitempty"`
+
+	// Prune defines whether the bundle image is deleted from the registry.
+	// Allowed values are Never, and AfterPull to delete the image after it
+	// was pulled successfully. The default is Never.
+	//
+	// +kubebuilder:validation:Enum=Never;AfterPull
+	// +optional
+	Prune *PruneOption `json:"prune,omitempty"`
+}
+
+// PublicKeyReference references a PEM encoded public key in a Secret
+type PublicKeyReference struct {
+	// Secret is the name of the Secret that contains the public key
+	Secret string `json:"secret"`
+
+	// Key is the key in the Secret that contains the public key, defaults
+	// to cosign.pub
+	//
+	// +optional
+	Key string `json:"key,omitempty"`
 }
 
 // Source describes the Git source repository to fetch.
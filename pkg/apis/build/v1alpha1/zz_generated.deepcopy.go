@@ -160,6 +160,58 @@ func (in *BuildRun) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildRunAttempt) DeepCopyInto(out *BuildRunAttempt) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Logs != nil {
+		in, out := &in.Logs, &out.Logs
+		*out = new(BuildRunLogs)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildRunAttempt.
+func (in *BuildRunAttempt) DeepCopy() *BuildRunAttempt {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildRunAttempt)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildRunFingerprint) DeepCopyInto(out *BuildRunFingerprint) {
+	*out = *in
+	if in.Revisions != nil {
+		in, out := &in.Revisions, &out.Revisions
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildRunFingerprint.
+func (in *BuildRunFingerprint) DeepCopy() *BuildRunFingerprint {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildRunFingerprint)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BuildRunList) DeepCopyInto(out *BuildRunList) {
 	*out = *in
@@ -193,10 +245,35 @@ func (in *BuildRunList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildRunLogs) DeepCopyInto(out *BuildRunLogs) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildRunLogs.
+func (in *BuildRunLogs) DeepCopy() *BuildRunLogs {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildRunLogs)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BuildRunSpec) DeepCopyInto(out *BuildRunSpec) {
 	*out = *in
-	in.BuildRef.DeepCopyInto(&out.BuildRef)
+	if in.BuildRef != nil {
+		in, out := &in.BuildRef, &out.BuildRef
+		*out = new(BuildRef)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BuildSpec != nil {
+		in, out := &in.BuildSpec, &out.BuildSpec
+		*out = new(BuildSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Sources != nil {
 		in, out := &in.Sources, &out.Sources
 		*out = make([]BuildSource, len(*in))
@@ -238,6 +315,21 @@ func (in *BuildRunSpec) DeepCopyInto(out *BuildRunSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Retry != nil {
+		in, out := &in.Retry, &out.Retry
+		*out = new(RetryPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SkipIfUnchanged != nil {
+		in, out := &in.SkipIfUnchanged, &out.SkipIfUnchanged
+		*out = new(bool)
+		**out = **in
+	}
+	if in.PinnedSources != nil {
+		in, out := &in.PinnedSources, &out.PinnedSources
+		*out = make([]PinnedSource, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -301,6 +393,30 @@ func (in *BuildRunStatus) DeepCopyInto(out *BuildRunStatus) {
 		*out = new(FailureDetails)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]BuildRunStep, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Logs != nil {
+		in, out := &in.Logs, &out.Logs
+		*out = new(BuildRunLogs)
+		**out = **in
+	}
+	if in.Attempts != nil {
+		in, out := &in.Attempts, &out.Attempts
+		*out = make([]BuildRunAttempt, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Fingerprint != nil {
+		in, out := &in.Fingerprint, &out.Fingerprint
+		*out = new(BuildRunFingerprint)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -314,6 +430,71 @@ func (in *BuildRunStatus) DeepCopy() *BuildRunStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildRunStep) DeepCopyInto(out *BuildRunStep) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ExitCode != nil {
+		in, out := &in.ExitCode, &out.ExitCode
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildRunStep.
+func (in *BuildRunStep) DeepCopy() *BuildRunStep {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildRunStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildSchedule) DeepCopyInto(out *BuildSchedule) {
+	*out = *in
+	if in.TimeZone != nil {
+		in, out := &in.TimeZone, &out.TimeZone
+		*out = new(string)
+		**out = **in
+	}
+	if in.StartingDeadline != nil {
+		in, out := &in.StartingDeadline, &out.StartingDeadline
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.SucceededHistoryLimit != nil {
+		in, out := &in.SucceededHistoryLimit, &out.SucceededHistoryLimit
+		*out = new(uint)
+		**out = **in
+	}
+	if in.FailedHistoryLimit != nil {
+		in, out := &in.FailedHistoryLimit, &out.FailedHistoryLimit
+		*out = new(uint)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildSchedule.
+func (in *BuildSchedule) DeepCopy() *BuildSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BuildSource) DeepCopyInto(out *BuildSource) {
 	*out = *in
@@ -322,6 +503,21 @@ func (in *BuildSource) DeepCopyInto(out *BuildSource) {
 		*out = new(v1.Duration)
 		**out = **in
 	}
+	if in.Revision != nil {
+		in, out := &in.Revision, &out.Revision
+		*out = new(string)
+		**out = **in
+	}
+	if in.Credentials != nil {
+		in, out := &in.Credentials, &out.Credentials
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	if in.Extract != nil {
+		in, out := &in.Extract, &out.Extract
+		*out = new(ArchiveFormat)
+		**out = **in
+	}
 	return
 }
 
@@ -382,6 +578,31 @@ func (in *BuildSpec) DeepCopyInto(out *BuildSpec) {
 		*out = new(BuildRetention)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Proxy != nil {
+		in, out := &in.Proxy, &out.Proxy
+		*out = new(Proxy)
+		**out = **in
+	}
+	if in.CABundle != nil {
+		in, out := &in.CABundle, &out.CABundle
+		*out = new(CABundle)
+		**out = **in
+	}
+	if in.Retry != nil {
+		in, out := &in.Retry, &out.Retry
+		*out = new(RetryPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = new(BuildSchedule)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SkipIfUnchanged != nil {
+		in, out := &in.SkipIfUnchanged, &out.SkipIfUnchanged
+		*out = new(bool)
+		**out = **in
+	}
 	return
 }
 
@@ -413,6 +634,10 @@ func (in *BuildStatus) DeepCopyInto(out *BuildStatus) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
 	return
 }
 
@@ -553,6 +778,16 @@ func (in *BuildStrategyStatus) DeepCopy() *BuildStrategyStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BundleContainer) DeepCopyInto(out *BundleContainer) {
 	*out = *in
+	if in.PublicKey != nil {
+		in, out := &in.PublicKey, &out.PublicKey
+		*out = new(PublicKeyReference)
+		**out = **in
+	}
+	if in.Prune != nil {
+		in, out := &in.Prune, &out.Prune
+		*out = new(PruneOption)
+		**out = **in
+	}
 	return
 }
 
@@ -582,6 +817,22 @@ func (in *BundleSourceResult) DeepCopy() *BundleSourceResult {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CABundle) DeepCopyInto(out *CABundle) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CABundle.
+func (in *CABundle) DeepCopy() *CABundle {
+	if in == nil {
+		return nil
+	}
+	out := new(CABundle)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterBuildStrategy) DeepCopyInto(out *ClusterBuildStrategy) {
 	*out = *in
@@ -735,6 +986,22 @@ func (in *GitSourceResult) DeepCopy() *GitSourceResult {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPSourceResult) DeepCopyInto(out *HTTPSourceResult) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPSourceResult.
+func (in *HTTPSourceResult) DeepCopy() *HTTPSourceResult {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPSourceResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Image) DeepCopyInto(out *Image) {
 	*out = *in
@@ -757,6 +1024,11 @@ func (in *Image) DeepCopyInto(out *Image) {
 			(*out)[key] = val
 		}
 	}
+	if in.SourceAnnotations != nil {
+		in, out := &in.SourceAnnotations, &out.SourceAnnotations
+		*out = new(bool)
+		**out = **in
+	}
 	return
 }
 
@@ -770,6 +1042,22 @@ func (in *Image) DeepCopy() *Image {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalCopySourceResult) DeepCopyInto(out *LocalCopySourceResult) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalCopySourceResult.
+func (in *LocalCopySourceResult) DeepCopy() *LocalCopySourceResult {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalCopySourceResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ObjectKeyRef) DeepCopyInto(out *ObjectKeyRef) {
 	*out = *in
@@ -865,6 +1153,80 @@ func (in *Parameter) DeepCopy() *Parameter {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PinnedSource) DeepCopyInto(out *PinnedSource) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PinnedSource.
+func (in *PinnedSource) DeepCopy() *PinnedSource {
+	if in == nil {
+		return nil
+	}
+	out := new(PinnedSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Proxy) DeepCopyInto(out *Proxy) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Proxy.
+func (in *Proxy) DeepCopy() *Proxy {
+	if in == nil {
+		return nil
+	}
+	out := new(Proxy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PublicKeyReference) DeepCopyInto(out *PublicKeyReference) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PublicKeyReference.
+func (in *PublicKeyReference) DeepCopy() *PublicKeyReference {
+	if in == nil {
+		return nil
+	}
+	out := new(PublicKeyReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetryPolicy) DeepCopyInto(out *RetryPolicy) {
+	*out = *in
+	if in.Backoff != nil {
+		in, out := &in.Backoff, &out.Backoff
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Reasons != nil {
+		in, out := &in.Reasons, &out.Reasons
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetryPolicy.
+func (in *RetryPolicy) DeepCopy() *RetryPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RetryPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceAccount) DeepCopyInto(out *ServiceAccount) {
 	*out = *in
@@ -933,7 +1295,7 @@ func (in *Source) DeepCopyInto(out *Source) {
 	if in.BundleContainer != nil {
 		in, out := &in.BundleContainer, &out.BundleContainer
 		*out = new(BundleContainer)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Revision != nil {
 		in, out := &in.Revision, &out.Revision
@@ -976,6 +1338,16 @@ func (in *SourceResult) DeepCopyInto(out *SourceResult) {
 		*out = new(BundleSourceResult)
 		**out = **in
 	}
+	if in.HTTP != nil {
+		in, out := &in.HTTP, &out.HTTP
+		*out = new(HTTPSourceResult)
+		**out = **in
+	}
+	if in.LocalCopy != nil {
+		in, out := &in.LocalCopy, &out.LocalCopy
+		*out = new(LocalCopySourceResult)
+		**out = **in
+	}
 	return
 }
 
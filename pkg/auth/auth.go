@@ -0,0 +1,114 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package auth authenticates and authorizes the users of the endpoints that the
+// controller serves for the subresources of BuildRuns
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	buildv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+)
+
+var (
+	// ErrUnauthenticated is returned when the token of a request does not
+	// authenticate a user
+	ErrUnauthenticated = errors.New("unauthenticated")
+
+	// ErrForbidden is returned when the user of a request is not allowed to
+	// access the subresource of a BuildRun, for example to upload its source code
+	// or to read its logs
+	ErrForbidden = errors.New("forbidden")
+)
+
+// Authorizer decides whether the user that a bearer token belongs to is allowed
+// to access a subresource of a BuildRun, for example to upload its source code
+// or to read its logs
+type Authorizer interface {
+	// Authorize returns the name of the user, or an error that wraps
+	// ErrUnauthenticated or ErrForbidden
+	Authorize(ctx context.Context, token string, namespace string, name string) (string, error)
+}
+
+// NewKubernetesSubresourceAuthorizer returns an authorizer that authenticates
+// the token with a TokenReview, and authorizes the user with a
+// SubjectAccessReview for the verb on the subresource of the BuildRun
+func NewKubernetesSubresourceAuthorizer(client client.Client, verb string, subresource string) Authorizer {
+	return &kubernetesAuthorizer{client: client, verb: verb, subresource: subresource}
+}
+
+// BearerToken returns the token of the Authorization header of a request
+func BearerToken(req *http.Request) (string, bool) {
+	const prefix = "Bearer "
+
+	header := req.Header.Get("Authorization")
+	if len(header) <= len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return "", false
+	}
+
+	return strings.TrimSpace(header[len(prefix):]), true
+}
+
+type kubernetesAuthorizer struct {
+	client      client.Client
+	verb        string
+	subresource string
+}
+
+func (a *kubernetesAuthorizer) Authorize(ctx context.Context, token string, namespace string, name string) (string, error) {
+	tokenReview := &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token},
+	}
+
+	if err := a.client.Create(ctx, tokenReview); err != nil {
+		return "", err
+	}
+
+	if !tokenReview.Status.Authenticated {
+		return "", fmt.Errorf("%w: %s", ErrUnauthenticated, tokenReview.Status.Error)
+	}
+
+	user := tokenReview.Status.User
+
+	extra := make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+	for key, value := range user.Extra {
+		extra[key] = authorizationv1.ExtraValue(value)
+	}
+
+	subjectAccessReview := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace:   namespace,
+				Verb:        a.verb,
+				Group:       buildv1alpha1.SchemeGroupVersion.Group,
+				Resource:    "buildruns",
+				Subresource: a.subresource,
+				Name:        name,
+			},
+			User:   user.Username,
+			UID:    user.UID,
+			Groups: user.Groups,
+			Extra:  extra,
+		},
+	}
+
+	if err := a.client.Create(ctx, subjectAccessReview); err != nil {
+		return "", err
+	}
+
+	if !subjectAccessReview.Status.Allowed {
+		return "", fmt.Errorf("%w: user %s cannot %s buildruns/%s for %s in namespace %s", ErrForbidden, user.Username, a.verb, a.subresource, name, namespace)
+	}
+
+	return user.Username, nil
+}
@@ -0,0 +1,106 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package auth_test
+
+import (
+	"context"
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	crc "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/shipwright-io/build/pkg/auth"
+	"github.com/shipwright-io/build/pkg/controller/fakes"
+)
+
+var _ = Describe("Auth", func() {
+	Context("reading the bearer token of a request", func() {
+		request := func(header string) *http.Request {
+			req, err := http.NewRequest(http.MethodGet, "https://shipwright.io", nil)
+			Expect(err).ToNot(HaveOccurred())
+			if header != "" {
+				req.Header.Set("Authorization", header)
+			}
+			return req
+		}
+
+		It("returns the token", func() {
+			token, ok := auth.BearerToken(request("bearer  secret-token "))
+			Expect(ok).To(BeTrue())
+			Expect(token).To(Equal("secret-token"))
+		})
+
+		It("rejects requests without bearer token", func() {
+			_, ok := auth.BearerToken(request(""))
+			Expect(ok).To(BeFalse())
+
+			_, ok = auth.BearerToken(request("Basic dXNlcjpwYXNz"))
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Context("authorizing the user of a token", func() {
+		var (
+			client        *fakes.FakeClient
+			authenticated bool
+			allowed       bool
+			reviews       []*authorizationv1.SubjectAccessReview
+		)
+
+		BeforeEach(func() {
+			authenticated, allowed, reviews = true, true, nil
+
+			client = &fakes.FakeClient{}
+			client.CreateCalls(func(_ context.Context, object crc.Object, _ ...crc.CreateOption) error {
+				switch review := object.(type) {
+				case *authenticationv1.TokenReview:
+					review.Status.Authenticated = authenticated
+					review.Status.User = authenticationv1.UserInfo{Username: "developer", Groups: []string{"team"}}
+				case *authorizationv1.SubjectAccessReview:
+					reviews = append(reviews, review)
+					review.Status.Allowed = allowed
+				}
+				return nil
+			})
+		})
+
+		It("returns the user that is allowed to access the subresource", func() {
+			user, err := auth.NewKubernetesSubresourceAuthorizer(client, "get", "log").Authorize(context.TODO(), "token", "ns", "buildrun")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(user).To(Equal("developer"))
+
+			Expect(reviews).To(HaveLen(1))
+			Expect(reviews[0].Spec.User).To(Equal("developer"))
+			Expect(reviews[0].Spec.Groups).To(ConsistOf("team"))
+			Expect(*reviews[0].Spec.ResourceAttributes).To(Equal(authorizationv1.ResourceAttributes{
+				Namespace:   "ns",
+				Verb:        "get",
+				Group:       "shipwright.io",
+				Resource:    "buildruns",
+				Subresource: "log",
+				Name:        "buildrun",
+			}))
+		})
+
+		It("fails for a token that does not authenticate a user", func() {
+			authenticated = false
+
+			_, err := auth.NewKubernetesSubresourceAuthorizer(client, "create", "upload").Authorize(context.TODO(), "token", "ns", "buildrun")
+			Expect(err).To(MatchError(auth.ErrUnauthenticated))
+			Expect(reviews).To(BeEmpty())
+		})
+
+		It("fails for a user that is not allowed to access the subresource", func() {
+			allowed = false
+
+			_, err := auth.NewKubernetesSubresourceAuthorizer(client, "create", "upload").Authorize(context.TODO(), "token", "ns", "buildrun")
+			Expect(err).To(MatchError(auth.ErrForbidden))
+		})
+	})
+})
@@ -6,8 +6,8 @@ package bundle
 
 import (
 	"archive/tar"
-	"bufio"
-	"bytes"
+	"compress/gzip"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/fs"
@@ -16,46 +16,126 @@ import (
 	"strings"
 	"time"
 
-	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
 	"github.com/google/go-containerregistry/pkg/name"
 	containerreg "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/empty"
 	"github.com/google/go-containerregistry/pkg/v1/mutate"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/stream"
 	"github.com/google/go-containerregistry/pkg/v1/tarball"
 )
 
 const shpIgnoreFilename = ".shpignore"
 
+// ProgressFunc is called with the number of bytes that were processed so far
+type ProgressFunc func(processed int64)
+
+// PackOptions are the optional settings to pack a directory into a bundle image
+type PackOptions struct {
+	// CompressionLevel is the gzip compression level of the layers, see
+	// compress/gzip for the possible values, for example gzip.DefaultCompression
+	CompressionLevel int
+
+	// LayerStrategy defines how the content is split into layers, the
+	// default is SingleLayer
+	LayerStrategy LayerStrategy
+
+	// DockerIgnore excludes the files that the .dockerignore file of the
+	// directory configures, in addition to the .shpignore and .gitignore files
+	DockerIgnore bool
+}
+
 // PackAndPush a local directory as-is into a container image. See
 // remote.Option for optional options to the image push to the registry, for
 // example to provide the appropriate access credentials.
 func PackAndPush(ref name.Reference, directory string, options ...remote.Option) (name.Digest, error) {
-	r, err := Pack(directory)
-	if err != nil {
-		return name.Digest{}, err
+	return PackAndPushWithOptions(ref, directory, PackOptions{CompressionLevel: gzip.DefaultCompression}, options...)
+}
+
+// PackAndPushWithCompressionLevel a local directory as-is into a container
+// image, with the given gzip compression level for the image layer.
+func PackAndPushWithCompressionLevel(ref name.Reference, directory string, level int, options ...remote.Option) (name.Digest, error) {
+	return PackAndPushWithOptions(ref, directory, PackOptions{CompressionLevel: level}, options...)
+}
+
+// PackAndPushWithOptions a local directory into a container image, with its
+// content split into layers according to the layer strategy. The layers are
+// deterministic for the same content, so that a registry does not need to
+// receive unchanged layers again. The directory content is streamed into the
+// registry, so that the memory usage does not depend on the size of the
+// directory. A single layer is packed once while it is pushed, the layers of
+// the other strategies are packed again to compute their digests first.
+func PackAndPushWithOptions(ref name.Reference, directory string, packOptions PackOptions, options ...remote.Option) (name.Digest, error) {
+	level := packOptions.CompressionLevel
+	if level < gzip.HuffmanOnly || level > gzip.BestCompression {
+		return name.Digest{}, fmt.Errorf("invalid compression level %d", level)
 	}
 
-	bundleLayer, err := tarball.LayerFromReader(r)
+	definitions, err := layerDefinitions(directory, packOptions)
 	if err != nil {
 		return name.Digest{}, err
 	}
 
-	image, err := mutate.AppendLayers(empty.Image, bundleLayer)
+	image, err := mutate.CreatedAt(empty.Image, containerreg.Time{Time: time.Unix(0, 0)})
 	if err != nil {
 		return name.Digest{}, err
 	}
 
-	image, err = mutate.Time(image, time.Unix(0, 0))
+	contentManifest := ContentManifest{
+		LayerStrategy: packOptions.LayerStrategy,
+		Layers:        make([]ContentLayer, 0, len(definitions)),
+	}
+
+	if contentManifest.LayerStrategy == "" {
+		contentManifest.LayerStrategy = SingleLayer
+	}
+
+	for _, definition := range definitions {
+		var layer containerreg.Layer
+		if len(definitions) == 1 {
+			layer, err = pushStreamedLayer(ref, directory, packOptions, definition, options...)
+		} else {
+			layer, err = openedLayer(directory, packOptions, definition)
+		}
+		if err != nil {
+			return name.Digest{}, err
+		}
+
+		digest, err := layer.Digest()
+		if err != nil {
+			return name.Digest{}, err
+		}
+
+		image, err = mutate.Append(image, mutate.Addendum{
+			Layer:   layer,
+			History: containerreg.History{Created: containerreg.Time{Time: time.Unix(0, 0)}},
+		})
+		if err != nil {
+			return name.Digest{}, err
+		}
+
+		contentManifest.Layers = append(contentManifest.Layers, ContentLayer{
+			Name:   definition.name,
+			Digest: digest.String(),
+			Paths:  definition.paths,
+		})
+	}
+
+	data, err := json.Marshal(contentManifest)
 	if err != nil {
 		return name.Digest{}, err
 	}
 
+	image = mutate.Annotations(image, map[string]string{
+		ContentManifestAnnotation: string(data),
+	}).(containerreg.Image)
+
 	hash, err := image.Digest()
 	if err != nil {
 		return name.Digest{}, err
 	}
 
+	// layers that the registry already has are not uploaded again
 	if err := remote.Write(ref, image, options...); err != nil {
 		return name.Digest{}, err
 	}
@@ -66,10 +146,45 @@ func PackAndPush(ref name.Reference, directory string, options ...remote.Option)
 	))
 }
 
+// pushStreamedLayer packs the content of the layer definition while it is
+// pushed, so that the directory is only read once. The digest of the layer is
+// known afterwards, the image that references the layer is pushed later.
+func pushStreamedLayer(ref name.Reference, directory string, packOptions PackOptions, definition layerDefinition, options ...remote.Option) (containerreg.Layer, error) {
+	rc, err := pack(directory, packOptions, true, definition.include)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	layer := stream.NewLayer(rc, stream.WithCompressionLevel(packOptions.CompressionLevel))
+	if err := remote.WriteLayer(ref.Context(), layer, options...); err != nil {
+		return nil, err
+	}
+
+	return layer, nil
+}
+
+// openedLayer returns a layer that packs the content of the layer definition
+// whenever it is read, its digest is computed before the image is pushed, so
+// that the registry does not receive layers again that it already has
+func openedLayer(directory string, packOptions PackOptions, definition layerDefinition) (containerreg.Layer, error) {
+	return tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+		return pack(directory, packOptions, true, definition.include)
+	}, tarball.WithCompressionLevel(packOptions.CompressionLevel))
+}
+
 // PullAndUnpack a container image layer content into a local directory. Analog
 // to the bundle.PackAndPush function, optional remote.Option can be used to
 // configure settings for the image pull, i.e. access credentials.
 func PullAndUnpack(ref name.Reference, targetPath string, options ...remote.Option) (*containerreg.Image, error) {
+	return PullAndUnpackWithProgress(ref, targetPath, nil, options...)
+}
+
+// PullAndUnpackWithProgress a container image layer content into a local
+// directory, and report the number of extracted bytes to the progress function.
+// The layers are streamed to disk, so that the memory usage does not depend on
+// the size of the image.
+func PullAndUnpackWithProgress(ref name.Reference, targetPath string, progress ProgressFunc, options ...remote.Option) (*containerreg.Image, error) {
 	desc, err := remote.Get(ref, options...)
 	if err != nil {
 		return nil, err
@@ -80,23 +195,55 @@ func PullAndUnpack(ref name.Reference, targetPath string, options ...remote.Opti
 		return nil, err
 	}
 
-	rc := mutate.Extract(image)
-	defer rc.Close()
-
-	if err = Unpack(rc, targetPath); err != nil {
+	layers, err := image.Layers()
+	if err != nil {
 		return nil, err
 	}
 
+	// the layers are applied in order, so that whiteouts of a layer remove
+	// the content of the layers before it
+	counter := &progressReader{progress: progress}
+	for _, layer := range layers {
+		if err := unpackLayer(layer, targetPath, counter); err != nil {
+			return nil, err
+		}
+	}
+
 	return &image, nil
 }
 
+func unpackLayer(layer containerreg.Layer, targetPath string, counter *progressReader) error {
+	rc, err := layer.Uncompressed()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	var in io.Reader = rc
+	if counter.progress != nil {
+		counter.reader = rc
+		in = counter
+	}
+
+	return Unpack(in, targetPath)
+}
+
 // Pack reads a directory and creates a tar stream with its content by:
 // - storing all directories and regular files as-is,
 // - dereferencing all symlinks and storing the respective target,
-// - ignoring all files configured in .shpignore
-func Pack(directory string) (io.Reader, error) {
-	var split = func(path string) []string { return strings.Split(path, string(filepath.Separator)) }
+// - ignoring all files configured in .shpignore and the .gitignore files
+//
+// The tar stream is written while it is read, closing the returned reader
+// before the end of the stream stops the processing of the directory.
+func Pack(directory string) (io.ReadCloser, error) {
+	return pack(directory, PackOptions{}, false, nil)
+}
 
+// pack creates the tar stream of a directory, with normalizeTimes all
+// modification times are set to the Unix epoch to create a reproducible stream.
+// The optional include function decides which top-level entries, including
+// their content, are part of the stream, the directory itself is named ".".
+func pack(directory string, packOptions PackOptions, normalizeTimes bool, include func(topLevel string) bool) (io.ReadCloser, error) {
 	var write = func(w io.Writer, path string) error {
 		file, err := os.Open(path)
 		if err != nil {
@@ -126,147 +273,112 @@ func Pack(directory string) (io.Reader, error) {
 		return deref, info, err
 	}
 
-	var patterns []gitignore.Pattern
-	if file, err := os.Open(filepath.Join(directory, shpIgnoreFilename)); err == nil {
-		defer file.Close()
-
-		domain := split(directory)
-		scanner := bufio.NewScanner(file)
-		for scanner.Scan() {
-			line := strings.TrimSpace(scanner.Text())
-			if len(line) != 0 && !strings.HasPrefix(line, "#") {
-				patterns = append(patterns, gitignore.ParsePattern(line, domain))
-			}
+	var writeHeader = func(tw *tar.Writer, header *tar.Header) error {
+		if normalizeTimes {
+			header.ModTime = time.Unix(0, 0)
 		}
 
-		if err := scanner.Err(); err != nil {
-			return nil, err
-		}
+		return tw.WriteHeader(header)
 	}
 
-	matcher := gitignore.NewMatcher(patterns)
-
-	var buf bytes.Buffer
-	var tw = tar.NewWriter(&buf)
-	defer tw.Close()
-
-	err := filepath.WalkDir(directory, func(path string, d fs.DirEntry, err error) error {
-		// Bail out on path errors
-		if err != nil {
-			return err
-		}
-
-		// Skip files on the ignore list
-		if matcher.Match(split(path), d.IsDir()) {
-			if d.IsDir() {
-				return filepath.SkipDir
-			}
-
-			return nil
-		}
-
-		info, err := d.Info()
-		if err != nil {
-			return err
-		}
-
-		header, err := tar.FileInfoHeader(info, path)
-		if err != nil {
-			return err
-		}
-
-		header.Name, err = filepath.Rel(directory, path)
-		if err != nil {
-			return err
-		}
-
-		switch {
-		case info.Mode().IsDir():
-			return tw.WriteHeader(header)
-
-		case info.Mode().IsRegular():
-			if err := tw.WriteHeader(header); err != nil {
-				return err
+	var writeDirectory = func(tw *tar.Writer) error {
+		return walk(directory, packOptions, func(path string, name string, d fs.DirEntry, excluded bool, _ *IgnoreRule) error {
+			// Skip files on the ignore list, the walk does not descend into
+			// excluded directories unless content of them is re-included
+			if excluded {
+				return nil
 			}
 
-			return write(tw, path)
-
-		case info.Mode()&os.ModeSymlink == os.ModeSymlink:
-			deref, info, err := followSymLink(path)
+			info, err := d.Info()
 			if err != nil {
 				return err
 			}
 
-			header, err = tar.FileInfoHeader(info, deref)
+			header, err := tar.FileInfoHeader(info, path)
 			if err != nil {
 				return err
 			}
 
-			header.Name, err = filepath.Rel(directory, path)
-			if err != nil {
-				return err
-			}
+			header.Name = name
 
-			if err := tw.WriteHeader(header); err != nil {
-				return err
+			// Skip top-level entries that are not part of the stream
+			if include != nil && !include(split(header.Name)[0]) {
+				if d.IsDir() && header.Name != "." {
+					return filepath.SkipDir
+				}
+
+				return nil
 			}
 
-			return write(tw, deref)
+			switch {
+			case info.Mode().IsDir():
+				return writeHeader(tw, header)
 
-		default:
-			return fmt.Errorf("unsupported file type: %s", path)
-		}
-	})
+			case info.Mode().IsRegular():
+				if err := writeHeader(tw, header); err != nil {
+					return err
+				}
 
-	return &buf, err
-}
+				return write(tw, path)
 
-// Unpack reads a tar stream and writes the content into the local file system
-// with all files and directories.
-func Unpack(in io.Reader, targetPath string) error {
-	var tr = tar.NewReader(in)
-	for {
-		header, err := tr.Next()
-		switch {
-		case err == io.EOF:
-			return nil
-
-		case err != nil:
-			return err
+			case info.Mode()&os.ModeSymlink == os.ModeSymlink:
+				deref, info, err := followSymLink(path)
+				if err != nil {
+					return err
+				}
 
-		case header == nil:
-			continue
-		}
+				header, err = tar.FileInfoHeader(info, deref)
+				if err != nil {
+					return err
+				}
 
-		var target = filepath.Join(targetPath, header.Name)
-		switch header.Typeflag {
-		case tar.TypeDir:
-			if err := os.MkdirAll(target, os.FileMode(header.Mode)); err != nil {
-				return err
-			}
+				header.Name = name
 
-		case tar.TypeReg:
-			// Edge case in which that tarball did not have a directory entry
-			dir, _ := filepath.Split(target)
-			if err := os.MkdirAll(dir, os.FileMode(0755)); err != nil {
-				return err
-			}
+				if err := writeHeader(tw, header); err != nil {
+					return err
+				}
 
-			file, err := os.OpenFile(target, os.O_CREATE|os.O_RDWR, os.FileMode(header.Mode))
-			if err != nil {
-				return err
-			}
+				return write(tw, deref)
 
-			if _, err := io.Copy(file, tr); err != nil {
-				file.Close()
-				return err
+			default:
+				return fmt.Errorf("unsupported file type: %s", path)
 			}
+		})
+	}
 
-			file.Close()
-			os.Chtimes(target, header.AccessTime, header.ModTime)
+	pr, pw := io.Pipe()
+	go func() {
+		tw := tar.NewWriter(pw)
 
-		default:
-			return fmt.Errorf("provided tarball contains unsupported file type, only directories and regular files are supported")
+		err := writeDirectory(tw)
+		if err == nil {
+			err = tw.Close()
 		}
+
+		// a nil error results in io.EOF for the reader
+		pw.CloseWithError(err)
+	}()
+
+	return pr, nil
+}
+
+func split(path string) []string {
+	return strings.Split(path, string(filepath.Separator))
+}
+
+// progressReader reports the number of bytes that were read
+type progressReader struct {
+	reader    io.Reader
+	progress  ProgressFunc
+	processed int64
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.reader.Read(b)
+	p.processed += int64(n)
+	if n > 0 {
+		p.progress(p.processed)
 	}
+
+	return n, err
 }
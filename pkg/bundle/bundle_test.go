@@ -5,22 +5,77 @@
 package bundle_test
 
 import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
 	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	containerreg "github.com/google/go-containerregistry/pkg/v1"
+
 	. "github.com/shipwright-io/build/pkg/bundle"
 )
 
 var _ = Describe("Bundle", func() {
+	var withTempDir = func(f func(tempDir string)) {
+		tempDir, err := ioutil.TempDir("", "bundle")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(tempDir)
+
+		f(tempDir)
+	}
+
+	var uploads int
+
+	var withRegistry = func(f func(host string)) {
+		handler := registry.New(registry.Logger(log.New(ioutil.Discard, "", 0)))
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/blobs/uploads/") {
+				uploads++
+			}
+			handler.ServeHTTP(w, r)
+		}))
+		defer server.Close()
+
+		uploads = 0
+		f(strings.TrimPrefix(server.URL, "http://"))
+	}
+
+	var writeFiles = func(directory string, files map[string]string) {
+		for path, content := range files {
+			Expect(os.MkdirAll(filepath.Join(directory, filepath.Dir(path)), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(directory, path), []byte(content), 0644)).To(Succeed())
+		}
+	}
+
+	var pull = func(ref name.Reference, f func(img containerreg.Image, tempDir string)) {
+		withTempDir(func(tempDir string) {
+			img, err := PullAndUnpack(ref, tempDir)
+			Expect(err).ToNot(HaveOccurred())
+
+			f(*img, tempDir)
+		})
+	}
+
 	Context("packing and unpacking", func() {
 		It("should pack and unpack a directory", func() {
 			r, err := Pack("../../test/bundle")
 			Expect(err).ToNot(HaveOccurred())
 			Expect(r).ToNot(BeNil())
+			defer r.Close()
 
 			tempDir, err := ioutil.TempDir("", "bundle")
 			Expect(err).ToNot(HaveOccurred())
@@ -36,5 +91,241 @@ var _ = Describe("Bundle", func() {
 			Expect(filepath.Join(tempDir, "somefile")).To(BeAnExistingFile())
 			Expect(filepath.Join(tempDir, "linktofile")).To(BeAnExistingFile())
 		})
+
+		It("should fail to read the stream in case the directory does not exist", func() {
+			r, err := Pack("../../test/does-not-exist")
+			Expect(err).ToNot(HaveOccurred())
+			defer r.Close()
+
+			_, err = io.Copy(ioutil.Discard, r)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should stream a multi-GB directory with bounded memory", func() {
+			if testing.Short() {
+				Skip("skipping the multi-GB directory in short mode")
+			}
+
+			const files, fileSize = 4, 512 * 1024 * 1024
+
+			withTempDir(func(tempDir string) {
+				// sparse files do not occupy disk space, but are read with their full size
+				for i := 0; i < files; i++ {
+					file, err := os.Create(filepath.Join(tempDir, fmt.Sprintf("file-%d", i)))
+					Expect(err).ToNot(HaveOccurred())
+					Expect(file.Truncate(fileSize)).To(Succeed())
+					Expect(file.Close()).To(Succeed())
+				}
+
+				var before, after runtime.MemStats
+				runtime.GC()
+				runtime.ReadMemStats(&before)
+
+				r, err := Pack(tempDir)
+				Expect(err).ToNot(HaveOccurred())
+				defer r.Close()
+
+				var size int64
+				tr := tar.NewReader(r)
+				for {
+					header, err := tr.Next()
+					if err == io.EOF {
+						break
+					}
+					Expect(err).ToNot(HaveOccurred())
+
+					n, err := io.Copy(ioutil.Discard, tr)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(n).To(Equal(header.Size))
+					size += n
+				}
+
+				runtime.ReadMemStats(&after)
+
+				Expect(size).To(BeNumerically("==", files*fileSize))
+				Expect(after.TotalAlloc - before.TotalAlloc).To(BeNumerically("<", 64*1024*1024))
+			})
+		})
+	})
+
+	Context("pushing and pulling", func() {
+		It("should push a bundle and pull it with progress", func() {
+			withRegistry(func(host string) {
+				ref, err := name.ParseReference(host + "/test/bundle:latest")
+				Expect(err).ToNot(HaveOccurred())
+
+				digest, err := PackAndPushWithCompressionLevel(ref, "../../test/bundle", gzip.BestSpeed)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(digest.DigestStr()).To(HavePrefix("sha256:"))
+
+				withTempDir(func(tempDir string) {
+					var processed int64
+					img, err := PullAndUnpackWithProgress(ref, tempDir, func(n int64) { processed = n })
+					Expect(err).ToNot(HaveOccurred())
+
+					pulled, err := (*img).Digest()
+					Expect(err).ToNot(HaveOccurred())
+					Expect(pulled.String()).To(Equal(digest.DigestStr()))
+
+					Expect(processed).To(BeNumerically(">", 0))
+					Expect(filepath.Join(tempDir, "README.md")).To(BeAnExistingFile())
+					Expect(filepath.Join(tempDir, ".someToolDir", "config.json")).ToNot(BeAnExistingFile())
+				})
+			})
+		})
+
+		It("should create the same image for the same content", func() {
+			withRegistry(func(host string) {
+				ref, err := name.ParseReference(host + "/test/bundle:latest")
+				Expect(err).ToNot(HaveOccurred())
+
+				first, err := PackAndPush(ref, "../../test/bundle")
+				Expect(err).ToNot(HaveOccurred())
+
+				second, err := PackAndPush(ref, "../../test/bundle")
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(second).To(Equal(first))
+			})
+		})
+
+		It("should stream a single layer and reference it in the content manifest", func() {
+			withRegistry(func(host string) {
+				ref, err := name.ParseReference(host + "/test/bundle:latest")
+				Expect(err).ToNot(HaveOccurred())
+
+				digest, err := PackAndPush(ref, "../../test/bundle")
+				Expect(err).ToNot(HaveOccurred())
+
+				// the layer and the configuration
+				Expect(uploads).To(Equal(2))
+
+				pull(ref, func(img containerreg.Image, tempDir string) {
+					pulled, err := img.Digest()
+					Expect(err).ToNot(HaveOccurred())
+					Expect(pulled.String()).To(Equal(digest.DigestStr()))
+
+					layers, err := img.Layers()
+					Expect(err).ToNot(HaveOccurred())
+					Expect(len(layers)).To(Equal(1))
+
+					layerDigest, err := layers[0].Digest()
+					Expect(err).ToNot(HaveOccurred())
+
+					contentManifest, err := ReadContentManifest(img)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(contentManifest.LayerStrategy).To(Equal(SingleLayer))
+					Expect(len(contentManifest.Layers)).To(Equal(1))
+					Expect(contentManifest.Layers[0].Digest).To(Equal(layerDigest.String()))
+
+					Expect(filepath.Join(tempDir, "README.md")).To(BeAnExistingFile())
+				})
+			})
+		})
+
+		It("should push every top-level directory as a separate layer", func() {
+			withRegistry(func(host string) {
+				ref, err := name.ParseReference(host + "/test/bundle:latest")
+				Expect(err).ToNot(HaveOccurred())
+
+				withTempDir(func(source string) {
+					writeFiles(source, map[string]string{
+						"README.md":          "# Sample",
+						"src/main.go":        "package main",
+						"vendor/lib/lib.go":  "package lib",
+						"vendor/modules.txt": "lib",
+					})
+
+					_, err := PackAndPushWithOptions(ref, source, PackOptions{
+						CompressionLevel: gzip.DefaultCompression,
+						LayerStrategy:    TopLevelDirectoryLayers,
+					})
+					Expect(err).ToNot(HaveOccurred())
+				})
+
+				pull(ref, func(img containerreg.Image, tempDir string) {
+					layers, err := img.Layers()
+					Expect(err).ToNot(HaveOccurred())
+					Expect(len(layers)).To(Equal(3))
+
+					contentManifest, err := ReadContentManifest(img)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(contentManifest.LayerStrategy).To(Equal(TopLevelDirectoryLayers))
+					Expect(len(contentManifest.Layers)).To(Equal(3))
+					Expect(contentManifest.Layers[0].Name).To(Equal("."))
+					Expect(contentManifest.Layers[0].Paths).To(Equal([]string{"README.md"}))
+					Expect(contentManifest.Layers[1].Name).To(Equal("src"))
+					Expect(contentManifest.Layers[2].Name).To(Equal("vendor"))
+
+					digest, err := layers[2].Digest()
+					Expect(err).ToNot(HaveOccurred())
+					Expect(contentManifest.Layers[2].Digest).To(Equal(digest.String()))
+
+					Expect(filepath.Join(tempDir, "README.md")).To(BeAnExistingFile())
+					Expect(filepath.Join(tempDir, "src", "main.go")).To(BeAnExistingFile())
+					Expect(filepath.Join(tempDir, "vendor", "lib", "lib.go")).To(BeAnExistingFile())
+				})
+			})
+		})
+
+		It("should not upload unchanged layers again", func() {
+			withRegistry(func(host string) {
+				ref, err := name.ParseReference(host + "/test/bundle:latest")
+				Expect(err).ToNot(HaveOccurred())
+
+				withTempDir(func(source string) {
+					writeFiles(source, map[string]string{
+						"main.go":                 "package main",
+						"node_modules/a/index.js": "module.exports = {}",
+					})
+
+					packOptions := PackOptions{CompressionLevel: gzip.BestSpeed, LayerStrategy: DependencyLayers}
+
+					_, err := PackAndPushWithOptions(ref, source, packOptions)
+					Expect(err).ToNot(HaveOccurred())
+
+					// two layers and the configuration
+					Expect(uploads).To(Equal(3))
+
+					writeFiles(source, map[string]string{"main.go": "package main // changed"})
+
+					uploads = 0
+					_, err = PackAndPushWithOptions(ref, source, packOptions)
+					Expect(err).ToNot(HaveOccurred())
+
+					// the application layer and the configuration
+					Expect(uploads).To(Equal(2))
+				})
+
+				pull(ref, func(img containerreg.Image, tempDir string) {
+					contentManifest, err := ReadContentManifest(img)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(contentManifest.Layers[0].Name).To(Equal("dependencies"))
+					Expect(contentManifest.Layers[0].Paths).To(Equal([]string{"node_modules"}))
+					Expect(contentManifest.Layers[1].Name).To(Equal("application"))
+
+					data, err := ioutil.ReadFile(filepath.Join(tempDir, "main.go"))
+					Expect(err).ToNot(HaveOccurred())
+					Expect(string(data)).To(Equal("package main // changed"))
+					Expect(filepath.Join(tempDir, "node_modules", "a", "index.js")).To(BeAnExistingFile())
+				})
+			})
+		})
+
+		It("should fail in case of an unknown layer strategy", func() {
+			ref, err := name.ParseReference("registry.example.com/test/bundle:latest")
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = PackAndPushWithOptions(ref, "../../test/bundle", PackOptions{LayerStrategy: "Random"})
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should fail in case of an invalid compression level", func() {
+			ref, err := name.ParseReference("registry.example.com/test/bundle:latest")
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = PackAndPushWithCompressionLevel(ref, "../../test/bundle", 42)
+			Expect(err).To(HaveOccurred())
+		})
 	})
 })
@@ -0,0 +1,347 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package bundle
+
+import (
+	"bufio"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+)
+
+const (
+	gitIgnoreFilename    = ".gitignore"
+	dockerIgnoreFilename = ".dockerignore"
+)
+
+// IgnoreRule is a pattern of an ignore file
+type IgnoreRule struct {
+	// File is the path of the ignore file, relative to the packed directory
+	File string `json:"file"`
+
+	// Line is the line number of the pattern in the ignore file
+	Line int `json:"line"`
+
+	// Pattern is the pattern as it is written in the ignore file
+	Pattern string `json:"pattern"`
+}
+
+// DryRunEntry describes whether a file or directory is part of a bundle
+type DryRunEntry struct {
+	// Path of the file or directory, relative to the packed directory
+	Path string `json:"path"`
+
+	// IsDir is true for directories
+	IsDir bool `json:"isDir"`
+
+	// Included is true when the entry is part of the bundle, the content of
+	// excluded directories is not listed
+	Included bool `json:"included"`
+
+	// Rule is the ignore rule that decided about the entry, or nil when no
+	// rule matches and the entry is included
+	Rule *IgnoreRule `json:"rule,omitempty"`
+}
+
+// DryRun lists the files and directories that packing the directory with the
+// options includes and excludes, together with the deciding ignore rule
+func DryRun(directory string, packOptions PackOptions) ([]DryRunEntry, error) {
+	var entries []DryRunEntry
+	err := walk(directory, packOptions, func(path string, name string, d fs.DirEntry, excluded bool, rule *IgnoreRule) error {
+		if name != "." {
+			entries = append(entries, DryRunEntry{
+				Path:     name,
+				IsDir:    d.IsDir(),
+				Included: !excluded,
+				Rule:     rule,
+			})
+		}
+
+		return nil
+	})
+
+	return entries, err
+}
+
+// walkFunc is called for every file and directory with its name relative to
+// the walked directory, and the decision of the ignore rules about it
+type walkFunc func(path string, name string, d fs.DirEntry, excluded bool, rule *IgnoreRule) error
+
+// walk walks the directory in lexical order, and does not descend into
+// excluded directories, unless a rule of the .dockerignore file re-includes
+// content of excluded directories
+func walk(directory string, packOptions PackOptions, fn walkFunc) error {
+	matcher, err := newIgnoreMatcher(directory, packOptions)
+	if err != nil {
+		return err
+	}
+
+	return filepath.WalkDir(directory, func(path string, d fs.DirEntry, err error) error {
+		// Bail out on path errors
+		if err != nil {
+			return err
+		}
+
+		name, err := filepath.Rel(directory, path)
+		if err != nil {
+			return err
+		}
+
+		var excluded bool
+		var rule *IgnoreRule
+		if name != "." {
+			excluded, rule = matcher.match(name, d.IsDir())
+		}
+
+		if err := fn(path, name, d, excluded, rule); err != nil {
+			return err
+		}
+
+		if !d.IsDir() || name == "." {
+			return nil
+		}
+
+		if excluded && matcher.skipContent(rule) {
+			return filepath.SkipDir
+		}
+
+		// the .gitignore files of excluded directories still apply to the
+		// content that the .dockerignore file re-includes
+		return matcher.loadGitIgnore(name)
+	})
+}
+
+// ignoreMatcher decides about files with the rules of the .shpignore file,
+// the .gitignore files on every directory level, and optionally the
+// .dockerignore file. The .shpignore file takes precedence over the
+// .gitignore files, which take precedence over the .dockerignore file.
+type ignoreMatcher struct {
+	directory string
+
+	shpIgnore    []ignoreRule
+	gitIgnore    []ignoreRule
+	dockerIgnore []ignoreRule
+
+	// reincludes is true when the .dockerignore file contains negations,
+	// which can re-include content of excluded directories
+	reincludes bool
+}
+
+type ignoreRule struct {
+	IgnoreRule
+	match func(path []string, isDir bool) gitignore.MatchResult
+}
+
+func newIgnoreMatcher(directory string, packOptions PackOptions) (*ignoreMatcher, error) {
+	m := &ignoreMatcher{directory: directory}
+
+	var err error
+	if m.shpIgnore, err = readGitIgnoreRules(directory, shpIgnoreFilename); err != nil {
+		return nil, err
+	}
+
+	if err := m.loadGitIgnore("."); err != nil {
+		return nil, err
+	}
+
+	if packOptions.DockerIgnore {
+		if m.dockerIgnore, err = readDockerIgnoreRules(directory); err != nil {
+			return nil, err
+		}
+
+		for _, rule := range m.dockerIgnore {
+			if strings.HasPrefix(rule.Pattern, "!") {
+				m.reincludes = true
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// loadGitIgnore adds the rules of the .gitignore file in the directory, which
+// is relative to the packed directory
+func (m *ignoreMatcher) loadGitIgnore(dir string) error {
+	rules, err := readGitIgnoreRules(m.directory, filepath.Join(dir, gitIgnoreFilename))
+	if err != nil {
+		return err
+	}
+
+	// the files are loaded from the top to the bottom, so that the rules of
+	// deeper .gitignore files come later and take precedence
+	m.gitIgnore = append(m.gitIgnore, rules...)
+	return nil
+}
+
+// match returns whether the path, which is relative to the packed
+// directory, is excluded, and the rule that decided it
+func (m *ignoreMatcher) match(name string, isDir bool) (bool, *IgnoreRule) {
+	path := split(name)
+	for _, rules := range [][]ignoreRule{m.shpIgnore, m.gitIgnore, m.dockerIgnore} {
+		// the last matching rule wins
+		for i := len(rules) - 1; i >= 0; i-- {
+			switch rules[i].match(path, isDir) {
+			case gitignore.Exclude:
+				return true, &rules[i].IgnoreRule
+
+			case gitignore.Include:
+				return false, &rules[i].IgnoreRule
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// skipContent returns whether the content of a directory, which the rule
+// excludes, is excluded as well. Like git, nothing can re-include content of
+// a directory that the .shpignore or a .gitignore file excludes.
+func (m *ignoreMatcher) skipContent(rule *IgnoreRule) bool {
+	return !m.reincludes || rule.File != dockerIgnoreFilename
+}
+
+// readGitIgnoreRules reads an ignore file with the gitignore syntax, the
+// patterns apply to the directory of the file
+func readGitIgnoreRules(directory string, file string) ([]ignoreRule, error) {
+	var domain []string
+	if dir := filepath.Dir(file); dir != "." {
+		domain = split(dir)
+	}
+
+	return readIgnoreFile(directory, file, func(line string) func([]string, bool) gitignore.MatchResult {
+		if strings.HasPrefix(line, "#") {
+			return nil
+		}
+
+		if line = strings.TrimRight(line, " "); line == "" {
+			return nil
+		}
+
+		return gitignore.ParsePattern(line, domain).Match
+	})
+}
+
+// readDockerIgnoreRules reads the .dockerignore file, its patterns are
+// relative to the packed directory, and a pattern that matches a directory
+// also matches its content
+func readDockerIgnoreRules(directory string) ([]ignoreRule, error) {
+	return readIgnoreFile(directory, dockerIgnoreFilename, func(line string) func([]string, bool) gitignore.MatchResult {
+		if strings.HasPrefix(line, "#") {
+			return nil
+		}
+
+		result := gitignore.Exclude
+		pattern := strings.TrimSpace(line)
+		if strings.HasPrefix(pattern, "!") {
+			result = gitignore.Include
+			pattern = strings.TrimSpace(pattern[1:])
+		}
+
+		pattern = strings.TrimPrefix(filepath.ToSlash(filepath.Clean(pattern)), "/")
+		if pattern == "" || pattern == "." {
+			return nil
+		}
+
+		expression, err := regexp.Compile(dockerPatternExpression(pattern))
+		if err != nil {
+			// an invalid pattern cannot match anything
+			return nil
+		}
+
+		return func(path []string, isDir bool) gitignore.MatchResult {
+			for i := len(path); i > 0; i-- {
+				if expression.MatchString(strings.Join(path[:i], "/")) {
+					return result
+				}
+			}
+
+			return gitignore.NoMatch
+		}
+	})
+}
+
+func readIgnoreFile(directory string, file string, parse func(line string) func([]string, bool) gitignore.MatchResult) ([]ignoreRule, error) {
+	f, err := os.Open(filepath.Join(directory, file))
+	switch {
+	case os.IsNotExist(err):
+		return nil, nil
+
+	case err != nil:
+		return nil, err
+	}
+	defer f.Close()
+
+	var rules []ignoreRule
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimLeft(scanner.Text(), " \t")
+		if match := parse(text); match != nil {
+			rules = append(rules, ignoreRule{
+				IgnoreRule: IgnoreRule{File: file, Line: line, Pattern: strings.TrimSpace(text)},
+				match:      match,
+			})
+		}
+	}
+
+	return rules, scanner.Err()
+}
+
+// dockerPatternExpression translates a pattern of a .dockerignore file into a
+// regular expression, where ** matches any number of directories
+func dockerPatternExpression(pattern string) string {
+	var sb strings.Builder
+	sb.WriteString("^")
+
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					i++
+					sb.WriteString("(.*/)?")
+				} else {
+					sb.WriteString(".*")
+				}
+			} else {
+				sb.WriteString("[^/]*")
+			}
+
+		case '?':
+			sb.WriteString("[^/]")
+
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				sb.WriteString(regexp.QuoteMeta(string(pattern[i])))
+			}
+
+		case '[':
+			end := strings.IndexByte(pattern[i:], ']')
+			if end < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+
+			class := pattern[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+
+			sb.WriteString("[" + class + "]")
+			i += end
+
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	sb.WriteString("$")
+	return sb.String()
+}
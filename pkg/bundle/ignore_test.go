@@ -0,0 +1,191 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package bundle_test
+
+import (
+	"archive/tar"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+
+	. "github.com/shipwright-io/build/pkg/bundle"
+)
+
+var _ = Describe("Ignore files", func() {
+	var directory string
+
+	var writeFiles = func(files map[string]string) {
+		for path, content := range files {
+			Expect(os.MkdirAll(filepath.Join(directory, filepath.Dir(path)), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(directory, path), []byte(content), 0644)).To(Succeed())
+		}
+	}
+
+	var dryRun = func(packOptions PackOptions) map[string]DryRunEntry {
+		entries, err := DryRun(directory, packOptions)
+		Expect(err).ToNot(HaveOccurred())
+
+		result := map[string]DryRunEntry{}
+		for _, entry := range entries {
+			result[entry.Path] = entry
+		}
+
+		return result
+	}
+
+	var packedFiles = func() []string {
+		rc, err := Pack(directory)
+		Expect(err).ToNot(HaveOccurred())
+		defer rc.Close()
+
+		var files []string
+		tr := tar.NewReader(rc)
+		for {
+			header, err := tr.Next()
+			if err == io.EOF {
+				return files
+			}
+
+			Expect(err).ToNot(HaveOccurred())
+			if header.Typeflag == tar.TypeReg {
+				files = append(files, header.Name)
+			}
+		}
+	}
+
+	BeforeEach(func() {
+		var err error
+		directory, err = ioutil.TempDir("", "ignore")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(directory)).To(Succeed())
+	})
+
+	Context(".gitignore files", func() {
+		It("should honour .gitignore files on every directory level", func() {
+			writeFiles(map[string]string{
+				".gitignore":              "# logs\n*.log\n!keep.log\n",
+				"app.log":                 "",
+				"keep.log":                "",
+				"main.go":                 "",
+				"sub/.gitignore":          "generated/\n",
+				"sub/b.log":               "",
+				"sub/main.go":             "",
+				"sub/generated/output.go": "",
+				"other/generated/main.go": "",
+			})
+
+			Expect(packedFiles()).To(ConsistOf(
+				".gitignore",
+				"keep.log",
+				"main.go",
+				"sub/.gitignore",
+				"sub/main.go",
+				"other/generated/main.go",
+			))
+		})
+
+		It("should not re-include files of excluded directories", func() {
+			writeFiles(map[string]string{
+				".gitignore":     "build/\n!build/keep.txt\n",
+				"build/keep.txt": "",
+				"main.go":        "",
+			})
+
+			Expect(packedFiles()).To(ConsistOf(".gitignore", "main.go"))
+		})
+
+		It("should let the .shpignore file take precedence over .gitignore files", func() {
+			writeFiles(map[string]string{
+				".gitignore":    "*.log\n",
+				".shpignore":    "!important.log\n.gitignore\n",
+				"important.log": "",
+				"other.log":     "",
+			})
+
+			Expect(packedFiles()).To(ConsistOf(".shpignore", "important.log"))
+		})
+	})
+
+	Context(".dockerignore file", func() {
+		BeforeEach(func() {
+			writeFiles(map[string]string{
+				".dockerignore":       "docs\n!docs/README.md\n**/*.tmp\n",
+				"docs/README.md":      "",
+				"docs/guide.md":       "",
+				"src/main.go":         "",
+				"src/cache/state.tmp": "",
+			})
+		})
+
+		It("should ignore the .dockerignore file by default", func() {
+			entries := dryRun(PackOptions{})
+			Expect(entries["docs/guide.md"].Included).To(BeTrue())
+			Expect(entries["src/cache/state.tmp"].Included).To(BeTrue())
+		})
+
+		It("should re-include files of excluded directories", func() {
+			entries := dryRun(PackOptions{DockerIgnore: true})
+
+			Expect(entries["docs"].Included).To(BeFalse())
+			Expect(entries["docs/guide.md"].Included).To(BeFalse())
+			Expect(entries["docs/README.md"].Included).To(BeTrue())
+			Expect(entries["docs/README.md"].Rule).To(Equal(&IgnoreRule{File: ".dockerignore", Line: 2, Pattern: "!docs/README.md"}))
+			Expect(entries["src/main.go"].Included).To(BeTrue())
+			Expect(entries["src/main.go"].Rule).To(BeNil())
+			Expect(entries["src/cache/state.tmp"].Included).To(BeFalse())
+			Expect(entries["src/cache/state.tmp"].Rule).To(Equal(&IgnoreRule{File: ".dockerignore", Line: 3, Pattern: "**/*.tmp"}))
+		})
+
+		It("should push the re-included files of excluded directories", func() {
+			server := httptest.NewServer(registry.New(registry.Logger(log.New(ioutil.Discard, "", 0))))
+			defer server.Close()
+
+			ref, err := name.ParseReference(strings.TrimPrefix(server.URL, "http://") + "/test/bundle:latest")
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = PackAndPushWithOptions(ref, directory, PackOptions{DockerIgnore: true})
+			Expect(err).ToNot(HaveOccurred())
+
+			target, err := ioutil.TempDir("", "ignore-target")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.RemoveAll(target)
+
+			_, err = PullAndUnpack(ref, target)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(filepath.Join(target, "docs", "README.md")).To(BeAnExistingFile())
+			Expect(filepath.Join(target, "docs", "guide.md")).ToNot(BeAnExistingFile())
+			Expect(filepath.Join(target, "src", "cache", "state.tmp")).ToNot(BeAnExistingFile())
+			Expect(filepath.Join(target, "src", "main.go")).To(BeAnExistingFile())
+		})
+	})
+
+	Context("dry run", func() {
+		It("should list the deciding rule of excluded files", func() {
+			writeFiles(map[string]string{
+				"sub/.gitignore": "# comment\n\n*.bin\n",
+				"sub/app.bin":    "",
+			})
+
+			entries := dryRun(PackOptions{})
+			Expect(entries["sub"].Included).To(BeTrue())
+			Expect(entries["sub/app.bin"].Included).To(BeFalse())
+			Expect(entries["sub/app.bin"].Rule).To(Equal(&IgnoreRule{File: "sub/.gitignore", Line: 3, Pattern: "*.bin"}))
+		})
+	})
+})
@@ -0,0 +1,198 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package bundle
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	containerreg "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// ContentManifestAnnotation is the image annotation that contains the content
+// manifest of a bundle image
+const ContentManifestAnnotation = "build.shipwright.io/bundle-content"
+
+// LayerStrategy defines how the content of a directory is split into the
+// layers of a bundle image
+type LayerStrategy string
+
+const (
+	// SingleLayer packs the complete directory into one layer
+	SingleLayer LayerStrategy = "Single"
+
+	// TopLevelDirectoryLayers packs every top-level directory into a separate
+	// layer, and all top-level files into one layer in front of them
+	TopLevelDirectoryLayers LayerStrategy = "TopLevelDirectories"
+
+	// DependencyLayers packs the well-known directories of vendored
+	// dependencies into one layer, and the application code into another
+	DependencyLayers LayerStrategy = "Dependencies"
+)
+
+// dependencyDirectories are the top-level directories that contain vendored
+// dependencies, which usually change less often than the application code
+var dependencyDirectories = map[string]struct{}{
+	".venv":        {},
+	"node_modules": {},
+	"third_party":  {},
+	"vendor":       {},
+}
+
+// ContentManifest describes which content of the directory is contained in
+// which layer of a bundle image
+type ContentManifest struct {
+	LayerStrategy LayerStrategy  `json:"layerStrategy"`
+	Layers        []ContentLayer `json:"layers"`
+}
+
+// ContentLayer describes the content of one layer of a bundle image
+type ContentLayer struct {
+	// Name of the layer, for example the name of the top-level directory
+	Name string `json:"name"`
+
+	// Digest of the compressed layer
+	Digest string `json:"digest"`
+
+	// Paths are the top-level files and directories in the layer
+	Paths []string `json:"paths"`
+}
+
+// ReadContentManifest returns the content manifest of a bundle image, or nil
+// in case the image does not contain one
+func ReadContentManifest(image containerreg.Image) (*ContentManifest, error) {
+	manifest, err := image.Manifest()
+	if err != nil {
+		return nil, err
+	}
+
+	value, ok := manifest.Annotations[ContentManifestAnnotation]
+	if !ok {
+		return nil, nil
+	}
+
+	var contentManifest ContentManifest
+	if err := json.Unmarshal([]byte(value), &contentManifest); err != nil {
+		return nil, fmt.Errorf("failed to parse the content manifest: %w", err)
+	}
+
+	return &contentManifest, nil
+}
+
+// layerDefinition is a layer of a bundle image, which contains the top-level
+// files and directories for which include returns true
+type layerDefinition struct {
+	name    string
+	paths   []string
+	include func(topLevel string) bool
+}
+
+// layerDefinitions splits the top-level files and directories of a directory
+// into layers, the result is the same for the same directory content
+func layerDefinitions(directory string, packOptions PackOptions) ([]layerDefinition, error) {
+	matcher, err := newIgnoreMatcher(directory, packOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	// the entries are sorted by name, which makes the layers deterministic
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		return nil, err
+	}
+
+	var files, directories []string
+	for _, entry := range entries {
+		if excluded, rule := matcher.match(entry.Name(), entry.IsDir()); excluded && (!entry.IsDir() || matcher.skipContent(rule)) {
+			continue
+		}
+
+		if entry.IsDir() {
+			directories = append(directories, entry.Name())
+		} else {
+			files = append(files, entry.Name())
+		}
+	}
+
+	var contains = func(names []string) func(string) bool {
+		set := make(map[string]struct{}, len(names))
+		for _, name := range names {
+			set[name] = struct{}{}
+		}
+
+		return func(topLevel string) bool {
+			_, ok := set[topLevel]
+			return ok
+		}
+	}
+
+	switch packOptions.LayerStrategy {
+	case "", SingleLayer:
+		return []layerDefinition{{
+			name:    ".",
+			paths:   concat(files, directories),
+			include: func(string) bool { return true },
+		}}, nil
+
+	case TopLevelDirectoryLayers:
+		includeFiles := contains(files)
+		definitions := []layerDefinition{{
+			name:  ".",
+			paths: files,
+			include: func(topLevel string) bool {
+				return topLevel == "." || includeFiles(topLevel)
+			},
+		}}
+
+		for _, dir := range directories {
+			definitions = append(definitions, layerDefinition{
+				name:    dir,
+				paths:   []string{dir},
+				include: contains([]string{dir}),
+			})
+		}
+
+		return definitions, nil
+
+	case DependencyLayers:
+		var dependencies, application []string
+		for _, dir := range directories {
+			if _, ok := dependencyDirectories[dir]; ok {
+				dependencies = append(dependencies, dir)
+			} else {
+				application = append(application, dir)
+			}
+		}
+
+		var definitions []layerDefinition
+		if len(dependencies) > 0 {
+			definitions = append(definitions, layerDefinition{
+				name:    "dependencies",
+				paths:   dependencies,
+				include: contains(dependencies),
+			})
+		}
+
+		application = concat(files, application)
+		includeApplication := contains(application)
+		return append(definitions, layerDefinition{
+			name:  "application",
+			paths: application,
+			include: func(topLevel string) bool {
+				return topLevel == "." || includeApplication(topLevel)
+			},
+		}), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported layer strategy %q", packOptions.LayerStrategy)
+	}
+}
+
+func concat(a []string, b []string) []string {
+	result := make([]string, 0, len(a)+len(b))
+	result = append(result, a...)
+	return append(result, b...)
+}
@@ -0,0 +1,80 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package bundle
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+)
+
+// ErrDeletionUnsupported is returned when the registry does not support the
+// deletion of the bundle image
+var ErrDeletionUnsupported = errors.New("registry does not support the deletion of images")
+
+// Prune deletes a bundle image from the registry. In case the reference is a
+// tag, the tag is deleted first, because not all registries delete the tags
+// of a manifest together with it. Afterwards, the manifest of the digest is
+// deleted. Content that is already gone counts as deleted.
+func Prune(ref name.Reference, digest name.Digest, options ...remote.Option) error {
+	refs := []name.Reference{digest}
+	if tag, ok := ref.(name.Tag); ok {
+		refs = []name.Reference{tag, digest}
+	}
+
+	var deleted, unsupported bool
+	for _, target := range refs {
+		switch err := deleteManifest(target, options...); {
+		case errors.Is(err, ErrDeletionUnsupported):
+			unsupported = true
+
+		case err != nil:
+			return err
+
+		default:
+			deleted = true
+		}
+	}
+
+	// in case only the tag could be deleted, the image is not reachable
+	// through it anymore, even if the registry keeps the manifest
+	if unsupported && !deleted {
+		return fmt.Errorf("%w: %s", ErrDeletionUnsupported, ref)
+	}
+
+	return nil
+}
+
+func deleteManifest(ref name.Reference, options ...remote.Option) error {
+	err := remote.Delete(ref, options...)
+	if err == nil {
+		return nil
+	}
+
+	var transportErr *transport.Error
+	if !errors.As(err, &transportErr) {
+		return err
+	}
+
+	switch {
+	case transportErr.StatusCode == http.StatusNotFound:
+		return nil
+
+	case transportErr.StatusCode == http.StatusMethodNotAllowed:
+		return ErrDeletionUnsupported
+	}
+
+	for _, diagnostic := range transportErr.Errors {
+		if diagnostic.Code == transport.UnsupportedErrorCode {
+			return ErrDeletionUnsupported
+		}
+	}
+
+	return err
+}
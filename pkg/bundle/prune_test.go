@@ -0,0 +1,86 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package bundle_test
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+
+	. "github.com/shipwright-io/build/pkg/bundle"
+)
+
+var _ = Describe("Prune", func() {
+	var withRegistry = func(deletion bool, f func(host string)) {
+		handler := registry.New(registry.Logger(log.New(ioutil.Discard, "", 0)))
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodDelete && !deletion {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				_, _ = w.Write([]byte(`{"errors":[{"code":"UNSUPPORTED","message":"The operation is unsupported."}]}`))
+				return
+			}
+
+			handler.ServeHTTP(w, r)
+		}))
+		defer server.Close()
+
+		f(strings.TrimPrefix(server.URL, "http://"))
+	}
+
+	var exists = func(ref name.Reference) bool {
+		_, err := remote.Head(ref)
+		return err == nil
+	}
+
+	It("should delete the tag and the manifest of a bundle image", func() {
+		withRegistry(true, func(host string) {
+			ref, err := name.ParseReference(host + "/test/bundle:latest")
+			Expect(err).ToNot(HaveOccurred())
+
+			digest, err := PackAndPush(ref, "../../test/bundle")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(Prune(ref, digest)).To(Succeed())
+			Expect(exists(ref)).To(BeFalse())
+			Expect(exists(digest)).To(BeFalse())
+		})
+	})
+
+	It("should succeed in case the image is already deleted", func() {
+		withRegistry(true, func(host string) {
+			ref, err := name.ParseReference(host + "/test/bundle:latest")
+			Expect(err).ToNot(HaveOccurred())
+
+			digest, err := PackAndPush(ref, "../../test/bundle")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(Prune(ref, digest)).To(Succeed())
+			Expect(Prune(ref, digest)).To(Succeed())
+		})
+	})
+
+	It("should report registries that do not support the deletion", func() {
+		withRegistry(false, func(host string) {
+			ref, err := name.ParseReference(host + "/test/bundle:latest")
+			Expect(err).ToNot(HaveOccurred())
+
+			digest, err := PackAndPush(ref, "../../test/bundle")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(Prune(ref, digest)).To(MatchError(ErrDeletionUnsupported))
+			Expect(exists(ref)).To(BeTrue())
+		})
+	})
+})
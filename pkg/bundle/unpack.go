@@ -0,0 +1,405 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package bundle
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	whiteoutPrefix = ".wh."
+	whiteoutOpaque = whiteoutPrefix + whiteoutPrefix + ".opq"
+)
+
+// UnpackOptions defines how the content of a tar stream is written into the
+// local file system
+type UnpackOptions struct {
+	// PreserveSymlinks creates symbolic links that point to a location inside
+	// of the target directory. Without it, symbolic links are rejected.
+	PreserveSymlinks bool
+
+	// PreserveOwnership sets the user and group of the tar entries on the
+	// created files, which usually requires to run as root
+	PreserveOwnership bool
+
+	// Umask contains the permission bits that are removed from the mode of
+	// the tar entries
+	Umask os.FileMode
+}
+
+// Unpack reads a tar stream and writes the content into the local file system
+// with all files and directories.
+func Unpack(in io.Reader, targetPath string) error {
+	return UnpackWithOptions(in, targetPath, UnpackOptions{})
+}
+
+// UnpackWithOptions reads a tar stream and writes the content into the local
+// file system. Entries that would end up outside of the target directory are
+// rejected, and whiteout entries remove content that was unpacked before.
+func UnpackWithOptions(in io.Reader, targetPath string, options UnpackOptions) error {
+	if err := os.MkdirAll(targetPath, os.FileMode(0755)); err != nil {
+		return err
+	}
+
+	realTarget, err := filepath.EvalSymlinks(targetPath)
+	if err != nil {
+		return err
+	}
+
+	u := &unpacker{
+		target:      filepath.Clean(targetPath),
+		realTarget:  realTarget,
+		options:     options,
+		directories: map[string]*tar.Header{},
+	}
+
+	if err := u.unpack(tar.NewReader(in)); err != nil {
+		return err
+	}
+
+	return u.finish()
+}
+
+type unpacker struct {
+	target      string
+	realTarget  string
+	options     UnpackOptions
+	directories map[string]*tar.Header
+	symlinks    []string
+}
+
+func (u *unpacker) unpack(tr *tar.Reader) error {
+	for {
+		header, err := tr.Next()
+		switch {
+		case err == io.EOF:
+			return nil
+
+		case err != nil:
+			return err
+
+		case header == nil:
+			continue
+		}
+
+		if header.Typeflag == tar.TypeXGlobalHeader {
+			continue
+		}
+
+		path, err := u.join(header.Name)
+		if err != nil {
+			return err
+		}
+
+		if path == u.target {
+			// the root of the tar stream is the target directory itself
+			if header.Typeflag == tar.TypeDir {
+				u.directories[path] = header
+			}
+			continue
+		}
+
+		if err := u.ensureParent(path); err != nil {
+			return err
+		}
+
+		if base := filepath.Base(path); strings.HasPrefix(base, whiteoutPrefix) {
+			if err := u.whiteout(path, base); err != nil {
+				return err
+			}
+			continue
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := u.mkdir(path); err != nil {
+				return err
+			}
+
+			// permissions of directories are applied at the end, so that
+			// read-only directories can still be filled
+			u.directories[path] = header
+
+		case tar.TypeReg:
+			if err := u.writeFile(path, header, tr); err != nil {
+				return err
+			}
+
+		case tar.TypeSymlink:
+			if err := u.symlink(path, header); err != nil {
+				return err
+			}
+
+		case tar.TypeLink:
+			if err := u.link(path, header); err != nil {
+				return err
+			}
+
+		default:
+			return fmt.Errorf("provided tarball contains unsupported file type of %s, only directories, regular files, and links are supported", header.Name)
+		}
+	}
+}
+
+// join returns the path of a tar entry inside of the target directory, and
+// rejects entries that point outside of it
+func (u *unpacker) join(name string) (string, error) {
+	if filepath.IsAbs(name) {
+		return "", fmt.Errorf("provided tarball contains the absolute path %s", name)
+	}
+
+	path := filepath.Join(u.target, name)
+	if !isWithin(u.target, path) {
+		return "", fmt.Errorf("provided tarball contains the path %s outside of the target directory", name)
+	}
+
+	return path, nil
+}
+
+// ensureParent verifies that the existing part of the parent directory does
+// not leave the target directory through symbolic links, and creates the rest
+func (u *unpacker) ensureParent(path string) error {
+	dir := filepath.Dir(path)
+	for existing := dir; ; existing = filepath.Dir(existing) {
+		real, err := filepath.EvalSymlinks(existing)
+		if os.IsNotExist(err) && existing != u.target {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if !isWithin(u.realTarget, real) {
+			return fmt.Errorf("provided tarball contains the path %s, which leads outside of the target directory", path)
+		}
+
+		break
+	}
+
+	return os.MkdirAll(dir, os.FileMode(0755))
+}
+
+func (u *unpacker) mkdir(path string) error {
+	if info, err := os.Lstat(path); err == nil {
+		if info.IsDir() {
+			return nil
+		}
+
+		if err := os.RemoveAll(path); err != nil {
+			return err
+		}
+	}
+
+	return os.Mkdir(path, os.FileMode(0755))
+}
+
+func (u *unpacker) writeFile(path string, header *tar.Header, in io.Reader) error {
+	// never write through an existing symbolic link or into a directory
+	if err := u.removeExisting(path); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(0600))
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(file, in); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	if err := u.applyMetadata(path, header); err != nil {
+		return err
+	}
+
+	return os.Chtimes(path, header.AccessTime, header.ModTime)
+}
+
+func (u *unpacker) symlink(path string, header *tar.Header) error {
+	if !u.options.PreserveSymlinks {
+		return fmt.Errorf("provided tarball contains the symbolic link %s, which is not supported", header.Name)
+	}
+
+	if filepath.IsAbs(header.Linkname) {
+		return fmt.Errorf("provided tarball contains the symbolic link %s with the absolute target %s", header.Name, header.Linkname)
+	}
+
+	destination := filepath.Join(filepath.Dir(path), header.Linkname)
+	if !isWithin(u.target, destination) {
+		return fmt.Errorf("provided tarball contains the symbolic link %s with the target %s outside of the target directory", header.Name, header.Linkname)
+	}
+
+	if err := u.removeExisting(path); err != nil {
+		return err
+	}
+
+	if err := os.Symlink(header.Linkname, path); err != nil {
+		return err
+	}
+
+	// the destination of the link is verified at the end again, because it
+	// can be resolved differently once all entries exist
+	u.symlinks = append(u.symlinks, path)
+
+	if u.options.PreserveOwnership {
+		return os.Lchown(path, header.Uid, header.Gid)
+	}
+
+	return nil
+}
+
+func (u *unpacker) link(path string, header *tar.Header) error {
+	source, err := u.join(header.Linkname)
+	if err != nil {
+		return err
+	}
+
+	real, err := filepath.EvalSymlinks(source)
+	if err != nil {
+		return fmt.Errorf("provided tarball contains the hard link %s to %s, which does not exist: %w", header.Name, header.Linkname, err)
+	}
+
+	if !isWithin(u.realTarget, real) {
+		return fmt.Errorf("provided tarball contains the hard link %s to %s outside of the target directory", header.Name, header.Linkname)
+	}
+
+	info, err := os.Stat(real)
+	if err != nil {
+		return err
+	}
+
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("provided tarball contains the hard link %s to %s, which is not a regular file", header.Name, header.Linkname)
+	}
+
+	if real == path {
+		return nil
+	}
+
+	if err := u.removeExisting(path); err != nil {
+		return err
+	}
+
+	return os.Link(real, path)
+}
+
+// whiteout removes the content that the whiteout entry marks as deleted
+func (u *unpacker) whiteout(path string, base string) error {
+	dir := filepath.Dir(path)
+
+	if base == whiteoutOpaque {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			if err := os.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	name := strings.TrimPrefix(base, whiteoutPrefix)
+	if name == "" || name == "." || name == ".." {
+		return fmt.Errorf("provided tarball contains the invalid whiteout %s", path)
+	}
+
+	deleted := filepath.Join(dir, name)
+	delete(u.directories, deleted)
+	return os.RemoveAll(deleted)
+}
+
+func (u *unpacker) removeExisting(path string) error {
+	info, err := os.Lstat(path)
+	switch {
+	case os.IsNotExist(err):
+		return nil
+
+	case err != nil:
+		return err
+
+	case info.Mode().IsRegular():
+		// hard links share the content, therefore the file is replaced
+		// instead of truncated
+		return os.Remove(path)
+
+	default:
+		return os.RemoveAll(path)
+	}
+}
+
+func (u *unpacker) applyMetadata(path string, header *tar.Header) error {
+	if u.options.PreserveOwnership {
+		if err := os.Lchown(path, header.Uid, header.Gid); err != nil {
+			return err
+		}
+	}
+
+	return os.Chmod(path, u.mode(header))
+}
+
+func (u *unpacker) mode(header *tar.Header) os.FileMode {
+	mode := header.FileInfo().Mode()
+	return (mode.Perm() | mode&(os.ModeSetuid|os.ModeSetgid|os.ModeSticky)) &^ u.options.Umask
+}
+
+// finish verifies the symbolic links and applies the directory permissions,
+// starting with the deepest directories
+func (u *unpacker) finish() error {
+	for _, path := range u.symlinks {
+		real, err := filepath.EvalSymlinks(path)
+		switch {
+		case os.IsNotExist(err):
+			continue
+
+		case err != nil:
+			return err
+
+		case !isWithin(u.realTarget, real):
+			os.Remove(path)
+			return fmt.Errorf("provided tarball contains the symbolic link %s, which leads outside of the target directory", path)
+		}
+	}
+
+	paths := make([]string, 0, len(u.directories))
+	for path := range u.directories {
+		paths = append(paths, path)
+	}
+
+	sort.Sort(sort.Reverse(sort.StringSlice(paths)))
+
+	for _, path := range paths {
+		header := u.directories[path]
+		if err := u.applyMetadata(path, header); err != nil {
+			return err
+		}
+
+		if err := os.Chtimes(path, header.AccessTime, header.ModTime); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isWithin returns whether the path is the directory or located inside of it
+func isWithin(directory string, path string) bool {
+	return path == directory || strings.HasPrefix(path, directory+string(filepath.Separator))
+}
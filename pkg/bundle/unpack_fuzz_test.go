@@ -0,0 +1,79 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build go1.18
+// +build go1.18
+
+package bundle_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shipwright-io/build/pkg/bundle"
+)
+
+// FuzzUnpack verifies that no tar stream can create content outside of the
+// target directory, run it with: go test ./pkg/bundle -run=^$ -fuzz=FuzzUnpack
+// The seed corpus runs with every go test, fuzzing requires Go 1.18 or later,
+// the module itself still builds with Go 1.17.
+func FuzzUnpack(f *testing.F) {
+	for _, entries := range [][]tar.Header{
+		{{Name: "file.txt", Typeflag: tar.TypeReg, Mode: 0644}},
+		{{Name: "../evil.txt", Typeflag: tar.TypeReg, Mode: 0644}},
+		{{Name: "dir/", Typeflag: tar.TypeDir, Mode: 0755}, {Name: "dir/../../evil.txt", Typeflag: tar.TypeReg, Mode: 0644}},
+		{{Name: "link", Typeflag: tar.TypeSymlink, Linkname: ".."}, {Name: "link/evil.txt", Typeflag: tar.TypeReg, Mode: 0644}},
+		{{Name: "x", Typeflag: tar.TypeSymlink, Linkname: "y/.."}, {Name: "y", Typeflag: tar.TypeSymlink, Linkname: "."}, {Name: "x/evil.txt", Typeflag: tar.TypeReg, Mode: 0644}},
+		{{Name: "file.txt", Typeflag: tar.TypeReg, Mode: 0644}, {Name: "link", Typeflag: tar.TypeLink, Linkname: "file.txt"}},
+		{{Name: ".wh..", Typeflag: tar.TypeReg, Mode: 0644}},
+	} {
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		for i := range entries {
+			if err := tw.WriteHeader(&entries[i]); err != nil {
+				f.Fatal(err)
+			}
+		}
+		if err := tw.Close(); err != nil {
+			f.Fatal(err)
+		}
+
+		f.Add(buf.Bytes(), true)
+		f.Add(buf.Bytes(), false)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte, preserveSymlinks bool) {
+		parentDir, err := ioutil.TempDir("", "unpack-fuzz")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() {
+			_ = filepath.Walk(parentDir, func(path string, info os.FileInfo, err error) error {
+				if err == nil && info.IsDir() {
+					_ = os.Chmod(path, 0755)
+				}
+				return nil
+			})
+			os.RemoveAll(parentDir)
+		}()
+
+		targetDir := filepath.Join(parentDir, "target")
+		_ = bundle.UnpackWithOptions(bytes.NewReader(data), targetDir, bundle.UnpackOptions{PreserveSymlinks: preserveSymlinks})
+
+		entries, err := os.ReadDir(parentDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, entry := range entries {
+			if entry.Name() != "target" {
+				t.Fatalf("unpacking created %s outside of the target directory", entry.Name())
+			}
+		}
+	})
+}
@@ -0,0 +1,284 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package bundle_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	. "github.com/shipwright-io/build/pkg/bundle"
+)
+
+type entry struct {
+	name     string
+	typeflag byte
+	content  string
+	linkname string
+	mode     int64
+}
+
+func tarball(entries ...entry) *bytes.Buffer {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		mode := e.mode
+		if mode == 0 {
+			mode = 0644
+		}
+
+		Expect(tw.WriteHeader(&tar.Header{
+			Name:     e.name,
+			Typeflag: e.typeflag,
+			Linkname: e.linkname,
+			Mode:     mode,
+			Size:     int64(len(e.content)),
+		})).To(Succeed())
+
+		_, err := tw.Write([]byte(e.content))
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	Expect(tw.Close()).To(Succeed())
+	return &buf
+}
+
+var _ = Describe("Unpack", func() {
+	var (
+		parentDir string
+		targetDir string
+	)
+
+	var filecontent = func(path string) string {
+		data, err := ioutil.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		return string(data)
+	}
+
+	BeforeEach(func() {
+		var err error
+		parentDir, err = ioutil.TempDir("", "unpack")
+		Expect(err).ToNot(HaveOccurred())
+
+		targetDir = filepath.Join(parentDir, "target")
+	})
+
+	AfterEach(func() {
+		// make read-only directories removable again
+		_ = filepath.Walk(parentDir, func(path string, info os.FileInfo, err error) error {
+			if err == nil && info.IsDir() {
+				_ = os.Chmod(path, 0755)
+			}
+			return nil
+		})
+
+		Expect(os.RemoveAll(parentDir)).To(Succeed())
+	})
+
+	Context("path traversal", func() {
+		It("should reject entries with a relative path outside of the target directory", func() {
+			err := Unpack(tarball(entry{name: "../evil.txt", typeflag: tar.TypeReg, content: "evil"}), targetDir)
+			Expect(err).To(HaveOccurred())
+			Expect(filepath.Join(parentDir, "evil.txt")).ToNot(BeAnExistingFile())
+		})
+
+		It("should reject entries with an absolute path", func() {
+			err := Unpack(tarball(entry{name: "/tmp/evil.txt", typeflag: tar.TypeReg, content: "evil"}), targetDir)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should reject symbolic links by default", func() {
+			err := Unpack(tarball(entry{name: "link", typeflag: tar.TypeSymlink, linkname: "file"}), targetDir)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should reject symbolic links that point outside of the target directory", func() {
+			err := UnpackWithOptions(tarball(entry{name: "link", typeflag: tar.TypeSymlink, linkname: "../outside"}), targetDir, UnpackOptions{PreserveSymlinks: true})
+			Expect(err).To(HaveOccurred())
+			Expect(filepath.Join(targetDir, "link")).ToNot(BeAnExistingFile())
+		})
+
+		It("should reject writing through a chain of symbolic links that leads outside of the target directory", func() {
+			err := UnpackWithOptions(tarball(
+				entry{name: "x", typeflag: tar.TypeSymlink, linkname: "y/.."},
+				entry{name: "y", typeflag: tar.TypeSymlink, linkname: "."},
+				entry{name: "x/evil.txt", typeflag: tar.TypeReg, content: "evil"},
+			), targetDir, UnpackOptions{PreserveSymlinks: true})
+			Expect(err).To(HaveOccurred())
+			Expect(filepath.Join(parentDir, "evil.txt")).ToNot(BeAnExistingFile())
+		})
+
+		It("should reject a regular file that replaces an existing symbolic link to write outside", func() {
+			outside := filepath.Join(parentDir, "outside.txt")
+			Expect(ioutil.WriteFile(outside, []byte("original"), 0644)).To(Succeed())
+			Expect(os.MkdirAll(targetDir, 0755)).To(Succeed())
+			Expect(os.Symlink(outside, filepath.Join(targetDir, "file.txt"))).To(Succeed())
+
+			Expect(Unpack(tarball(entry{name: "file.txt", typeflag: tar.TypeReg, content: "new"}), targetDir)).To(Succeed())
+			Expect(filecontent(outside)).To(Equal("original"))
+			Expect(filecontent(filepath.Join(targetDir, "file.txt"))).To(Equal("new"))
+		})
+
+		It("should reject hard links to files outside of the target directory", func() {
+			err := Unpack(tarball(entry{name: "link", typeflag: tar.TypeLink, linkname: "../../../etc/passwd"}), targetDir)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("links", func() {
+		It("should preserve symbolic links inside of the target directory", func() {
+			Expect(UnpackWithOptions(tarball(
+				entry{name: "dir/", typeflag: tar.TypeDir, mode: 0755},
+				entry{name: "dir/file.txt", typeflag: tar.TypeReg, content: "content"},
+				entry{name: "link", typeflag: tar.TypeSymlink, linkname: "dir/file.txt"},
+			), targetDir, UnpackOptions{PreserveSymlinks: true})).To(Succeed())
+
+			destination, err := os.Readlink(filepath.Join(targetDir, "link"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(destination).To(Equal("dir/file.txt"))
+			Expect(filecontent(filepath.Join(targetDir, "link"))).To(Equal("content"))
+		})
+
+		It("should create hard links", func() {
+			Expect(Unpack(tarball(
+				entry{name: "file.txt", typeflag: tar.TypeReg, content: "content"},
+				entry{name: "link.txt", typeflag: tar.TypeLink, linkname: "file.txt"},
+			), targetDir)).To(Succeed())
+
+			file, err := os.Stat(filepath.Join(targetDir, "file.txt"))
+			Expect(err).ToNot(HaveOccurred())
+			link, err := os.Stat(filepath.Join(targetDir, "link.txt"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(os.SameFile(file, link)).To(BeTrue())
+		})
+	})
+
+	Context("whiteouts", func() {
+		It("should remove files and directories that were unpacked before", func() {
+			Expect(Unpack(tarball(
+				entry{name: "dir/file.txt", typeflag: tar.TypeReg, content: "content"},
+				entry{name: "other/file.txt", typeflag: tar.TypeReg, content: "content"},
+				entry{name: "keep.txt", typeflag: tar.TypeReg, content: "content"},
+			), targetDir)).To(Succeed())
+
+			Expect(Unpack(tarball(
+				entry{name: ".wh.dir", typeflag: tar.TypeReg},
+				entry{name: "other/.wh..wh..opq", typeflag: tar.TypeReg},
+			), targetDir)).To(Succeed())
+
+			Expect(filepath.Join(targetDir, "dir")).ToNot(BeADirectory())
+			Expect(filepath.Join(targetDir, "other")).To(BeADirectory())
+			Expect(filepath.Join(targetDir, "other", "file.txt")).ToNot(BeAnExistingFile())
+			Expect(filepath.Join(targetDir, "keep.txt")).To(BeAnExistingFile())
+			Expect(filepath.Join(targetDir, ".wh.dir")).ToNot(BeAnExistingFile())
+		})
+
+		It("should reject whiteouts that point outside of the target directory", func() {
+			err := Unpack(tarball(entry{name: ".wh..", typeflag: tar.TypeReg}), targetDir)
+			Expect(err).To(HaveOccurred())
+			Expect(targetDir).To(BeADirectory())
+		})
+	})
+
+	Context("files and permissions", func() {
+		It("should truncate existing files", func() {
+			Expect(os.MkdirAll(targetDir, 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(targetDir, "file.txt"), []byte("a much longer content"), 0644)).To(Succeed())
+
+			Expect(Unpack(tarball(entry{name: "file.txt", typeflag: tar.TypeReg, content: "short"}), targetDir)).To(Succeed())
+			Expect(filecontent(filepath.Join(targetDir, "file.txt"))).To(Equal("short"))
+		})
+
+		It("should apply the permissions of the entries and the umask", func() {
+			Expect(UnpackWithOptions(tarball(
+				entry{name: "script.sh", typeflag: tar.TypeReg, content: "#!/bin/sh", mode: 0777},
+			), targetDir, UnpackOptions{Umask: 0022})).To(Succeed())
+
+			info, err := os.Stat(filepath.Join(targetDir, "script.sh"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(0755)))
+		})
+
+		It("should fill read-only directories", func() {
+			Expect(Unpack(tarball(
+				entry{name: "readonly/", typeflag: tar.TypeDir, mode: 0555},
+				entry{name: "readonly/file.txt", typeflag: tar.TypeReg, content: "content"},
+			), targetDir)).To(Succeed())
+
+			info, err := os.Stat(filepath.Join(targetDir, "readonly"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(0555)))
+			Expect(filecontent(filepath.Join(targetDir, "readonly", "file.txt"))).To(Equal("content"))
+		})
+
+		It("should reject special files", func() {
+			err := Unpack(tarball(entry{name: "fifo", typeflag: tar.TypeFifo}), targetDir)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("hostile tar streams", func() {
+		// onlyTarget verifies that unpacking did neither create nor remove anything next to the
+		// target directory
+		var onlyTarget = func() {
+			entries, err := ioutil.ReadDir(parentDir)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(HaveLen(1))
+			Expect(entries[0].Name()).To(Equal("target"))
+		}
+
+		DescribeTable("never create content outside of the target directory",
+			func(entries ...entry) {
+				for _, preserveSymlinks := range []bool{false, true} {
+					_ = UnpackWithOptions(tarball(entries...), targetDir, UnpackOptions{PreserveSymlinks: preserveSymlinks})
+					onlyTarget()
+				}
+			},
+			Entry("relative path traversal",
+				entry{name: "../evil.txt", typeflag: tar.TypeReg, content: "evil"},
+			),
+			Entry("path traversal through a directory",
+				entry{name: "dir/", typeflag: tar.TypeDir, mode: 0755},
+				entry{name: "dir/../../evil.txt", typeflag: tar.TypeReg, content: "evil"},
+			),
+			Entry("absolute path",
+				entry{name: "/evil.txt", typeflag: tar.TypeReg, content: "evil"},
+			),
+			Entry("symbolic link to the parent directory",
+				entry{name: "link", typeflag: tar.TypeSymlink, linkname: ".."},
+				entry{name: "link/evil.txt", typeflag: tar.TypeReg, content: "evil"},
+			),
+			Entry("absolute symbolic link",
+				entry{name: "link", typeflag: tar.TypeSymlink, linkname: "/tmp"},
+				entry{name: "link/evil.txt", typeflag: tar.TypeReg, content: "evil"},
+			),
+			Entry("chain of symbolic links",
+				entry{name: "x", typeflag: tar.TypeSymlink, linkname: "y/.."},
+				entry{name: "y", typeflag: tar.TypeSymlink, linkname: "."},
+				entry{name: "x/evil.txt", typeflag: tar.TypeReg, content: "evil"},
+			),
+			Entry("hard link outside of the target directory",
+				entry{name: "link", typeflag: tar.TypeLink, linkname: "../evil.txt"},
+			),
+			Entry("hard link to a symbolic link that escapes",
+				entry{name: "link", typeflag: tar.TypeSymlink, linkname: ".."},
+				entry{name: "hard", typeflag: tar.TypeLink, linkname: "link"},
+				entry{name: "hard/evil.txt", typeflag: tar.TypeReg, content: "evil"},
+			),
+			Entry("whiteout of the parent directory",
+				entry{name: ".wh..", typeflag: tar.TypeReg},
+			),
+			Entry("whiteout outside of the target directory",
+				entry{name: "../.wh.target", typeflag: tar.TypeReg},
+			),
+		)
+	})
+})
@@ -0,0 +1,308 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package bundle
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	containerreg "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+const (
+	// signatureAnnotation is the layer annotation of a cosign signature
+	// image that contains the base64 encoded signature of the layer
+	signatureAnnotation = "dev.cosignproject.cosign/signature"
+
+	// signaturePayloadMediaType is the media type of the layers of a cosign
+	// signature image, which contain the signed payload
+	signaturePayloadMediaType types.MediaType = "application/vnd.dev.cosign.simplesigning.v1+json"
+
+	signaturePayloadType = "cosign container image signature"
+)
+
+var (
+	// ErrDigestMismatch is returned when the digest of a bundle image does
+	// not match the expected digest
+	ErrDigestMismatch = errors.New("bundle image digest mismatch")
+
+	// ErrSignatureNotFound is returned when no signature exists for a
+	// bundle image
+	ErrSignatureNotFound = errors.New("bundle image signature not found")
+
+	// ErrSignatureInvalid is returned when none of the signatures of a
+	// bundle image can be verified with the public key
+	ErrSignatureInvalid = errors.New("bundle image signature invalid")
+)
+
+// VerifyOptions define what is verified about a bundle image before it is
+// unpacked
+type VerifyOptions struct {
+	// Digest is the expected digest of the image in the format sha256:<hex>,
+	// it is not verified when empty
+	Digest string
+
+	// PublicKey is the key that the image signature is verified with, the
+	// signature is not verified when nil
+	PublicKey crypto.PublicKey
+}
+
+// Verification is the outcome of a successful verification of a bundle image
+type Verification struct {
+	// Reference is the digest reference of the verified image, which should
+	// be used to pull the image, so that it cannot change in between
+	Reference name.Digest
+
+	// DigestVerified is true when the digest matched the expected digest
+	DigestVerified bool
+
+	// SignatureVerified is true when a signature was verified with the
+	// public key
+	SignatureVerified bool
+}
+
+// signaturePayload is the simple signing payload that cosign signs
+type signaturePayload struct {
+	Critical struct {
+		Identity struct {
+			DockerReference string `json:"docker-reference"`
+		} `json:"identity"`
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+		Type string `json:"type"`
+	} `json:"critical"`
+	Optional map[string]string `json:"optional"`
+}
+
+// LoadPublicKey parses a PEM encoded ECDSA, RSA, or Ed25519 public key
+func LoadPublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("failed to decode the PEM encoded public key")
+	}
+
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the public key: %w", err)
+	}
+
+	switch publicKey.(type) {
+	case *ecdsa.PublicKey, *rsa.PublicKey, ed25519.PublicKey:
+		return publicKey, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+}
+
+// Verify resolves the digest of a bundle image, and verifies it against the
+// expected digest and the signature according to the options. The signature
+// is expected in the format of cosign, in the image with the tag
+// sha256-<hex>.sig next to the bundle image.
+func Verify(ref name.Reference, verifyOptions VerifyOptions, options ...remote.Option) (*Verification, error) {
+	desc, err := remote.Head(ref, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	verification := &Verification{
+		Reference: ref.Context().Digest(desc.Digest.String()),
+	}
+
+	if verifyOptions.Digest != "" {
+		if desc.Digest.String() != verifyOptions.Digest {
+			return nil, fmt.Errorf("%w: expected %s, but %s has the digest %s", ErrDigestMismatch, verifyOptions.Digest, ref, desc.Digest)
+		}
+
+		verification.DigestVerified = true
+	}
+
+	if verifyOptions.PublicKey != nil {
+		if err := verifySignature(verification.Reference, verifyOptions.PublicKey, options...); err != nil {
+			return nil, err
+		}
+
+		verification.SignatureVerified = true
+	}
+
+	return verification, nil
+}
+
+// Sign signs a bundle image with the signer, and pushes the signature in the
+// format of cosign next to the image
+func Sign(ref name.Digest, signer crypto.Signer, options ...remote.Option) error {
+	var payload signaturePayload
+	payload.Critical.Identity.DockerReference = ref.Context().String()
+	payload.Critical.Image.DockerManifestDigest = ref.DigestStr()
+	payload.Critical.Type = signaturePayloadType
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	var signature []byte
+	switch signer.Public().(type) {
+	case ed25519.PublicKey:
+		signature, err = signer.Sign(rand.Reader, data, crypto.Hash(0))
+
+	default:
+		hash := sha256.Sum256(data)
+		signature, err = signer.Sign(rand.Reader, hash[:], crypto.SHA256)
+	}
+	if err != nil {
+		return err
+	}
+
+	image := mutate.MediaType(empty.Image, types.OCIManifestSchema1)
+	image = mutate.ConfigMediaType(image, types.OCIConfigJSON)
+	image, err = mutate.Append(image, mutate.Addendum{
+		Layer: static.NewLayer(data, signaturePayloadMediaType),
+		Annotations: map[string]string{
+			signatureAnnotation: base64.StdEncoding.EncodeToString(signature),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	signatureRef, err := signatureTag(ref)
+	if err != nil {
+		return err
+	}
+
+	return remote.Write(signatureRef, image, options...)
+}
+
+// verifySignature verifies that at least one signature of the image can be
+// verified with the public key, and that it was created for the image digest
+func verifySignature(ref name.Digest, publicKey crypto.PublicKey, options ...remote.Option) error {
+	signatureRef, err := signatureTag(ref)
+	if err != nil {
+		return err
+	}
+
+	signatureImage, err := remote.Image(signatureRef, options...)
+	if err != nil {
+		var transportErr *transport.Error
+		if errors.As(err, &transportErr) && transportErr.StatusCode == http.StatusNotFound {
+			return fmt.Errorf("%w: %s does not exist", ErrSignatureNotFound, signatureRef)
+		}
+
+		return err
+	}
+
+	manifest, err := signatureImage.Manifest()
+	if err != nil {
+		return err
+	}
+
+	if len(manifest.Layers) == 0 {
+		return fmt.Errorf("%w: %s does not contain any signature", ErrSignatureNotFound, signatureRef)
+	}
+
+	var lastErr error
+	for _, descriptor := range manifest.Layers {
+		if lastErr = verifySignatureLayer(signatureImage, descriptor, ref, publicKey); lastErr == nil {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: none of the signatures in %s can be verified with the public key, last error: %v", ErrSignatureInvalid, signatureRef, lastErr)
+}
+
+func verifySignatureLayer(signatureImage containerreg.Image, descriptor containerreg.Descriptor, ref name.Digest, publicKey crypto.PublicKey) error {
+	encoded, ok := descriptor.Annotations[signatureAnnotation]
+	if !ok {
+		return fmt.Errorf("layer %s has no signature annotation", descriptor.Digest)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("failed to decode the signature of layer %s: %w", descriptor.Digest, err)
+	}
+
+	layer, err := signatureImage.LayerByDigest(descriptor.Digest)
+	if err != nil {
+		return err
+	}
+
+	rc, err := layer.Compressed()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	data, err := ioutil.ReadAll(rc)
+	if err != nil {
+		return err
+	}
+
+	if err := verifyPayloadSignature(publicKey, data, signature); err != nil {
+		return err
+	}
+
+	// the signature is only valid for the image that the payload references
+	var payload signaturePayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return fmt.Errorf("failed to parse the signature payload: %w", err)
+	}
+
+	if payload.Critical.Image.DockerManifestDigest != ref.DigestStr() {
+		return fmt.Errorf("the signature is for the digest %s", payload.Critical.Image.DockerManifestDigest)
+	}
+
+	return nil
+}
+
+func verifyPayloadSignature(publicKey crypto.PublicKey, payload []byte, signature []byte) error {
+	hash := sha256.Sum256(payload)
+
+	switch key := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(key, hash[:], signature) {
+			return errors.New("invalid ECDSA signature")
+		}
+		return nil
+
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], signature)
+
+	case ed25519.PublicKey:
+		if !ed25519.Verify(key, payload, signature) {
+			return errors.New("invalid Ed25519 signature")
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+}
+
+// signatureTag returns the tag of the cosign signature image of an image
+func signatureTag(ref name.Digest) (name.Tag, error) {
+	return name.NewTag(fmt.Sprintf("%s:%s.sig", ref.Context(), strings.Replace(ref.DigestStr(), ":", "-", 1)))
+}
@@ -0,0 +1,135 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package bundle_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"log"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+
+	. "github.com/shipwright-io/build/pkg/bundle"
+)
+
+var _ = Describe("Verify", func() {
+	var withPushedBundle = func(f func(ref name.Reference, digest name.Digest)) {
+		server := httptest.NewServer(registry.New(registry.Logger(log.New(ioutil.Discard, "", 0))))
+		defer server.Close()
+
+		ref, err := name.ParseReference(strings.TrimPrefix(server.URL, "http://") + "/test/bundle:latest")
+		Expect(err).ToNot(HaveOccurred())
+
+		digest, err := PackAndPush(ref, "../../test/bundle")
+		Expect(err).ToNot(HaveOccurred())
+
+		f(ref, digest)
+	}
+
+	var newECDSAKey = func() *ecdsa.PrivateKey {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).ToNot(HaveOccurred())
+		return key
+	}
+
+	Context("loading public keys", func() {
+		It("should load a PEM encoded public key", func() {
+			data, err := x509.MarshalPKIXPublicKey(newECDSAKey().Public())
+			Expect(err).ToNot(HaveOccurred())
+
+			publicKey, err := LoadPublicKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: data}))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(publicKey).To(BeAssignableToTypeOf(&ecdsa.PublicKey{}))
+		})
+
+		It("should fail in case the data is not PEM encoded", func() {
+			_, err := LoadPublicKey([]byte("not a key"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("verifying the digest", func() {
+		It("should return the digest reference in case nothing is verified", func() {
+			withPushedBundle(func(ref name.Reference, digest name.Digest) {
+				verification, err := Verify(ref, VerifyOptions{})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(verification.Reference.DigestStr()).To(Equal(digest.DigestStr()))
+				Expect(verification.Reference.Context()).To(Equal(ref.Context()))
+				Expect(verification.DigestVerified).To(BeFalse())
+				Expect(verification.SignatureVerified).To(BeFalse())
+			})
+		})
+
+		It("should succeed in case the digest matches", func() {
+			withPushedBundle(func(ref name.Reference, digest name.Digest) {
+				verification, err := Verify(ref, VerifyOptions{Digest: digest.DigestStr()})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(verification.DigestVerified).To(BeTrue())
+			})
+		})
+
+		It("should fail in case the digest does not match", func() {
+			withPushedBundle(func(ref name.Reference, _ name.Digest) {
+				_, err := Verify(ref, VerifyOptions{Digest: "sha256:" + strings.Repeat("0", 64)})
+				Expect(err).To(MatchError(ErrDigestMismatch))
+			})
+		})
+	})
+
+	Context("verifying the signature", func() {
+		It("should verify an ECDSA signature", func() {
+			withPushedBundle(func(ref name.Reference, digest name.Digest) {
+				key := newECDSAKey()
+				Expect(Sign(digest, key)).To(Succeed())
+
+				verification, err := Verify(ref, VerifyOptions{Digest: digest.DigestStr(), PublicKey: key.Public()})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(verification.DigestVerified).To(BeTrue())
+				Expect(verification.SignatureVerified).To(BeTrue())
+			})
+		})
+
+		It("should verify an Ed25519 signature", func() {
+			withPushedBundle(func(ref name.Reference, digest name.Digest) {
+				publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(Sign(digest, privateKey)).To(Succeed())
+
+				verification, err := Verify(ref, VerifyOptions{PublicKey: publicKey})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(verification.SignatureVerified).To(BeTrue())
+			})
+		})
+
+		It("should fail in case the image is not signed", func() {
+			withPushedBundle(func(ref name.Reference, _ name.Digest) {
+				_, err := Verify(ref, VerifyOptions{PublicKey: newECDSAKey().Public()})
+				Expect(err).To(MatchError(ErrSignatureNotFound))
+			})
+		})
+
+		It("should fail in case the image is signed with a different key", func() {
+			withPushedBundle(func(ref name.Reference, digest name.Digest) {
+				Expect(Sign(digest, newECDSAKey())).To(Succeed())
+
+				var publicKey crypto.PublicKey = newECDSAKey().Public()
+				_, err := Verify(ref, VerifyOptions{PublicKey: publicKey})
+				Expect(err).To(MatchError(ErrSignatureInvalid))
+			})
+		})
+	})
+})
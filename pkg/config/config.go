@@ -6,6 +6,7 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"strconv"
 	"strings"
@@ -22,9 +23,6 @@ const (
 	// E.g. if 5 seconds is wanted, the CTX_TIMEOUT=5
 	contextTimeoutEnvVar = "CTX_TIMEOUT"
 
-	remoteArtifactsDefaultImage = "quay.io/quay/busybox:latest"
-	remoteArtifactsEnvVar       = "REMOTE_ARTIFACTS_CONTAINER_IMAGE"
-
 	// the Git image is built using ko which can replace environment variable values in the deployment, so once we decide to move
 	// from environment variables to a ConfigMap, then we should move the container template, but retain the environment variable
 	// (or make it an argument like Tekton)
@@ -41,6 +39,15 @@ const (
 	bundleImageEnvVar             = "BUNDLE_CONTAINER_IMAGE"
 	bundleContainerTemplateEnvVar = "BUNDLE_CONTAINER_TEMPLATE"
 
+	// Analog to the Git image, the HTTP image is also created by ko
+	httpDefaultImage            = "ghcr.io/shipwright-io/build/http:latest"
+	httpImageEnvVar             = "HTTP_CONTAINER_IMAGE"
+	httpContainerTemplateEnvVar = "HTTP_CONTAINER_TEMPLATE"
+
+	// deprecated environment variable of the image of the HTTP source step, it
+	// is read in case HTTP_CONTAINER_IMAGE is not set
+	remoteArtifactsEnvVar = "REMOTE_ARTIFACTS_CONTAINER_IMAGE"
+
 	// environment variable to hold waiter's container image, created by ko
 	waiterDefaultImage            = "ghcr.io/shipwright-io/build/waiter:latest"
 	waiterImageEnvVar             = "WAITER_CONTAINER_IMAGE"
@@ -50,6 +57,7 @@ const (
 	metricBuildRunCompletionDurationBucketsEnvVar = "PROMETHEUS_BR_COMP_DUR_BUCKETS"
 	metricBuildRunEstablishDurationBucketsEnvVar  = "PROMETHEUS_BR_EST_DUR_BUCKETS"
 	metricBuildRunRampUpDurationBucketsEnvVar     = "PROMETHEUS_BR_RAMPUP_DUR_BUCKETS"
+	metricBuildRunStepDurationBucketsEnvVar       = "PROMETHEUS_BR_STEP_DUR_BUCKETS"
 
 	// environment variable to enable prometheus metric labels
 	prometheusEnabledLabelsEnvVar = "PROMETHEUS_ENABLED_LABELS"
@@ -76,6 +84,56 @@ const (
 
 	// environment variable for the Git rewrite setting
 	useGitRewriteRule = "GIT_ENABLE_REWRITE_RULE"
+
+	// environment variables for the proxy and the trusted CA bundle of the steps that the
+	// controller generates, the ConfigMap must exist in the namespace of the BuildRun
+	stepHTTPProxyEnvVar         = "STEP_HTTP_PROXY"
+	stepHTTPSProxyEnvVar        = "STEP_HTTPS_PROXY"
+	stepNoProxyEnvVar           = "STEP_NO_PROXY"
+	stepCABundleConfigMapEnvVar = "STEP_CA_BUNDLE_CONFIGMAP"
+	stepCABundleKeyEnvVar       = "STEP_CA_BUNDLE_KEY"
+
+	// environment variables for the endpoint that receives the source code of
+	// LocalCopy sources, an empty address disables the endpoint
+	uploadServerAddressEnvVar     = "UPLOAD_SERVER_ADDRESS"
+	uploadServerTLSCertFileEnvVar = "UPLOAD_SERVER_TLS_CERT_FILE"
+	uploadServerTLSKeyFileEnvVar  = "UPLOAD_SERVER_TLS_KEY_FILE"
+	uploadServerInsecureEnvVar    = "UPLOAD_SERVER_INSECURE"
+
+	// environment variable to limit the number of running BuildRuns per namespace
+	maxRunningBuildRunsPerNamespaceEnvVar = "BUILDRUN_MAX_RUNNING_PER_NAMESPACE"
+
+	// environment variables for the archive of the logs of completed BuildRuns,
+	// an empty sink disables the archive
+	buildRunLogsSinkEnvVar              = "BUILDRUN_LOGS_SINK"
+	buildRunLogsDirectoryEnvVar         = "BUILDRUN_LOGS_DIRECTORY"
+	buildRunLogsS3EndpointEnvVar        = "BUILDRUN_LOGS_S3_ENDPOINT"
+	buildRunLogsS3BucketEnvVar          = "BUILDRUN_LOGS_S3_BUCKET"
+	buildRunLogsS3RegionEnvVar          = "BUILDRUN_LOGS_S3_REGION"
+	buildRunLogsS3AccessKeyIDEnvVar     = "BUILDRUN_LOGS_S3_ACCESS_KEY_ID"
+	buildRunLogsS3SecretAccessKeyEnvVar = "BUILDRUN_LOGS_S3_SECRET_ACCESS_KEY"
+	buildRunLogsS3RegionDefault         = "us-east-1"
+
+	// environment variables for the endpoint that streams the logs of BuildRuns,
+	// an empty address disables the endpoint
+	logsServerAddressEnvVar     = "LOGS_SERVER_ADDRESS"
+	logsServerTLSCertFileEnvVar = "LOGS_SERVER_TLS_CERT_FILE"
+	logsServerTLSKeyFileEnvVar  = "LOGS_SERVER_TLS_KEY_FILE"
+	logsServerInsecureEnvVar    = "LOGS_SERVER_INSECURE"
+)
+
+// Sinks of the archive of the logs of completed BuildRuns
+const (
+	// LogsSinkPVC stores the logs as files in a directory of the controller
+	// in which a PersistentVolumeClaim is mounted
+	LogsSinkPVC = "pvc"
+
+	// LogsSinkOCI pushes the logs as an OCI artifact into the repository of
+	// the output image
+	LogsSinkOCI = "oci"
+
+	// LogsSinkS3 uploads the logs into a bucket of an S3-compatible endpoint
+	LogsSinkS3 = "s3"
 )
 
 var (
@@ -83,6 +141,7 @@ var (
 	metricBuildRunCompletionDurationBuckets = prometheus.LinearBuckets(50, 50, 10)
 	metricBuildRunEstablishDurationBuckets  = []float64{0, 1, 2, 3, 5, 7, 10, 15, 20, 30}
 	metricBuildRunRampUpDurationBuckets     = prometheus.LinearBuckets(0, 1, 10)
+	metricBuildRunStepDurationBuckets       = []float64{1, 5, 10, 30, 60, 120, 180, 300, 600, 900}
 
 	root    = pointer.Int64(0)
 	nonRoot = pointer.Int64(1000)
@@ -91,18 +150,107 @@ var (
 // Config hosts different parameters that
 // can be set to use on the Build controllers
 type Config struct {
-	CtxTimeOut                    time.Duration
-	GitContainerTemplate          corev1.Container
-	MutateImageContainerTemplate  corev1.Container
-	BundleContainerTemplate       corev1.Container
-	WaiterContainerTemplate       corev1.Container
-	RemoteArtifactsContainerImage string
-	TerminationLogPath            string
-	Prometheus                    PrometheusConfig
-	ManagerOptions                ManagerOptions
-	Controllers                   Controllers
-	KubeAPIOptions                KubeAPIOptions
-	GitRewriteRule                bool
+	CtxTimeOut                   time.Duration
+	GitContainerTemplate         corev1.Container
+	MutateImageContainerTemplate corev1.Container
+	BundleContainerTemplate      corev1.Container
+	HTTPContainerTemplate        corev1.Container
+	WaiterContainerTemplate      corev1.Container
+	TerminationLogPath           string
+	Prometheus                   PrometheusConfig
+	ManagerOptions               ManagerOptions
+	Controllers                  Controllers
+	KubeAPIOptions               KubeAPIOptions
+	GitRewriteRule               bool
+	Proxy                        ProxyConfig
+	CABundle                     CABundleConfig
+	UploadServer                 UploadServerConfig
+
+	// MaxRunningBuildRunsPerNamespace limits the number of BuildRuns that run at the
+	// same time in a namespace, further BuildRuns are queued. There is no limit if the
+	// value is 0 or lower.
+	MaxRunningBuildRunsPerNamespace int
+
+	BuildRunLogs BuildRunLogsConfig
+	LogsServer   LogsServerConfig
+}
+
+// LogsServerConfig contains the settings of the endpoint that streams the logs
+// of the steps of BuildRuns
+type LogsServerConfig struct {
+	// Address to listen on, for example :8444, the endpoint is disabled
+	// when it is empty
+	Address string
+
+	// TLSCertFile and TLSKeyFile are the PEM encoded certificate and key of
+	// the endpoint, which is required unless Insecure is set
+	TLSCertFile string
+	TLSKeyFile  string
+
+	// Insecure allows the endpoint to serve plain HTTP, which exposes the
+	// bearer tokens of its clients to the network
+	Insecure bool
+}
+
+// BuildRunLogsConfig contains the settings of the archive of the logs of the
+// steps of completed BuildRuns
+type BuildRunLogsConfig struct {
+	// Sink is where the logs are stored, one of pvc, oci or s3, the archive
+	// is disabled when it is empty
+	Sink string
+
+	// Directory is where the PersistentVolumeClaim of the pvc sink is mounted
+	Directory string
+
+	// S3 contains the settings of the s3 sink
+	S3 S3Config
+}
+
+// S3Config contains the endpoint, bucket and credentials of an S3-compatible
+// object storage
+type S3Config struct {
+	Endpoint        string
+	Bucket          string
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// UploadServerConfig contains the settings of the endpoint that receives the
+// source code of LocalCopy sources and streams it into the waiting build pod
+type UploadServerConfig struct {
+	// Address to listen on, for example :8443, the endpoint is disabled
+	// when it is empty
+	Address string
+
+	// TLSCertFile and TLSKeyFile are the PEM encoded certificate and key of
+	// the endpoint, which is required unless Insecure is set
+	TLSCertFile string
+	TLSKeyFile  string
+
+	// Insecure allows the endpoint to serve plain HTTP, which exposes the
+	// bearer tokens of its clients to the network
+	Insecure bool
+}
+
+// ProxyConfig contains the HTTP proxy settings for the steps that the
+// controller generates, for example to retrieve the source code
+type ProxyConfig struct {
+	HTTPProxy  string
+	HTTPSProxy string
+	NoProxy    string
+}
+
+// DefaultCABundleKey is the key in the ConfigMap of a CA bundle that contains
+// the certificates, unless another key is configured
+const DefaultCABundleKey = "ca-bundle.crt"
+
+// CABundleConfig references a key in a ConfigMap that contains PEM encoded
+// certificates that the steps that the controller generates trust in addition
+// to the system certificates
+type CABundleConfig struct {
+	ConfigMap string
+	Key       string
 }
 
 // PrometheusConfig contains the specific configuration for the
@@ -110,6 +258,7 @@ type PrometheusConfig struct {
 	BuildRunCompletionDurationBuckets []float64
 	BuildRunEstablishDurationBuckets  []float64
 	BuildRunRampUpDurationBuckets     []float64
+	BuildRunStepDurationBuckets       []float64
 	EnabledLabels                     []string
 }
 
@@ -164,7 +313,16 @@ func NewDefaultConfig() *Config {
 				RunAsGroup: nonRoot,
 			},
 		},
-		RemoteArtifactsContainerImage: remoteArtifactsDefaultImage,
+		HTTPContainerTemplate: corev1.Container{
+			Image: httpDefaultImage,
+			Command: []string{
+				"/ko-app/http",
+			},
+			SecurityContext: &corev1.SecurityContext{
+				RunAsUser:  nonRoot,
+				RunAsGroup: nonRoot,
+			},
+		},
 		MutateImageContainerTemplate: corev1.Container{
 			Image: mutateImageDefaultImage,
 			Command: []string{
@@ -209,6 +367,7 @@ func NewDefaultConfig() *Config {
 			BuildRunCompletionDurationBuckets: metricBuildRunCompletionDurationBuckets,
 			BuildRunEstablishDurationBuckets:  metricBuildRunEstablishDurationBuckets,
 			BuildRunRampUpDurationBuckets:     metricBuildRunRampUpDurationBuckets,
+			BuildRunStepDurationBuckets:       metricBuildRunStepDurationBuckets,
 		},
 		ManagerOptions: ManagerOptions{
 			LeaderElectionNamespace: leaderElectionNamespaceDefault,
@@ -233,6 +392,14 @@ func NewDefaultConfig() *Config {
 		},
 		TerminationLogPath: terminationLogPathDefault,
 		GitRewriteRule:     false,
+		CABundle: CABundleConfig{
+			Key: DefaultCABundleKey,
+		},
+		BuildRunLogs: BuildRunLogsConfig{
+			S3: S3Config{
+				Region: buildRunLogsS3RegionDefault,
+			},
+		},
 	}
 }
 
@@ -311,8 +478,24 @@ func (c *Config) SetConfigFromEnv() error {
 		c.WaiterContainerTemplate.Image = waiterImage
 	}
 
-	if remoteArtifactsImage := os.Getenv(remoteArtifactsEnvVar); remoteArtifactsImage != "" {
-		c.RemoteArtifactsContainerImage = remoteArtifactsImage
+	if httpContainerTemplate := os.Getenv(httpContainerTemplateEnvVar); httpContainerTemplate != "" {
+		c.HTTPContainerTemplate = corev1.Container{}
+		if err := json.Unmarshal([]byte(httpContainerTemplate), &c.HTTPContainerTemplate); err != nil {
+			return err
+		}
+		if c.HTTPContainerTemplate.Image == "" {
+			c.HTTPContainerTemplate.Image = httpDefaultImage
+		}
+	}
+
+	// the dedicated environment variable for the image overwrites what is defined in the HTTP container template,
+	// the deprecated one is still used in case it is not set
+	httpImage := os.Getenv(httpImageEnvVar)
+	if httpImage == "" {
+		httpImage = os.Getenv(remoteArtifactsEnvVar)
+	}
+	if httpImage != "" {
+		c.HTTPContainerTemplate.Image = httpImage
 	}
 
 	if err := updateBucketsConfig(&c.Prometheus.BuildRunCompletionDurationBuckets, metricBuildRunCompletionDurationBucketsEnvVar); err != nil {
@@ -327,6 +510,10 @@ func (c *Config) SetConfigFromEnv() error {
 		return err
 	}
 
+	if err := updateBucketsConfig(&c.Prometheus.BuildRunStepDurationBuckets, metricBuildRunStepDurationBucketsEnvVar); err != nil {
+		return err
+	}
+
 	c.Prometheus.EnabledLabels = strings.Split(os.Getenv(prometheusEnabledLabelsEnvVar), ",")
 
 	if leaderElectionNamespace := os.Getenv(leaderElectionNamespaceEnvVar); leaderElectionNamespace != "" {
@@ -371,6 +558,59 @@ func (c *Config) SetConfigFromEnv() error {
 		c.TerminationLogPath = terminationLogPath
 	}
 
+	// proxy and CA bundle settings of the generated steps
+	c.Proxy.HTTPProxy = os.Getenv(stepHTTPProxyEnvVar)
+	c.Proxy.HTTPSProxy = os.Getenv(stepHTTPSProxyEnvVar)
+	c.Proxy.NoProxy = os.Getenv(stepNoProxyEnvVar)
+
+	c.CABundle.ConfigMap = os.Getenv(stepCABundleConfigMapEnvVar)
+	if caBundleKey := os.Getenv(stepCABundleKeyEnvVar); caBundleKey != "" {
+		c.CABundle.Key = caBundleKey
+	}
+
+	// upload endpoint settings
+	c.UploadServer.Address = os.Getenv(uploadServerAddressEnvVar)
+	c.UploadServer.TLSCertFile = os.Getenv(uploadServerTLSCertFileEnvVar)
+	c.UploadServer.TLSKeyFile = os.Getenv(uploadServerTLSKeyFileEnvVar)
+	if err := updateBoolOption(&c.UploadServer.Insecure, uploadServerInsecureEnvVar); err != nil {
+		return err
+	}
+	if err := validateServerTLS(c.UploadServer.Address, c.UploadServer.TLSCertFile, c.UploadServer.TLSKeyFile, c.UploadServer.Insecure, uploadServerTLSCertFileEnvVar, uploadServerTLSKeyFileEnvVar, uploadServerInsecureEnvVar); err != nil {
+		return err
+	}
+
+	if err := updateIntOption(&c.MaxRunningBuildRunsPerNamespace, maxRunningBuildRunsPerNamespaceEnvVar); err != nil {
+		return err
+	}
+
+	// log archive settings
+	c.BuildRunLogs.Sink = os.Getenv(buildRunLogsSinkEnvVar)
+	switch c.BuildRunLogs.Sink {
+	case "", LogsSinkPVC, LogsSinkOCI, LogsSinkS3:
+	default:
+		return fmt.Errorf("unknown sink %q for the logs of BuildRuns, supported are %s, %s and %s", c.BuildRunLogs.Sink, LogsSinkPVC, LogsSinkOCI, LogsSinkS3)
+	}
+
+	c.BuildRunLogs.Directory = os.Getenv(buildRunLogsDirectoryEnvVar)
+	c.BuildRunLogs.S3.Endpoint = os.Getenv(buildRunLogsS3EndpointEnvVar)
+	c.BuildRunLogs.S3.Bucket = os.Getenv(buildRunLogsS3BucketEnvVar)
+	if region := os.Getenv(buildRunLogsS3RegionEnvVar); region != "" {
+		c.BuildRunLogs.S3.Region = region
+	}
+	c.BuildRunLogs.S3.AccessKeyID = os.Getenv(buildRunLogsS3AccessKeyIDEnvVar)
+	c.BuildRunLogs.S3.SecretAccessKey = os.Getenv(buildRunLogsS3SecretAccessKeyEnvVar)
+
+	// log streaming endpoint settings
+	c.LogsServer.Address = os.Getenv(logsServerAddressEnvVar)
+	c.LogsServer.TLSCertFile = os.Getenv(logsServerTLSCertFileEnvVar)
+	c.LogsServer.TLSKeyFile = os.Getenv(logsServerTLSKeyFileEnvVar)
+	if err := updateBoolOption(&c.LogsServer.Insecure, logsServerInsecureEnvVar); err != nil {
+		return err
+	}
+	if err := validateServerTLS(c.LogsServer.Address, c.LogsServer.TLSCertFile, c.LogsServer.TLSKeyFile, c.LogsServer.Insecure, logsServerTLSCertFileEnvVar, logsServerTLSKeyFileEnvVar, logsServerInsecureEnvVar); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -425,3 +665,29 @@ func updateIntOption(i *int, envVarName string) error {
 
 	return nil
 }
+
+func updateBoolOption(b *bool, envVarName string) error {
+	if value := os.Getenv(envVarName); value != "" {
+		boolValue, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		*b = boolValue
+	}
+
+	return nil
+}
+
+// validateServerTLS ensures that an enabled endpoint, which authenticates its
+// clients with bearer tokens, serves TLS unless plain HTTP is explicitly allowed
+func validateServerTLS(address string, certFile string, keyFile string, insecure bool, certFileEnvVar string, keyFileEnvVar string, insecureEnvVar string) error {
+	if address == "" || insecure {
+		return nil
+	}
+
+	if certFile == "" || keyFile == "" {
+		return fmt.Errorf("%s and %s must be set to serve TLS on %s, set %s=true to serve plain HTTP instead", certFileEnvVar, keyFileEnvVar, address, insecureEnvVar)
+	}
+
+	return nil
+}
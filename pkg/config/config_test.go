@@ -36,12 +36,14 @@ var _ = Describe("Config", func() {
 				"PROMETHEUS_BR_COMP_DUR_BUCKETS":   "1,2,3,4",
 				"PROMETHEUS_BR_EST_DUR_BUCKETS":    "10,20,30,40",
 				"PROMETHEUS_BR_RAMPUP_DUR_BUCKETS": "1,2,3,5,8,12,20",
+				"PROMETHEUS_BR_STEP_DUR_BUCKETS":   "10,60,600",
 			}
 
 			configWithEnvVariableOverrides(overrides, func(config *Config) {
 				Expect(config.Prometheus.BuildRunCompletionDurationBuckets).To(Equal([]float64{1, 2, 3, 4}))
 				Expect(config.Prometheus.BuildRunEstablishDurationBuckets).To(Equal([]float64{10, 20, 30, 40}))
 				Expect(config.Prometheus.BuildRunRampUpDurationBuckets).To(Equal([]float64{1, 2, 3, 5, 8, 12, 20}))
+				Expect(config.Prometheus.BuildRunStepDurationBuckets).To(Equal([]float64{10, 60, 600}))
 			})
 		})
 
@@ -251,6 +253,166 @@ var _ = Describe("Config", func() {
 				}))
 			})
 		})
+
+		It("should allow for an override of the HTTP container template and image", func() {
+			var overrides = map[string]string{
+				"HTTP_CONTAINER_TEMPLATE": `{"image":"myregistry/custom/http-image","resources":{"requests":{"cpu":"0.5","memory":"128Mi"}}}`,
+				"HTTP_CONTAINER_IMAGE":    "myregistry/custom/http-image:override",
+			}
+
+			configWithEnvVariableOverrides(overrides, func(config *Config) {
+				Expect(config.HTTPContainerTemplate).To(Equal(corev1.Container{
+					Image: "myregistry/custom/http-image:override",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("0.5"),
+							corev1.ResourceMemory: resource.MustParse("128Mi"),
+						},
+					},
+				}))
+			})
+		})
+
+		It("should still use the deprecated remote artifacts image as the HTTP container image", func() {
+			var overrides = map[string]string{
+				"REMOTE_ARTIFACTS_CONTAINER_IMAGE": "myregistry/custom/http-image:deprecated",
+			}
+
+			configWithEnvVariableOverrides(overrides, func(config *Config) {
+				Expect(config.HTTPContainerTemplate.Image).To(Equal("myregistry/custom/http-image:deprecated"))
+				Expect(config.HTTPContainerTemplate.Command).To(Equal([]string{"/ko-app/http"}))
+			})
+		})
+
+		It("should prefer the HTTP container image over the deprecated remote artifacts image", func() {
+			var overrides = map[string]string{
+				"REMOTE_ARTIFACTS_CONTAINER_IMAGE": "myregistry/custom/http-image:deprecated",
+				"HTTP_CONTAINER_IMAGE":             "myregistry/custom/http-image:override",
+			}
+
+			configWithEnvVariableOverrides(overrides, func(config *Config) {
+				Expect(config.HTTPContainerTemplate.Image).To(Equal("myregistry/custom/http-image:override"))
+			})
+		})
+
+		It("should allow for an override of the proxy and CA bundle settings of the steps", func() {
+			var overrides = map[string]string{
+				"STEP_HTTP_PROXY":          "http://proxy.example.com:3128",
+				"STEP_HTTPS_PROXY":         "http://proxy.example.com:3129",
+				"STEP_NO_PROXY":            ".svc,.cluster.local",
+				"STEP_CA_BUNDLE_CONFIGMAP": "trusted-ca",
+			}
+
+			configWithEnvVariableOverrides(overrides, func(config *Config) {
+				Expect(config.Proxy).To(Equal(ProxyConfig{
+					HTTPProxy:  "http://proxy.example.com:3128",
+					HTTPSProxy: "http://proxy.example.com:3129",
+					NoProxy:    ".svc,.cluster.local",
+				}))
+				Expect(config.CABundle).To(Equal(CABundleConfig{
+					ConfigMap: "trusted-ca",
+					Key:       "ca-bundle.crt",
+				}))
+			})
+		})
+
+		It("should allow for an override of the upload endpoint settings", func() {
+			var overrides = map[string]string{
+				"UPLOAD_SERVER_ADDRESS":       ":8443",
+				"UPLOAD_SERVER_TLS_CERT_FILE": "/etc/upload/tls.crt",
+				"UPLOAD_SERVER_TLS_KEY_FILE":  "/etc/upload/tls.key",
+			}
+
+			configWithEnvVariableOverrides(overrides, func(config *Config) {
+				Expect(config.UploadServer).To(Equal(UploadServerConfig{
+					Address:     ":8443",
+					TLSCertFile: "/etc/upload/tls.crt",
+					TLSKeyFile:  "/etc/upload/tls.key",
+				}))
+			})
+		})
+
+		It("should allow for an override of the running BuildRuns per namespace", func() {
+			var overrides = map[string]string{
+				"BUILDRUN_MAX_RUNNING_PER_NAMESPACE": "5",
+			}
+
+			configWithEnvVariableOverrides(overrides, func(config *Config) {
+				Expect(config.MaxRunningBuildRunsPerNamespace).To(Equal(5))
+			})
+		})
+
+		It("should allow for an override of the log archive settings", func() {
+			var overrides = map[string]string{
+				"BUILDRUN_LOGS_SINK":                 "s3",
+				"BUILDRUN_LOGS_S3_ENDPOINT":          "https://s3.example.com",
+				"BUILDRUN_LOGS_S3_BUCKET":            "buildrun-logs",
+				"BUILDRUN_LOGS_S3_ACCESS_KEY_ID":     "access-key",
+				"BUILDRUN_LOGS_S3_SECRET_ACCESS_KEY": "secret-key",
+			}
+
+			configWithEnvVariableOverrides(overrides, func(config *Config) {
+				Expect(config.BuildRunLogs.Sink).To(Equal(LogsSinkS3))
+				Expect(config.BuildRunLogs.S3).To(Equal(S3Config{
+					Endpoint:        "https://s3.example.com",
+					Bucket:          "buildrun-logs",
+					Region:          "us-east-1",
+					AccessKeyID:     "access-key",
+					SecretAccessKey: "secret-key",
+				}))
+			})
+		})
+
+		It("should allow for an override of the log streaming endpoint settings", func() {
+			var overrides = map[string]string{
+				"LOGS_SERVER_ADDRESS":       ":8444",
+				"LOGS_SERVER_TLS_CERT_FILE": "/etc/logs/tls.crt",
+				"LOGS_SERVER_TLS_KEY_FILE":  "/etc/logs/tls.key",
+			}
+
+			configWithEnvVariableOverrides(overrides, func(config *Config) {
+				Expect(config.LogsServer).To(Equal(LogsServerConfig{
+					Address:     ":8444",
+					TLSCertFile: "/etc/logs/tls.crt",
+					TLSKeyFile:  "/etc/logs/tls.key",
+				}))
+			})
+		})
+
+		It("should allow plain HTTP for the endpoints when it is explicitly requested", func() {
+			var overrides = map[string]string{
+				"UPLOAD_SERVER_ADDRESS":  ":8443",
+				"UPLOAD_SERVER_INSECURE": "true",
+				"LOGS_SERVER_ADDRESS":    ":8444",
+				"LOGS_SERVER_INSECURE":   "true",
+			}
+
+			configWithEnvVariableOverrides(overrides, func(config *Config) {
+				Expect(config.UploadServer).To(Equal(UploadServerConfig{Address: ":8443", Insecure: true}))
+				Expect(config.LogsServer).To(Equal(LogsServerConfig{Address: ":8444", Insecure: true}))
+			})
+		})
+
+		It("should fail for an upload endpoint without TLS", func() {
+			os.Setenv("UPLOAD_SERVER_ADDRESS", ":8443")
+			defer os.Unsetenv("UPLOAD_SERVER_ADDRESS")
+
+			Expect(NewDefaultConfig().SetConfigFromEnv()).ToNot(Succeed())
+		})
+
+		It("should fail for a log streaming endpoint without TLS", func() {
+			os.Setenv("LOGS_SERVER_ADDRESS", ":8444")
+			defer os.Unsetenv("LOGS_SERVER_ADDRESS")
+
+			Expect(NewDefaultConfig().SetConfigFromEnv()).ToNot(Succeed())
+		})
+
+		It("should fail for an unknown log archive sink", func() {
+			os.Setenv("BUILDRUN_LOGS_SINK", "ftp")
+			defer os.Unsetenv("BUILDRUN_LOGS_SINK")
+
+			Expect(NewDefaultConfig().SetConfigFromEnv()).ToNot(Succeed())
+		})
 	})
 })
 
@@ -13,14 +13,18 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	"github.com/shipwright-io/build/pkg/apis"
+	"github.com/shipwright-io/build/pkg/auth"
 	"github.com/shipwright-io/build/pkg/config"
 	"github.com/shipwright-io/build/pkg/ctxlog"
+	"github.com/shipwright-io/build/pkg/logs"
 	"github.com/shipwright-io/build/pkg/reconciler/build"
 	"github.com/shipwright-io/build/pkg/reconciler/build_limit_cleanup"
+	"github.com/shipwright-io/build/pkg/reconciler/build_schedule"
 	"github.com/shipwright-io/build/pkg/reconciler/buildrun"
 	"github.com/shipwright-io/build/pkg/reconciler/buildrun_ttl_cleanup"
 	"github.com/shipwright-io/build/pkg/reconciler/buildstrategy"
 	"github.com/shipwright-io/build/pkg/reconciler/clusterbuildstrategy"
+	"github.com/shipwright-io/build/pkg/upload"
 )
 
 // NewManager add all the controllers to the manager and register the required schemes
@@ -73,5 +77,27 @@ func NewManager(ctx context.Context, config *config.Config, cfg *rest.Config, op
 		return nil, err
 	}
 
+	if err := build_schedule.Add(ctx, config, mgr); err != nil {
+		return nil, err
+	}
+
+	// Add the upload endpoint for LocalCopy sources
+	if config.UploadServer.Address != "" {
+		if err := mgr.Add(upload.NewServer(config, mgr.GetClient(), auth.NewKubernetesSubresourceAuthorizer(mgr.GetClient(), "create", "upload"))); err != nil {
+			return nil, err
+		}
+	}
+
+	if config.LogsServer.Address != "" {
+		reader, err := logs.NewPodLogReaderForConfig(mgr.GetConfig())
+		if err != nil {
+			return nil, err
+		}
+
+		if err := mgr.Add(logs.NewServer(config, mgr.GetClient(), auth.NewKubernetesSubresourceAuthorizer(mgr.GetClient(), "get", "log"), reader)); err != nil {
+			return nil, err
+		}
+	}
+
 	return mgr, nil
 }
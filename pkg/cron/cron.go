@@ -0,0 +1,238 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// searchLimit is how far the next time of a schedule is searched for, a
+// schedule like "0 0 30 2 *" never matches
+const searchLimit = 5 * 365 * 24 * time.Hour
+
+// field defines the range of values of a field of a schedule, and the names
+// that can be used instead of the numbers
+type field struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// Sunday is both 0 and 7
+	dowField = field{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Schedule is a parsed cron schedule with the five fields minute, hour, day
+// of month, month and day of week
+type Schedule struct {
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+
+	// like in cron, a day matches either the day of month or the day of
+	// week if both are restricted
+	domRestricted bool
+	dowRestricted bool
+
+	location *time.Location
+}
+
+// Parse parses a schedule in cron syntax whose times are interpreted in UTC
+func Parse(spec string) (*Schedule, error) {
+	return ParseInLocation(spec, "")
+}
+
+// ParseInLocation parses a schedule in cron syntax whose times are
+// interpreted in the named time zone, an empty name stands for UTC
+func ParseInLocation(spec string, timeZone string) (*Schedule, error) {
+	location, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q: %w", timeZone, err)
+	}
+
+	spec = strings.TrimSpace(spec)
+	if strings.HasPrefix(spec, "@") {
+		expanded, ok := macros[strings.ToLower(spec)]
+		if !ok {
+			return nil, fmt.Errorf("unknown macro %q", spec)
+		}
+
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expected five fields (minute, hour, day of month, month, day of week), found %d in %q", len(fields), spec)
+	}
+
+	schedule := &Schedule{location: location}
+	for i, target := range []struct {
+		field field
+		bits  *uint64
+	}{
+		{minuteField, &schedule.minute},
+		{hourField, &schedule.hour},
+		{domField, &schedule.dom},
+		{monthField, &schedule.month},
+		{dowField, &schedule.dow},
+	} {
+		if *target.bits, err = parseField(fields[i], target.field); err != nil {
+			return nil, err
+		}
+	}
+
+	if schedule.dow&(1<<7) != 0 {
+		schedule.dow |= 1
+	}
+
+	schedule.domRestricted = !strings.HasPrefix(fields[2], "*")
+	schedule.dowRestricted = !strings.HasPrefix(fields[4], "*")
+
+	return schedule, nil
+}
+
+// Next returns the first time of the schedule after the given time, or the
+// zero time if the schedule never matches
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.In(s.location)
+	limit := t.Add(searchLimit)
+
+	// schedules have a precision of one minute
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, s.location).Add(time.Minute)
+
+	for t.Before(limit) {
+		switch {
+		case !has(s.month, int(t.Month())):
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.location)
+
+		case !s.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.location)
+
+		case !has(s.hour, t.Hour()):
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, s.location)
+
+		case !has(s.minute, t.Minute()):
+			t = t.Add(time.Minute)
+
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+func (s *Schedule) matchesDay(t time.Time) bool {
+	domMatch := has(s.dom, t.Day())
+	dowMatch := has(s.dow, int(t.Weekday()))
+
+	if s.domRestricted && s.dowRestricted {
+		return domMatch || dowMatch
+	}
+
+	return domMatch && dowMatch
+}
+
+func has(bits uint64, value int) bool {
+	return bits&(1<<uint(value)) != 0
+}
+
+// parseField parses a comma-separated list of values, ranges and steps, for
+// example "1,15-20,*/5", into a bit set
+func parseField(value string, f field) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(value, ",") {
+		rangePart, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			rangePart = part[:i]
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step %q in the %s field", part[i+1:], f.name)
+			}
+		}
+
+		var start, end int
+		switch {
+		case rangePart == "*":
+			start, end = f.min, f.max
+
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if start, err = parseValue(bounds[0], f); err != nil {
+				return 0, err
+			}
+
+			if end, err = parseValue(bounds[1], f); err != nil {
+				return 0, err
+			}
+
+			if start > end {
+				return 0, fmt.Errorf("invalid range %q in the %s field", rangePart, f.name)
+			}
+
+		default:
+			var err error
+			if start, err = parseValue(rangePart, f); err != nil {
+				return 0, err
+			}
+
+			// a single value with a step, like 5/15, continues until the maximum
+			end = start
+			if strings.Contains(part, "/") {
+				end = f.max
+			}
+		}
+
+		for i := start; i <= end; i += step {
+			bits |= 1 << uint(i)
+		}
+	}
+
+	return bits, nil
+}
+
+func parseValue(value string, f field) (int, error) {
+	if number, ok := f.names[strings.ToLower(value)]; ok {
+		return number, nil
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q in the %s field", value, f.name)
+	}
+
+	if number < f.min || number > f.max {
+		return 0, fmt.Errorf("value %d in the %s field is outside of %d-%d", number, f.name, f.min, f.max)
+	}
+
+	return number, nil
+}
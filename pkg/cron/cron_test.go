@@ -0,0 +1,65 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cron_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/shipwright-io/build/pkg/cron"
+)
+
+var _ = Describe("Cron", func() {
+	// a Wednesday
+	from := time.Date(2021, time.September, 15, 10, 30, 0, 0, time.UTC)
+
+	DescribeTable("the next time of a schedule",
+		func(spec string, expected time.Time) {
+			schedule, err := cron.Parse(spec)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(schedule.Next(from)).To(Equal(expected))
+		},
+		Entry("every minute", "* * * * *", time.Date(2021, time.September, 15, 10, 31, 0, 0, time.UTC)),
+		Entry("nightly", "0 2 * * *", time.Date(2021, time.September, 16, 2, 0, 0, 0, time.UTC)),
+		Entry("steps", "*/20 * * * *", time.Date(2021, time.September, 15, 10, 40, 0, 0, time.UTC)),
+		Entry("lists and ranges", "5,50 8-10 * * *", time.Date(2021, time.September, 15, 10, 50, 0, 0, time.UTC)),
+		Entry("day of week names", "0 0 * * sat,sun", time.Date(2021, time.September, 18, 0, 0, 0, 0, time.UTC)),
+		Entry("Sunday as 7", "0 0 * * 7", time.Date(2021, time.September, 19, 0, 0, 0, 0, time.UTC)),
+		Entry("day of month or day of week", "0 0 1 * mon", time.Date(2021, time.September, 20, 0, 0, 0, 0, time.UTC)),
+		Entry("month names", "0 0 1 jan *", time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)),
+		Entry("a macro", "@weekly", time.Date(2021, time.September, 19, 0, 0, 0, 0, time.UTC)),
+		Entry("a date that never exists", "0 0 30 2 *", time.Time{}),
+	)
+
+	DescribeTable("invalid schedules",
+		func(spec string) {
+			_, err := cron.Parse(spec)
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("too few fields", "0 2 * *"),
+		Entry("a seconds field", "0 0 2 * * *"),
+		Entry("a value out of range", "60 * * * *"),
+		Entry("an inverted range", "0 10-8 * * *"),
+		Entry("an invalid step", "*/0 * * * *"),
+		Entry("an unknown name", "0 0 * * fun"),
+		Entry("an unknown macro", "@sometimes"),
+	)
+
+	Context("with a time zone", func() {
+		It("interprets the schedule in the time zone", func() {
+			schedule, err := cron.ParseInLocation("0 2 * * *", "Europe/Berlin")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(schedule.Next(from).UTC()).To(Equal(time.Date(2021, time.September, 16, 0, 0, 0, 0, time.UTC)))
+		})
+
+		It("fails for an unknown time zone", func() {
+			_, err := cron.ParseInLocation("0 2 * * *", "Mars/Olympus_Mons")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -47,6 +47,18 @@ const (
 	RepositoryNotFound
 	// AuthPrompted is caused when a repo is not found, is private and authentication is insufficient
 	AuthPrompted
+	// RateLimited expresses that the Git server rejected the request because too many requests were sent.
+	RateLimited
+	// TLSVerificationFailed expresses that the certificate of the Git server could not be verified.
+	TLSVerificationFailed
+	// HostNotResolved expresses that the host name of the Git server could not be resolved.
+	HostNotResolved
+	// ProxyError expresses that the connection through the configured HTTP proxy failed.
+	ProxyError
+	// Timeout expresses that the connection to the Git server or a transfer timed out.
+	Timeout
+	// ShallowFetchUnsupported expresses that the Git server does not support shallow fetches.
+	ShallowFetchUnsupported
 )
 
 type rawToken struct {
@@ -96,6 +108,18 @@ func (class ErrorClass) String() string {
 		return "GitSSHAuthUnexpected"
 	case AuthExpectedSSH:
 		return "GitSSHAuthExpected"
+	case RateLimited:
+		return "GitRateLimited"
+	case TLSVerificationFailed:
+		return "GitTLSVerificationFailed"
+	case HostNotResolved:
+		return "GitHostNotResolved"
+	case ProxyError:
+		return "GitProxyError"
+	case Timeout:
+		return "GitTimeout"
+	case ShallowFetchUnsupported:
+		return "GitShallowFetchUnsupported"
 	}
 
 	return "GitError"
@@ -120,12 +144,28 @@ func (class ErrorClass) ToMessage() string {
 		return "Credential/URL inconsistency: No SSH credentials provided, but URL is a SSH Git URL."
 	case AuthBasicIncomplete:
 		return "Basic Auth incomplete: Both username and password need to be configured."
+	case RateLimited:
+		return "The Git server rejected the request because of rate limiting. Retry later, or use credentials with a higher rate limit."
+	case TLSVerificationFailed:
+		return "The TLS certificate of the Git server could not be verified. Make sure that the certificate authority of the server is trusted."
+	case HostNotResolved:
+		return "The host name of the Git server could not be resolved. Check the repository URL and the DNS configuration."
+	case ProxyError:
+		return "The connection through the HTTP proxy failed. Check the proxy configuration."
+	case Timeout:
+		return "The connection to the Git server timed out."
+	case ShallowFetchUnsupported:
+		return "The Git server does not support shallow fetches."
 	}
 
 	return "Git encountered an unknown error."
 }
 
 func (token errorToken) String() string {
+	if token.prefixToken.raw == "" {
+		return token.classToken.String()
+	}
+
 	return token.prefixToken.String() + ": " + token.classToken.String()
 }
 
@@ -136,6 +176,13 @@ func parse(message string) (tokenList []errorToken) {
 	for scanner.Scan() {
 		if token, err := parseLine(scanner.Text()); err == nil {
 			tokenList = append(tokenList, *token)
+			continue
+		}
+
+		// Some Git servers, for example Bitbucket via SSH, report errors without
+		// any prefix, such lines are only considered when they can be classified
+		if token := parseUnprefixedLine(scanner.Text()); token != nil {
+			tokenList = append(tokenList, *token)
 		}
 	}
 
@@ -170,6 +217,19 @@ func parseLine(line string) (*errorToken, error) {
 	return &errorToken{classToken: errorClassToken, prefixToken: prefixToken}, nil
 }
 
+func parseUnprefixedLine(line string) *errorToken {
+	if strings.TrimSpace(line) == "" {
+		return nil
+	}
+
+	errorClassToken := parseErrorMessage(line)
+	if errorClassToken.class == Unknown {
+		return nil
+	}
+
+	return &errorToken{classToken: errorClassToken, prefixToken: prefixToken{unknownPrefix, rawToken{}}}
+}
+
 func parsePrefix(raw string) prefixToken {
 	prefix := unknownPrefix
 
@@ -185,6 +245,77 @@ func parsePrefix(raw string) prefixToken {
 	return prefixToken{prefix, rawToken{raw}}
 }
 
+// hostErrorRule describes an error message of a specific Git server implementation
+type hostErrorRule struct {
+	pattern *regexp.Regexp
+	class   ErrorClass
+}
+
+// hostErrorRules contains the error messages of Git servers other than GitHub and GitLab,
+// the patterns are matched against the lower case error message without its prefix
+var hostErrorRules = []hostErrorRule{
+	// Bitbucket
+	{pattern: regexp.MustCompile(`^invalid credentials`), class: AuthInvalidUserOrPass},
+	{pattern: regexp.MustCompile(`requested repository does not exist`), class: RepositoryNotFound},
+	{pattern: regexp.MustCompile(`^(conq: )?repository does not exist`), class: RepositoryNotFound},
+	{pattern: regexp.MustCompile(`rate limit for this resource has been exceeded`), class: RateLimited},
+	// Gitea and Gogs
+	{pattern: regexp.MustCompile(`either does not exist or you are not authorized`), class: RepositoryNotFound},
+	{pattern: regexp.MustCompile(`repository does not exist or you do not have access`), class: RepositoryNotFound},
+	// Azure DevOps
+	{pattern: regexp.MustCompile(`^tf401019: `), class: RepositoryNotFound},
+	{pattern: regexp.MustCompile(`^tf200016: `), class: RepositoryNotFound},
+	{pattern: regexp.MustCompile(`^tf400813: `), class: AuthInvalidUserOrPass},
+	// git daemon
+	{pattern: regexp.MustCompile(`access denied or repository not exported`), class: RepositoryNotFound},
+}
+
+func classifyHostError(raw string) ErrorClass {
+	for _, rule := range hostErrorRules {
+		if rule.pattern.MatchString(raw) {
+			return rule.class
+		}
+	}
+
+	return Unknown
+}
+
+func isRateLimited(raw string) bool {
+	return strings.Contains(raw, "returned error: 429") ||
+		strings.Contains(raw, "rate limit exceeded") ||
+		strings.Contains(raw, "too many requests")
+}
+
+func isTLSVerificationFailed(raw string) bool {
+	return strings.Contains(raw, "ssl certificate problem") ||
+		strings.Contains(raw, "server certificate verification failed") ||
+		strings.Contains(raw, "certificate verify failed") ||
+		strings.Contains(raw, "ssl: no alternative certificate subject name matches")
+}
+
+func isProxyError(raw string) bool {
+	return strings.Contains(raw, "could not resolve proxy") ||
+		strings.Contains(raw, "from proxy after connect") ||
+		strings.Contains(raw, "proxy connect aborted") ||
+		strings.Contains(raw, "proxy authentication required")
+}
+
+func isHostNotResolved(raw string) bool {
+	return strings.Contains(raw, "could not resolve host") ||
+		strings.Contains(raw, "name or service not known") ||
+		strings.Contains(raw, "temporary failure in name resolution")
+}
+
+func isTimeout(raw string) bool {
+	return strings.Contains(raw, "connection timed out") ||
+		strings.Contains(raw, "operation timed out") ||
+		strings.Contains(raw, "operation too slow")
+}
+
+func isShallowFetchUnsupported(raw string) bool {
+	return strings.Contains(raw, "does not support shallow")
+}
+
 func isAuthInvalidUserOrPass(raw string) bool {
 	return strings.Contains(raw, "authentication failed for") ||
 		strings.Contains(raw, "invalid username or password")
@@ -213,6 +344,18 @@ func parseErrorMessage(raw string) errorClassToken {
 	toCheck := strings.ToLower(strings.TrimSpace(raw))
 
 	switch {
+	case isProxyError(toCheck):
+		errorClass = ProxyError
+	case isTLSVerificationFailed(toCheck):
+		errorClass = TLSVerificationFailed
+	case isHostNotResolved(toCheck):
+		errorClass = HostNotResolved
+	case isTimeout(toCheck):
+		errorClass = Timeout
+	case isRateLimited(toCheck):
+		errorClass = RateLimited
+	case isShallowFetchUnsupported(toCheck):
+		errorClass = ShallowFetchUnsupported
 	case isAuthInvalidUserOrPass(toCheck):
 		errorClass = AuthInvalidUserOrPass
 	case isAuthPrompted(toCheck):
@@ -223,6 +366,8 @@ func parseErrorMessage(raw string) errorClassToken {
 		errorClass = RepositoryNotFound
 	case isBranchNotFound(toCheck):
 		errorClass = RevisionNotFound
+	default:
+		errorClass = classifyHostError(toCheck)
 	}
 
 	return errorClassToken{errorClass, rawToken{
@@ -230,6 +375,19 @@ func parseErrorMessage(raw string) errorClassToken {
 	}}
 }
 
+// classifyTransportTokens looks for connection related errors, these are the root cause
+// independent of the participant that reported them
+func classifyTransportTokens(tokens []errorToken) ErrorClass {
+	for _, token := range tokens {
+		switch token.classToken.class {
+		case ProxyError, TLSVerificationFailed, HostNotResolved, Timeout, RateLimited, ShallowFetchUnsupported:
+			return token.classToken.class
+		}
+	}
+
+	return Unknown
+}
+
 func classifyTokensWithRemotePrefix(tokens []errorToken) ErrorClass {
 	for _, remoteToken := range tokens {
 		switch remoteToken.classToken.class {
@@ -243,6 +401,18 @@ func classifyTokensWithRemotePrefix(tokens []errorToken) ErrorClass {
 	return Unknown
 }
 
+// classifyTokensWithUnknownPrefix handles messages that Git servers write without the
+// remote prefix, for example when the connection uses SSH
+func classifyTokensWithUnknownPrefix(tokens []errorToken) ErrorClass {
+	for _, token := range tokens {
+		if token.classToken.class == RepositoryNotFound {
+			return RepositoryNotFound
+		}
+	}
+
+	return Unknown
+}
+
 func classifyTokensWithErrorPrefix(tokens []errorToken) ErrorClass {
 	for _, remoteToken := range tokens {
 		if remoteToken.classToken.class == RepositoryNotFound {
@@ -264,6 +434,8 @@ func classifyTokensWithFatalPrefix(tokens []errorToken) ErrorClass {
 			return RevisionNotFound
 		case AuthInvalidUserOrPass:
 			return AuthInvalidUserOrPass
+		case RepositoryNotFound:
+			return RepositoryNotFound
 		}
 	}
 
@@ -276,6 +448,10 @@ func classifyErrorFromTokens(tokens []errorToken) ErrorClass {
 		classifierMap[token.prefixToken.scope] = append(classifierMap[token.prefixToken.scope], token)
 	}
 
+	if errorClass := classifyTransportTokens(tokens); errorClass != Unknown {
+		return errorClass
+	}
+
 	if errorClass := classifyTokensWithRemotePrefix(classifierMap[remotePrefix]); errorClass != Unknown {
 		return errorClass
 	}
@@ -284,6 +460,10 @@ func classifyErrorFromTokens(tokens []errorToken) ErrorClass {
 		return errorClass
 	}
 
+	if errorClass := classifyTokensWithUnknownPrefix(classifierMap[unknownPrefix]); errorClass != Unknown {
+		return errorClass
+	}
+
 	if errorClass := classifyTokensWithFatalPrefix(classifierMap[fatalPrefix]); errorClass != Unknown {
 		return errorClass
 	}
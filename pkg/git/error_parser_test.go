@@ -5,6 +5,9 @@
 package git
 
 import (
+	"os"
+	"path/filepath"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
@@ -51,6 +54,38 @@ var _ = Describe("Parsing Git Error Messages", func() {
 			parsed := parseErrorMessage("Repository not found.")
 			Expect(parsed.class).To(Equal(RepositoryNotFound))
 		})
+		It("should recognize rate limiting", func() {
+			parsed := parseErrorMessage("unable to access 'https://gitlab.com/shipwright-io/sample-go.git/': The requested URL returned error: 429")
+			Expect(parsed.class).To(Equal(RateLimited))
+		})
+		It("should recognize failed certificate verification", func() {
+			parsed := parseErrorMessage("unable to access 'https://git.example.com/sample-go.git/': SSL certificate problem: self signed certificate in certificate chain")
+			Expect(parsed.class).To(Equal(TLSVerificationFailed))
+		})
+		It("should recognize unresolvable host names", func() {
+			parsed := parseErrorMessage("Could not resolve hostname git.example.com: Name or service not known")
+			Expect(parsed.class).To(Equal(HostNotResolved))
+		})
+		It("should recognize proxy errors before host resolution errors", func() {
+			parsed := parseErrorMessage("unable to access 'https://github.com/shipwright-io/sample-go.git/': Could not resolve proxy: proxy.example.com")
+			Expect(parsed.class).To(Equal(ProxyError))
+		})
+		It("should recognize connection timeouts", func() {
+			parsed := parseErrorMessage("connect to host git.example.com port 22: Connection timed out")
+			Expect(parsed.class).To(Equal(Timeout))
+		})
+		It("should recognize servers without shallow fetch support", func() {
+			parsed := parseErrorMessage("dumb http transport does not support shallow capabilities")
+			Expect(parsed.class).To(Equal(ShallowFetchUnsupported))
+		})
+		It("should recognize non-existing repo on Azure DevOps", func() {
+			parsed := parseErrorMessage("TF401019: The Git repository with name or identifier sample-go does not exist or you do not have permissions for the operation you are attempting.")
+			Expect(parsed.class).To(Equal(RepositoryNotFound))
+		})
+		It("should recognize invalid credentials on Bitbucket", func() {
+			parsed := parseErrorMessage("Invalid credentials")
+			Expect(parsed.class).To(Equal(AuthInvalidUserOrPass))
+		})
 		It("should not be able to specify exact error class for unknown message type", func() {
 			parsed := parseErrorMessage("Something went wrong")
 			Expect(parsed.class).To(Equal(Unknown))
@@ -63,4 +98,25 @@ var _ = Describe("Parsing Git Error Messages", func() {
 			Expect(errorResult.Reason.String()).To(Equal(RepositoryNotFound.String()))
 		})
 	})
+	Context("Classify error transcripts of different Git hosts", func() {
+		// testdata/errors contains one directory per expected reason, each
+		// file in it is the stderr output of a failed Git command
+		files, err := filepath.Glob(filepath.Join("testdata", "errors", "*", "*.txt"))
+		if err != nil {
+			panic(err)
+		}
+
+		for _, file := range files {
+			file := file
+			expected := filepath.Base(filepath.Dir(file))
+
+			It("classifies "+file+" as "+expected, func() {
+				content, err := os.ReadFile(file)
+				Expect(err).ToNot(HaveOccurred())
+
+				errorResult := NewErrorResultFromMessage(string(content))
+				Expect(errorResult.Reason.String()).To(Equal(expected))
+			})
+		}
+	})
 })
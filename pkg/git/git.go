@@ -7,8 +7,10 @@ package git
 import (
 	"context"
 	"fmt"
+	"regexp"
 
 	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/go-git/go-git/v5/storage/memory"
 
@@ -23,6 +25,9 @@ const (
 	gitProtocol   = "ssh"
 )
 
+// commitSHA matches a full commit SHA
+var commitSHA = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
 // ValidateGitURLExists validate if a source URL exists or not
 // Note: We have an upcoming PR for the Build Status, where we
 // intend to define a single Status.Reason in the form of 'remoteRepositoryUnreachable',
@@ -62,3 +67,59 @@ func ValidateGitURLExists(ctx context.Context, urlPath string) error {
 
 	return nil
 }
+
+// ResolveRevision returns the commit that a revision of a remote repository
+// points to. The revision can be a branch, a tag, or a full reference name,
+// an empty revision stands for the default branch. A full commit SHA is
+// returned as is. Only HTTP and HTTPS URLs are supported.
+func ResolveRevision(ctx context.Context, urlPath string, revision string, auth transport.AuthMethod) (string, error) {
+	if commitSHA.MatchString(revision) {
+		return revision, nil
+	}
+
+	endpoint, err := transport.NewEndpoint(urlPath)
+	if err != nil {
+		return "", err
+	}
+
+	if endpoint.Protocol != httpsProtocol && endpoint.Protocol != httpProtocol {
+		return "", fmt.Errorf("resolving the revision of %s URLs is not supported", endpoint.Protocol)
+	}
+
+	repo := gogitv5.NewRemote(memory.NewStorage(), &config.RemoteConfig{
+		Name: defaultRemote,
+		URLs: []string{urlPath},
+	})
+
+	refs, err := repo.ListContext(ctx, &gogitv5.ListOptions{Auth: auth})
+	if err != nil {
+		return "", err
+	}
+
+	byName := make(map[plumbing.ReferenceName]*plumbing.Reference, len(refs))
+	for _, ref := range refs {
+		byName[ref.Name()] = ref
+	}
+
+	candidates := []plumbing.ReferenceName{plumbing.HEAD}
+	if revision != "" {
+		candidates = []plumbing.ReferenceName{
+			plumbing.ReferenceName(revision),
+			plumbing.NewBranchReferenceName(revision),
+			plumbing.NewTagReferenceName(revision),
+		}
+	}
+
+	for _, candidate := range candidates {
+		ref, ok := byName[candidate]
+		if ok && ref.Type() == plumbing.SymbolicReference {
+			ref, ok = byName[ref.Target()]
+		}
+
+		if ok {
+			return ref.Hash().String(), nil
+		}
+	}
+
+	return "", fmt.Errorf("revision %q not found in %s", revision, urlPath)
+}
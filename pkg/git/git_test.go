@@ -7,7 +7,13 @@ package git_test
 import (
 	"context"
 	"errors"
+	"net/http"
+	"net/http/httptest"
 
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/pktline"
+	"github.com/go-git/go-git/v5/plumbing/protocol/packp"
+	"github.com/go-git/go-git/v5/plumbing/protocol/packp/capability"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
@@ -15,6 +21,33 @@ import (
 	"github.com/shipwright-io/build/pkg/git"
 )
 
+const (
+	mainSHA = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+	tagSHA  = "e69de29bb2d1d6434b8b29ae775ad8c2e48c5391"
+)
+
+// newRepositoryServer returns a server that advertises the references of a
+// repository with a main branch and a v1.0.0 tag over the smart HTTP protocol
+func newRepositoryServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/repository.git/info/refs" || req.URL.Query().Get("service") != "git-upload-pack" {
+			http.NotFound(w, req)
+			return
+		}
+
+		head := plumbing.NewHash(mainSHA)
+		advRefs := packp.NewAdvRefs()
+		advRefs.Prefix = [][]byte{[]byte("# service=git-upload-pack"), pktline.Flush}
+		advRefs.Head = &head
+		Expect(advRefs.Capabilities.Set(capability.SymRef, "HEAD:refs/heads/main")).To(Succeed())
+		advRefs.References["refs/heads/main"] = head
+		advRefs.References["refs/tags/v1.0.0"] = plumbing.NewHash(tagSHA)
+
+		w.Header().Set("Content-Type", "application/x-git-upload-pack-advertisement")
+		Expect(advRefs.Encode(w)).To(Succeed())
+	}))
+}
+
 var _ = Describe("Git", func() {
 
 	DescribeTable("the source url validation errors",
@@ -27,4 +60,42 @@ var _ = Describe("Git", func() {
 		Entry("Check git repository which requires authentication", "git@github.com:shipwright-io/build-fake.git", Equal(errors.New("the source url requires authentication"))),
 		Entry("Check ssh repository which requires authentication", "ssh://github.com/shipwright-io/build-fake", Equal(errors.New("the source url requires authentication"))),
 	)
+
+	Context("resolving a revision", func() {
+		It("returns a full commit SHA as is", func() {
+			Expect(git.ResolveRevision(context.TODO(), "git@github.com:shipwright-io/build.git", "0c3e2d4c0c4e3bb2bc3c1d55b4de10c46d9b3e0f", nil)).To(Equal("0c3e2d4c0c4e3bb2bc3c1d55b4de10c46d9b3e0f"))
+		})
+
+		It("does not support SSH URLs", func() {
+			_, err := git.ResolveRevision(context.TODO(), "git@github.com:shipwright-io/build.git", "main", nil)
+			Expect(err).To(MatchError("resolving the revision of ssh URLs is not supported"))
+		})
+
+		Context("of a repository", func() {
+			var server *httptest.Server
+
+			BeforeEach(func() {
+				server = newRepositoryServer()
+			})
+
+			AfterEach(func() {
+				server.Close()
+			})
+
+			DescribeTable("resolves the revision to the commit",
+				func(revision string, expected string) {
+					Expect(git.ResolveRevision(context.TODO(), server.URL+"/repository.git", revision, nil)).To(Equal(expected))
+				},
+				Entry("a branch", "main", mainSHA),
+				Entry("a full reference name", "refs/heads/main", mainSHA),
+				Entry("a tag", "v1.0.0", tagSHA),
+				Entry("the default branch", "", mainSHA),
+			)
+
+			It("fails for an unknown revision", func() {
+				_, err := git.ResolveRevision(context.TODO(), server.URL+"/repository.git", "unknown", nil)
+				Expect(err).To(MatchError(ContainSubstring(`revision "unknown" not found`)))
+			})
+		})
+	})
 })
@@ -0,0 +1,108 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// PushArtifact pushes an OCI artifact with a single layer that holds the
+// content, and returns the reference of the artifact with its digest. The
+// media type of the artifact is set as the media type of its config. The
+// credentials are the content of a .dockerconfigjson file. The content is
+// buffered in a temporary file, because its digest is needed before the upload.
+func PushArtifact(ctx context.Context, reference string, artifactType types.MediaType, layerType types.MediaType, content io.Reader, dockerConfigJSON []byte) (string, error) {
+	ref, err := name.ParseReference(reference)
+	if err != nil {
+		return "", err
+	}
+
+	auth, err := authenticator(ref, dockerConfigJSON)
+	if err != nil {
+		return "", err
+	}
+
+	layer, err := newFileLayer(content, layerType)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(layer.path)
+
+	artifact, err := mutate.AppendLayers(mutate.MediaType(empty.Image, types.OCIManifestSchema1), layer)
+	if err != nil {
+		return "", err
+	}
+
+	artifact = mutate.ConfigMediaType(artifact, artifactType)
+
+	if err := remote.Write(ref, artifact, remote.WithContext(ctx), remote.WithAuth(auth)); err != nil {
+		return "", err
+	}
+
+	digest, err := artifact.Digest()
+	if err != nil {
+		return "", err
+	}
+
+	return ref.Context().Digest(digest.String()).String(), nil
+}
+
+// fileLayer is an uncompressed layer whose content is stored in a file
+type fileLayer struct {
+	path      string
+	digest    v1.Hash
+	size      int64
+	mediaType types.MediaType
+}
+
+// newFileLayer writes the content into a temporary file, the caller removes the file
+func newFileLayer(content io.Reader, mediaType types.MediaType) (*fileLayer, error) {
+	file, err := os.CreateTemp("", "artifact-")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	digest, size, err := v1.SHA256(io.TeeReader(content, file))
+	if err != nil {
+		os.Remove(file.Name())
+		return nil, err
+	}
+
+	return &fileLayer{path: file.Name(), digest: digest, size: size, mediaType: mediaType}, nil
+}
+
+func (l *fileLayer) Digest() (v1.Hash, error) {
+	return l.digest, nil
+}
+
+func (l *fileLayer) DiffID() (v1.Hash, error) {
+	return l.digest, nil
+}
+
+func (l *fileLayer) Compressed() (io.ReadCloser, error) {
+	return os.Open(l.path)
+}
+
+func (l *fileLayer) Uncompressed() (io.ReadCloser, error) {
+	return os.Open(l.path)
+}
+
+func (l *fileLayer) Size() (int64, error) {
+	return l.size, nil
+}
+
+func (l *fileLayer) MediaType() (types.MediaType, error) {
+	return l.mediaType, nil
+}
@@ -0,0 +1,63 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+
+	"github.com/shipwright-io/build/pkg/image"
+)
+
+var _ = Describe("PushArtifact", func() {
+	var server *httptest.Server
+
+	BeforeEach(func() {
+		server = httptest.NewServer(registry.New())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("pushes the content as the single layer of an artifact", func() {
+		reference := fmt.Sprintf("%s/some/image:sha256-abc.logs", strings.TrimPrefix(server.URL, "http://"))
+
+		pushed, err := image.PushArtifact(context.TODO(), reference, "application/vnd.example.config.v1+json", types.MediaType("text/plain"), strings.NewReader("some logs"), nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pushed).To(HavePrefix(strings.TrimSuffix(reference, ":sha256-abc.logs") + "@sha256:"))
+
+		ref, err := name.ParseReference(reference)
+		Expect(err).ToNot(HaveOccurred())
+
+		artifact, err := remote.Image(ref)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(artifact.MediaType()).To(Equal(types.OCIManifestSchema1))
+
+		manifest, err := artifact.Manifest()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(manifest.Config.MediaType).To(Equal(types.MediaType("application/vnd.example.config.v1+json")))
+
+		layers, err := artifact.Layers()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(layers).To(HaveLen(1))
+
+		content, err := layers[0].Uncompressed()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(io.ReadAll(content)).To(Equal([]byte("some logs")))
+	})
+})
@@ -0,0 +1,33 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"context"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+
+	"github.com/shipwright-io/build/pkg/bundle"
+)
+
+// DeleteImage deletes the tag and the manifest of an image from the registry,
+// the same way as the bundle step prunes a bundle image. The digest is the one
+// of the manifest that the reference pointed to when it was pulled. The
+// credentials are the content of a .dockerconfigjson file. The error wraps
+// bundle.ErrDeletionUnsupported if the registry does not delete images.
+func DeleteImage(ctx context.Context, reference string, digest string, dockerConfigJSON []byte) error {
+	ref, err := name.ParseReference(reference)
+	if err != nil {
+		return err
+	}
+
+	auth, err := authenticator(ref, dockerConfigJSON)
+	if err != nil {
+		return err
+	}
+
+	return bundle.Prune(ref, ref.Context().Digest(digest), remote.WithContext(ctx), remote.WithAuth(auth))
+}
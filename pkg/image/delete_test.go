@@ -0,0 +1,52 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image_test
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+
+	"github.com/shipwright-io/build/pkg/image"
+)
+
+var _ = Describe("DeleteImage", func() {
+	var (
+		server   *httptest.Server
+		imageRef string
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(registry.New())
+		imageRef = fmt.Sprintf("%s/some/image:latest", strings.TrimPrefix(server.URL, "http://"))
+
+		ref, err := name.ParseReference(imageRef)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(remote.Write(ref, empty.Image)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("deletes the tag and the manifest of an image", func() {
+		digest, err := empty.Image.Digest()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(image.DeleteImage(context.TODO(), imageRef, digest.String(), nil)).To(Succeed())
+
+		_, err = image.ResolveDigest(context.TODO(), imageRef, nil)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,75 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/docker/cli/cli/config"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+// ResolveDigest returns the image reference with the digest that it points
+// to, for example registry.example.com/some/image@sha256:<hex>. A reference
+// that contains a digest is returned as is. The credentials are the content
+// of a .dockerconfigjson file, without credentials the registry is accessed
+// anonymously.
+func ResolveDigest(ctx context.Context, reference string, dockerConfigJSON []byte) (string, error) {
+	ref, err := name.ParseReference(reference)
+	if err != nil {
+		return "", err
+	}
+
+	if digest, ok := ref.(name.Digest); ok {
+		return digest.String(), nil
+	}
+
+	auth, err := authenticator(ref, dockerConfigJSON)
+	if err != nil {
+		return "", err
+	}
+
+	descriptor, err := remote.Head(ref, remote.WithContext(ctx), remote.WithAuth(auth))
+	if err != nil {
+		return "", err
+	}
+
+	return ref.Context().Digest(descriptor.Digest.String()).String(), nil
+}
+
+// authenticator returns the credentials for the registry of a reference from
+// the content of a .dockerconfigjson file, without credentials the registry
+// is accessed anonymously
+func authenticator(ref name.Reference, dockerConfigJSON []byte) (authn.Authenticator, error) {
+	if len(dockerConfigJSON) == 0 {
+		return authn.Anonymous, nil
+	}
+
+	configFile, err := config.LoadFromReader(bytes.NewReader(dockerConfigJSON))
+	if err != nil {
+		return nil, err
+	}
+
+	registryName := ref.Context().RegistryStr()
+	if registryName == name.DefaultRegistry {
+		registryName = authn.DefaultAuthKey
+	}
+
+	authConfig, err := configFile.GetAuthConfig(registryName)
+	if err != nil {
+		return nil, err
+	}
+
+	return authn.FromConfig(authn.AuthConfig{
+		Username:      authConfig.Username,
+		Password:      authConfig.Password,
+		Auth:          authConfig.Auth,
+		IdentityToken: authConfig.IdentityToken,
+		RegistryToken: authConfig.RegistryToken,
+	}), nil
+}
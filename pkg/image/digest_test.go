@@ -0,0 +1,59 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image_test
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+
+	"github.com/shipwright-io/build/pkg/image"
+)
+
+var _ = Describe("ResolveDigest", func() {
+	var (
+		server   *httptest.Server
+		imageRef string
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(registry.New())
+		imageRef = fmt.Sprintf("%s/some/image:latest", strings.TrimPrefix(server.URL, "http://"))
+
+		ref, err := name.ParseReference(imageRef)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(remote.Write(ref, empty.Image)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("resolves the digest of a tag", func() {
+		digest, err := empty.Image.Digest()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(image.ResolveDigest(context.TODO(), imageRef, nil)).To(Equal(strings.TrimSuffix(imageRef, ":latest") + "@" + digest.String()))
+	})
+
+	It("returns a reference with a digest as is", func() {
+		reference := "registry.example.com/some/image@sha256:0d6b4c3e6fc0a1f4ea9ef1c3e88b5fcbd2a1b6e4f6f39c6cd4c8a5b1b0a3e7f2"
+		Expect(image.ResolveDigest(context.TODO(), reference, nil)).To(Equal(reference))
+	})
+
+	It("fails for an image that does not exist", func() {
+		_, err := image.ResolveDigest(context.TODO(), strings.TrimSuffix(imageRef, ":latest")+":missing", nil)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,46 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// NewTransport returns a HTTP transport for registry operations that uses the
+// proxy settings of the environment, and trusts the certificates of the
+// provided PEM encoded CA bundle file in addition to the system certificates
+func NewTransport(caBundlePath string) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	transport.TLSClientConfig = &tls.Config{
+		InsecureSkipVerify: false,
+		MinVersion:         tls.VersionTLS12,
+	}
+
+	if caBundlePath == "" {
+		return transport, nil
+	}
+
+	rootCAs, err := x509.SystemCertPool()
+	if err != nil || rootCAs == nil {
+		rootCAs = x509.NewCertPool()
+	}
+
+	data, err := ioutil.ReadFile(caBundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("reading CA bundle %s: %w", caBundlePath, err)
+	}
+
+	if !rootCAs.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no PEM encoded certificates found in CA bundle %s", caBundlePath)
+	}
+
+	transport.TLSClientConfig.RootCAs = rootCAs
+	return transport, nil
+}
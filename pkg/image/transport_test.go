@@ -0,0 +1,80 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image_test
+
+import (
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/shipwright-io/build/pkg/image"
+)
+
+var _ = Describe("NewTransport", func() {
+	var (
+		server  *httptest.Server
+		tempDir string
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		var err error
+		tempDir, err = ioutil.TempDir("", "ca-bundle")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		server.Close()
+		os.RemoveAll(tempDir)
+	})
+
+	get := func(transport http.RoundTripper) error {
+		resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	It("should not trust a server with a private certificate by default", func() {
+		transport, err := image.NewTransport("")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(get(transport)).To(HaveOccurred())
+	})
+
+	It("should trust a server whose certificate is in the CA bundle", func() {
+		caBundle := filepath.Join(tempDir, "ca-bundle.crt")
+		Expect(ioutil.WriteFile(caBundle, pem.EncodeToMemory(&pem.Block{
+			Type:  "CERTIFICATE",
+			Bytes: server.Certificate().Raw,
+		}), 0644)).To(Succeed())
+
+		transport, err := image.NewTransport(caBundle)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(get(transport)).To(Succeed())
+	})
+
+	It("should fail for a CA bundle without certificates", func() {
+		caBundle := filepath.Join(tempDir, "ca-bundle.crt")
+		Expect(ioutil.WriteFile(caBundle, []byte("no certificate"), 0644)).To(Succeed())
+
+		_, err := image.NewTransport(caBundle)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should fail for a CA bundle that does not exist", func() {
+		_, err := image.NewTransport(filepath.Join(tempDir, "does-not-exist"))
+		Expect(err).To(HaveOccurred())
+	})
+})
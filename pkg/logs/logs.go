@@ -0,0 +1,172 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package logs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	buildv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	"github.com/shipwright-io/build/pkg/config"
+)
+
+// maxContainerLogBytes limits the logs that are archived for each step
+const maxContainerLogBytes int64 = 10 * 1024 * 1024
+
+// Sink stores the logs of the steps of a BuildRun
+type Sink interface {
+	// Name returns the name of the sink as it is configured
+	Name() string
+
+	// Store stores the logs of the TaskRun of a BuildRun, which it reads
+	// until the end, and returns the reference of the stored logs
+	Store(ctx context.Context, buildRun *buildv1alpha1.BuildRun, taskRunName string, logs io.Reader) (string, error)
+}
+
+// LogReader reads the logs of a container of a pod, and if follow is set,
+// streams them until the container terminates
+type LogReader interface {
+	ReadLogs(ctx context.Context, namespace string, pod string, container string, follow bool) (io.ReadCloser, error)
+}
+
+// Archiver collects the logs of the steps of the TaskRun of a BuildRun and
+// stores them in a sink
+type Archiver struct {
+	sink   Sink
+	reader LogReader
+}
+
+// NewArchiver returns an Archiver that reads the logs with the reader and
+// stores them in the sink
+func NewArchiver(sink Sink, reader LogReader) *Archiver {
+	return &Archiver{sink: sink, reader: reader}
+}
+
+// NewArchiverFromConfig returns the Archiver for the sink that is configured
+// for the logs of BuildRuns, or nil if the archive is disabled
+func NewArchiverFromConfig(c *config.Config, restConfig *rest.Config, client client.Client) (*Archiver, error) {
+	var sink Sink
+	switch c.BuildRunLogs.Sink {
+	case "":
+		return nil, nil
+
+	case config.LogsSinkPVC:
+		if c.BuildRunLogs.Directory == "" {
+			return nil, fmt.Errorf("the %s sink for the logs of BuildRuns requires a directory", config.LogsSinkPVC)
+		}
+
+		sink = NewPVCSink(c.BuildRunLogs.Directory)
+
+	case config.LogsSinkOCI:
+		sink = NewOCISink(client)
+
+	case config.LogsSinkS3:
+		if c.BuildRunLogs.S3.Endpoint == "" || c.BuildRunLogs.S3.Bucket == "" {
+			return nil, fmt.Errorf("the %s sink for the logs of BuildRuns requires an endpoint and a bucket", config.LogsSinkS3)
+		}
+
+		sink = NewS3Sink(c.BuildRunLogs.S3, http.DefaultClient)
+
+	default:
+		return nil, fmt.Errorf("unknown sink %q for the logs of BuildRuns", c.BuildRunLogs.Sink)
+	}
+
+	reader, err := NewPodLogReaderForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewArchiver(sink, reader), nil
+}
+
+// Archive collects the logs of the steps of the TaskRun and stores them in
+// the sink, the logs of a step that can not be read are replaced by a note.
+// The logs are streamed into the sink, one step after the other.
+func (a *Archiver) Archive(ctx context.Context, buildRun *buildv1alpha1.BuildRun, taskRun *pipeline.TaskRun) (*buildv1alpha1.BuildRunLogs, error) {
+	if taskRun.Status.PodName == "" {
+		return nil, fmt.Errorf("TaskRun %s has no pod", taskRun.Name)
+	}
+
+	reader, writer := io.Pipe()
+	go func() {
+		for _, step := range taskRun.Status.Steps {
+			if _, err := fmt.Fprintf(writer, "==> %s <==\n", step.Name); err != nil {
+				return
+			}
+			if err := a.copyLogs(ctx, writer, taskRun.Namespace, taskRun.Status.PodName, step.ContainerName); err != nil {
+				if _, err := fmt.Fprintf(writer, "the logs of container %s are not available: %v\n", step.ContainerName, err); err != nil {
+					return
+				}
+			}
+		}
+		writer.Close()
+	}()
+
+	reference, err := a.sink.Store(ctx, buildRun, taskRun.Name, reader)
+
+	// stops the writer in case the sink did not read all logs
+	reader.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &buildv1alpha1.BuildRunLogs{Sink: a.sink.Name(), Reference: reference}, nil
+}
+
+func (a *Archiver) copyLogs(ctx context.Context, w io.Writer, namespace string, pod string, container string) error {
+	stream, err := a.reader.ReadLogs(ctx, namespace, pod, container, false)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	_, err = io.Copy(w, stream)
+	return err
+}
+
+type podLogReader struct {
+	clientset kubernetes.Interface
+}
+
+// NewPodLogReader returns a LogReader that reads the logs through the
+// Kubernetes API
+func NewPodLogReader(clientset kubernetes.Interface) LogReader {
+	return &podLogReader{clientset: clientset}
+}
+
+// NewPodLogReaderForConfig returns a LogReader that reads the logs through
+// the Kubernetes API of the configuration
+func NewPodLogReaderForConfig(restConfig *rest.Config) (LogReader, error) {
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPodLogReader(clientset), nil
+}
+
+func (r *podLogReader) ReadLogs(ctx context.Context, namespace string, pod string, container string, follow bool) (io.ReadCloser, error) {
+	options := &corev1.PodLogOptions{
+		Container: container,
+		Follow:    follow,
+	}
+
+	// the archive is limited, the stream is not
+	if !follow {
+		limitBytes := maxContainerLogBytes
+		options.LimitBytes = &limitBytes
+	}
+
+	return r.clientset.CoreV1().Pods(namespace).GetLogs(pod, options).Stream(ctx)
+}
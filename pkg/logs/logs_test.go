@@ -0,0 +1,117 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package logs_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	buildv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	"github.com/shipwright-io/build/pkg/logs"
+)
+
+type fakeLogReader map[string]string
+
+func (r fakeLogReader) ReadLogs(_ context.Context, _ string, _ string, container string, _ bool) (io.ReadCloser, error) {
+	content, ok := r[container]
+	if !ok {
+		return nil, errors.New("container not found")
+	}
+
+	return io.NopCloser(strings.NewReader(content)), nil
+}
+
+type fakeSink struct {
+	taskRunName string
+	logs        string
+	err         error
+}
+
+func (s *fakeSink) Name() string {
+	return "fake"
+}
+
+func (s *fakeSink) Store(_ context.Context, _ *buildv1alpha1.BuildRun, taskRunName string, logs io.Reader) (string, error) {
+	// a failing sink stops reading the logs
+	if s.err != nil {
+		return "", s.err
+	}
+
+	content, err := io.ReadAll(logs)
+	if err != nil {
+		return "", err
+	}
+
+	s.taskRunName = taskRunName
+	s.logs = string(content)
+	return "fake://" + taskRunName, nil
+}
+
+var _ = Describe("Archiver", func() {
+	var (
+		buildRun *buildv1alpha1.BuildRun
+		taskRun  *pipeline.TaskRun
+		sink     *fakeSink
+	)
+
+	BeforeEach(func() {
+		buildRun = &buildv1alpha1.BuildRun{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "buildrun"}}
+		taskRun = &pipeline.TaskRun{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "buildrun-abcde"}}
+		taskRun.Status.PodName = "buildrun-abcde-pod"
+		taskRun.Status.Steps = []pipeline.StepState{
+			{Name: "source-default", ContainerName: "step-source-default"},
+			{Name: "build-and-push", ContainerName: "step-build-and-push"},
+		}
+		sink = &fakeSink{}
+	})
+
+	It("stores the logs of all steps in the sink", func() {
+		archiver := logs.NewArchiver(sink, fakeLogReader{
+			"step-source-default": "cloned\n",
+			"step-build-and-push": "pushed\n",
+		})
+
+		result, err := archiver.Archive(context.TODO(), buildRun, taskRun)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(Equal(&buildv1alpha1.BuildRunLogs{Sink: "fake", Reference: "fake://buildrun-abcde"}))
+		Expect(sink.taskRunName).To(Equal("buildrun-abcde"))
+		Expect(sink.logs).To(Equal("==> source-default <==\ncloned\n==> build-and-push <==\npushed\n"))
+	})
+
+	It("stores a note for the logs of a step that are not available", func() {
+		archiver := logs.NewArchiver(sink, fakeLogReader{
+			"step-build-and-push": "pushed\n",
+		})
+
+		_, err := archiver.Archive(context.TODO(), buildRun, taskRun)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sink.logs).To(ContainSubstring("the logs of container step-source-default are not available: container not found"))
+		Expect(sink.logs).To(ContainSubstring("pushed\n"))
+	})
+
+	It("fails if the sink fails before it read the logs", func() {
+		sink.err = errors.New("sink unavailable")
+
+		_, err := logs.NewArchiver(sink, fakeLogReader{
+			"step-source-default": strings.Repeat("cloned\n", 100000),
+		}).Archive(context.TODO(), buildRun, taskRun)
+		Expect(err).To(MatchError("sink unavailable"))
+	})
+
+	It("fails if the TaskRun has no pod", func() {
+		taskRun.Status.PodName = ""
+
+		_, err := logs.NewArchiver(sink, fakeLogReader{}).Archive(context.TODO(), buildRun, taskRun)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,79 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package logs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	buildv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	"github.com/shipwright-io/build/pkg/config"
+	"github.com/shipwright-io/build/pkg/image"
+)
+
+const (
+	// artifactType is the media type of the config of the OCI artifact
+	artifactType = "application/vnd.shipwright.buildrun.logs.config.v1+json"
+
+	// layerType is the media type of the layer of the OCI artifact
+	layerType = "text/plain"
+)
+
+type ociSink struct {
+	client client.Client
+}
+
+// NewOCISink returns a Sink that pushes the logs as an OCI artifact into the
+// repository of the output image, using the credentials of the output. The
+// artifact is tagged like the digest of the image with the suffix .logs, or
+// like the TaskRun if the digest is unknown.
+func NewOCISink(client client.Client) Sink {
+	return &ociSink{client: client}
+}
+
+func (s *ociSink) Name() string {
+	return config.LogsSinkOCI
+}
+
+func (s *ociSink) Store(ctx context.Context, buildRun *buildv1alpha1.BuildRun, taskRunName string, logs io.Reader) (string, error) {
+	var output *buildv1alpha1.Image
+	switch {
+	case buildRun.Spec.Output != nil:
+		output = buildRun.Spec.Output
+	case buildRun.Status.BuildSpec != nil:
+		output = &buildRun.Status.BuildSpec.Output
+	default:
+		return "", fmt.Errorf("the output image of BuildRun %s is unknown", buildRun.Name)
+	}
+
+	ref, err := name.ParseReference(output.Image)
+	if err != nil {
+		return "", err
+	}
+
+	tag := taskRunName + ".logs"
+	if buildRun.Status.Output != nil && buildRun.Status.Output.Digest != "" {
+		tag = strings.Replace(buildRun.Status.Output.Digest, ":", "-", 1) + ".logs"
+	}
+
+	var dockerConfigJSON []byte
+	if output.Credentials != nil {
+		secret := &corev1.Secret{}
+		if err := s.client.Get(ctx, types.NamespacedName{Namespace: buildRun.Namespace, Name: output.Credentials.Name}, secret); err != nil {
+			return "", err
+		}
+
+		dockerConfigJSON = secret.Data[corev1.DockerConfigJsonKey]
+	}
+
+	return image.PushArtifact(ctx, ref.Context().Tag(tag).String(), artifactType, layerType, logs, dockerConfigJSON)
+}
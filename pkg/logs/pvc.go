@@ -0,0 +1,54 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package logs
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+
+	buildv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	"github.com/shipwright-io/build/pkg/config"
+)
+
+type pvcSink struct {
+	directory string
+}
+
+// NewPVCSink returns a Sink that stores the logs as files in a directory in
+// which a PersistentVolumeClaim is mounted, the files are organized by the
+// namespace and the name of the BuildRun
+func NewPVCSink(directory string) Sink {
+	return &pvcSink{directory: directory}
+}
+
+func (s *pvcSink) Name() string {
+	return config.LogsSinkPVC
+}
+
+func (s *pvcSink) Store(_ context.Context, buildRun *buildv1alpha1.BuildRun, taskRunName string, logs io.Reader) (string, error) {
+	directory := filepath.Join(s.directory, buildRun.Namespace, buildRun.Name)
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(directory, taskRunName+".log")
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := io.Copy(file, logs); err != nil {
+		file.Close()
+		return "", err
+	}
+
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
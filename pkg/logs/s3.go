@@ -0,0 +1,158 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package logs
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	buildv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	"github.com/shipwright-io/build/pkg/config"
+)
+
+const (
+	amzDateFormat  = "20060102T150405Z"
+	amzShortFormat = "20060102"
+)
+
+type s3Sink struct {
+	config     config.S3Config
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// NewS3Sink returns a Sink that uploads the logs into a bucket of an
+// S3-compatible endpoint, the objects are organized by the namespace and the
+// name of the BuildRun
+func NewS3Sink(c config.S3Config, httpClient *http.Client) Sink {
+	return &s3Sink{config: c, httpClient: httpClient, now: time.Now}
+}
+
+func (s *s3Sink) Name() string {
+	return config.LogsSinkS3
+}
+
+func (s *s3Sink) Store(ctx context.Context, buildRun *buildv1alpha1.BuildRun, taskRunName string, logs io.Reader) (string, error) {
+	key := path.Join(buildRun.Namespace, buildRun.Name, taskRunName+".log")
+
+	// the signature covers the hash of the payload, and S3 requires its length,
+	// the logs are therefore buffered in a temporary file
+	file, err := os.CreateTemp("", "buildrun-logs-")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	payloadHash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(file, payloadHash), logs)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(s.config.Endpoint, "/"), s.config.Bucket, key), io.NopCloser(file))
+	if err != nil {
+		return "", err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "text/plain")
+
+	signV4(req, hex.EncodeToString(payloadHash.Sum(nil)), s.config, s.now())
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("failed to upload the logs to %s: %s: %s", s.config.Endpoint, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	return fmt.Sprintf("s3://%s/%s", s.config.Bucket, key), nil
+}
+
+// signV4 signs the request with the AWS Signature Version 4 for the S3
+// service, all headers of the request and the host are signed
+func signV4(req *http.Request, payloadHash string, c config.S3Config, now time.Time) {
+	amzDate := now.UTC().Format(amzDateFormat)
+	scope := fmt.Sprintf("%s/%s/s3/aws4_request", now.UTC().Format(amzShortFormat), c.Region)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+
+	headers := map[string]string{"host": host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&canonicalHeaders, "%s:%s\n", name, headers[name])
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalURI := req.URL.EscapedPath()
+	if canonicalURI == "" {
+		canonicalURI = "/"
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURI,
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	canonicalRequestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hex.EncodeToString(canonicalRequestHash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+c.SecretAccessKey), now.UTC().Format(amzShortFormat))
+	key = hmacSHA256(key, c.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", c.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,301 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package logs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	buildv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	"github.com/shipwright-io/build/pkg/auth"
+	"github.com/shipwright-io/build/pkg/config"
+	"github.com/shipwright-io/build/pkg/ctxlog"
+)
+
+// stepContainerPrefix is the prefix of the names of the step containers that
+// Tekton creates in the pod of a TaskRun
+const stepContainerPrefix = "step-"
+
+// readHeaderTimeout limits the time a client has to send the headers of its
+// request, the response has no write timeout because the logs are streamed
+// until the BuildRun completed
+const readHeaderTimeout = 10 * time.Second
+
+// logPath matches the path of the logs of a BuildRun, for example
+// /namespaces/default/buildruns/sample-build-run/log
+var logPath = regexp.MustCompile(`^/namespaces/([^/]+)/buildruns/([^/]+)/log$`)
+
+// Server is the endpoint of the controller that streams the logs of the steps
+// of a BuildRun in the order in which they run. Users need to be allowed to
+// get the log subresource of the BuildRun, instead of to know and to read the
+// pod of its TaskRun.
+type Server struct {
+	// PollInterval is the interval in which the pod is checked for whether
+	// the next step started
+	PollInterval time.Duration
+
+	config     config.LogsServerConfig
+	client     client.Client
+	authorizer auth.Authorizer
+	reader     LogReader
+}
+
+// NewServer returns the log streaming endpoint for the configuration
+func NewServer(cfg *config.Config, client client.Client, authorizer auth.Authorizer, reader LogReader) *Server {
+	return &Server{
+		PollInterval: time.Second,
+		config:       cfg.LogsServer,
+		client:       client,
+		authorizer:   authorizer,
+		reader:       reader,
+	}
+}
+
+// Start serves the log streaming endpoint until the context is done
+func (s *Server) Start(ctx context.Context) error {
+	ctx = ctxlog.NewContext(ctx, "logs")
+
+	server := &http.Server{
+		Addr:              s.config.Address,
+		Handler:           s,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = server.Shutdown(context.Background())
+	}()
+
+	ctxlog.Info(ctx, "starting the log streaming endpoint", "address", s.config.Address)
+
+	var err error
+	switch {
+	case s.config.TLSCertFile != "":
+		err = server.ListenAndServeTLS(s.config.TLSCertFile, s.config.TLSKeyFile)
+	case s.config.Insecure:
+		ctxlog.Info(ctx, "the log streaming endpoint serves plain HTTP, bearer tokens are sent unencrypted")
+		err = server.ListenAndServe()
+	default:
+		return errors.New("the log streaming endpoint requires a TLS certificate and key")
+	}
+
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+
+	return err
+}
+
+// NeedLeaderElection returns false, so that every replica of the controller
+// streams logs
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	match := logPath.FindStringSubmatch(req.URL.Path)
+	if match == nil {
+		http.NotFound(w, req)
+		return
+	}
+
+	if req.Method != http.MethodGet {
+		http.Error(w, "only GET requests are supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	namespace, name := match[1], match[2]
+
+	token, ok := auth.BearerToken(req)
+	if !ok {
+		http.Error(w, "a bearer token is required", http.StatusUnauthorized)
+		return
+	}
+
+	user, err := s.authorizer.Authorize(ctx, token, namespace, name)
+	switch {
+	case errors.Is(err, auth.ErrUnauthenticated):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+
+	case errors.Is(err, auth.ErrForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+
+	case err != nil:
+		ctxlog.Error(ctx, err, "failed to authorize the log request", "namespace", namespace, "name", name)
+		http.Error(w, "failed to authorize the log request", http.StatusInternalServerError)
+		return
+	}
+
+	pod, status, err := s.pod(ctx, namespace, name)
+	if err != nil {
+		if status == http.StatusInternalServerError {
+			ctxlog.Error(ctx, err, "failed to find the pod of the BuildRun", "namespace", namespace, "name", name)
+		}
+
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	ctxlog.Info(ctx, "streaming the logs of the BuildRun", "namespace", namespace, "name", name, "user", user)
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+
+	s.stream(ctx, newFlushWriter(w), pod)
+}
+
+// pod returns the pod of the latest TaskRun of a BuildRun, or an error
+// together with the HTTP status that describes it
+func (s *Server) pod(ctx context.Context, namespace string, name string) (*corev1.Pod, int, error) {
+	buildRun := &buildv1alpha1.BuildRun{}
+	if err := s.client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, buildRun); err != nil {
+		return notFoundOrError(err, "BuildRun %s not found", name)
+	}
+
+	if buildRun.Status.LatestTaskRunRef == nil {
+		return nil, http.StatusConflict, fmt.Errorf("BuildRun %s has not started yet", name)
+	}
+
+	taskRun := &pipelinev1beta1.TaskRun{}
+	if err := s.client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: *buildRun.Status.LatestTaskRunRef}, taskRun); err != nil {
+		return notFoundOrError(err, "TaskRun %s not found", *buildRun.Status.LatestTaskRunRef)
+	}
+
+	if taskRun.Status.PodName == "" {
+		return nil, http.StatusConflict, fmt.Errorf("BuildRun %s has not started yet", name)
+	}
+
+	pod := &corev1.Pod{}
+	if err := s.client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: taskRun.Status.PodName}, pod); err != nil {
+		// the logs of a BuildRun whose pod was removed may be archived
+		if apierrors.IsNotFound(err) && buildRun.Status.Logs != nil {
+			return nil, http.StatusGone, fmt.Errorf("Pod %s no longer exists, the logs are archived in the %s sink at %s", taskRun.Status.PodName, buildRun.Status.Logs.Sink, buildRun.Status.Logs.Reference)
+		}
+
+		return notFoundOrError(err, "Pod %s not found", taskRun.Status.PodName)
+	}
+
+	return pod, http.StatusOK, nil
+}
+
+// stream writes the logs of the step containers of the pod in their order,
+// every step starts with a "==> <step> <==" line. It waits for every step to
+// start, and follows its logs until it terminates.
+func (s *Server) stream(ctx context.Context, w io.Writer, pod *corev1.Pod) {
+	for _, container := range pod.Spec.Containers {
+		if !strings.HasPrefix(container.Name, stepContainerPrefix) {
+			continue
+		}
+
+		fmt.Fprintf(w, "==> %s <==\n", strings.TrimPrefix(container.Name, stepContainerPrefix))
+
+		started, err := s.waitForContainer(ctx, pod.Namespace, pod.Name, container.Name)
+		switch {
+		case ctx.Err() != nil:
+			return
+
+		case err != nil:
+			fmt.Fprintf(w, "the logs of container %s are not available: %v\n", container.Name, err)
+			continue
+
+		case !started:
+			fmt.Fprintf(w, "container %s did not run\n", container.Name)
+			continue
+		}
+
+		stream, err := s.reader.ReadLogs(ctx, pod.Namespace, pod.Name, container.Name, true)
+		if err != nil {
+			fmt.Fprintf(w, "the logs of container %s are not available: %v\n", container.Name, err)
+			continue
+		}
+
+		_, err = io.Copy(w, stream)
+		stream.Close()
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err != nil {
+			fmt.Fprintf(w, "the logs of container %s are incomplete: %v\n", container.Name, err)
+		}
+	}
+}
+
+// waitForContainer waits until the container of the pod runs or terminated,
+// it returns false if the pod completed without running the container
+func (s *Server) waitForContainer(ctx context.Context, namespace string, name string, container string) (bool, error) {
+	for {
+		pod := &corev1.Pod{}
+		if err := s.client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, pod); err != nil {
+			return false, err
+		}
+
+		for _, containerStatus := range pod.Status.ContainerStatuses {
+			if containerStatus.Name == container && (containerStatus.State.Running != nil || containerStatus.State.Terminated != nil) {
+				return true, nil
+			}
+		}
+
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			return false, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-time.After(s.PollInterval):
+		}
+	}
+}
+
+func notFoundOrError(err error, format string, args ...interface{}) (*corev1.Pod, int, error) {
+	if apierrors.IsNotFound(err) {
+		return nil, http.StatusNotFound, fmt.Errorf(format, args...)
+	}
+
+	return nil, http.StatusInternalServerError, err
+}
+
+// flushWriter flushes every write, so that the logs reach the client while
+// they are streamed
+type flushWriter struct {
+	w       io.Writer
+	flusher http.Flusher
+}
+
+func newFlushWriter(w http.ResponseWriter) io.Writer {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return w
+	}
+
+	return &flushWriter{w: w, flusher: flusher}
+}
+
+func (f *flushWriter) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	f.flusher.Flush()
+	return n, err
+}
@@ -0,0 +1,210 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package logs_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	crc "sigs.k8s.io/controller-runtime/pkg/client"
+
+	buildv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	"github.com/shipwright-io/build/pkg/auth"
+	"github.com/shipwright-io/build/pkg/config"
+	"github.com/shipwright-io/build/pkg/controller/fakes"
+	"github.com/shipwright-io/build/pkg/logs"
+)
+
+type fakeAuthorizer struct {
+	users map[string]error
+}
+
+func (a *fakeAuthorizer) Authorize(_ context.Context, token string, _ string, _ string) (string, error) {
+	err, ok := a.users[token]
+	if !ok {
+		return "", auth.ErrUnauthenticated
+	}
+
+	return token, err
+}
+
+var _ = Describe("Server", func() {
+	var (
+		client   *fakes.FakeClient
+		buildRun *buildv1alpha1.BuildRun
+		pod      *corev1.Pod
+		podGets  int
+		onPodGet func(pod *corev1.Pod, count int)
+		server   *httptest.Server
+	)
+
+	var get = func(token string) (int, string) {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/namespaces/default/buildruns/logs-buildrun/log", nil)
+		Expect(err).ToNot(HaveOccurred())
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := http.DefaultClient.Do(req)
+		Expect(err).ToNot(HaveOccurred())
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).ToNot(HaveOccurred())
+		return resp.StatusCode, string(body)
+	}
+
+	var terminated = corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{}}
+
+	BeforeEach(func() {
+		taskRunName := "logs-buildrun-xyz"
+		buildRun = &buildv1alpha1.BuildRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "logs-buildrun", Namespace: "default"},
+			Status:     buildv1alpha1.BuildRunStatus{LatestTaskRunRef: &taskRunName},
+		}
+
+		pod = &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "logs-buildrun-xyz-pod", Namespace: "default"},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "step-source-default"},
+					{Name: "step-build-and-push"},
+					{Name: "sidecar"},
+				},
+			},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "step-source-default", State: terminated},
+					{Name: "step-build-and-push", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+					{Name: "sidecar", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+				},
+			},
+		}
+
+		podGets = 0
+		onPodGet = nil
+
+		client = &fakes.FakeClient{}
+		client.GetCalls(func(_ context.Context, nn types.NamespacedName, object crc.Object) error {
+			switch object := object.(type) {
+			case *buildv1alpha1.BuildRun:
+				if nn.Name == buildRun.Name {
+					buildRun.DeepCopyInto(object)
+					return nil
+				}
+
+			case *pipelinev1beta1.TaskRun:
+				if nn.Name == taskRunName {
+					object.Name = taskRunName
+					object.Status.PodName = "logs-buildrun-xyz-pod"
+					return nil
+				}
+
+			case *corev1.Pod:
+				if pod != nil && nn.Name == pod.Name {
+					podGets++
+					if onPodGet != nil {
+						onPodGet(pod, podGets)
+					}
+
+					pod.DeepCopyInto(object)
+					return nil
+				}
+			}
+
+			return k8serrors.NewNotFound(schema.GroupResource{}, nn.Name)
+		})
+
+		logServer := logs.NewServer(config.NewDefaultConfig(), client, &fakeAuthorizer{users: map[string]error{
+			"developer": nil,
+			"viewer":    fmt.Errorf("%w: not allowed", auth.ErrForbidden),
+		}}, fakeLogReader{
+			"step-source-default": "cloned\n",
+			"step-build-and-push": "pushed\n",
+			"sidecar":             "sidecar\n",
+		})
+		logServer.PollInterval = time.Millisecond
+
+		server = httptest.NewServer(logServer)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("streams the logs of the steps in their order", func() {
+		status, body := get("developer")
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(body).To(Equal("==> source-default <==\ncloned\n==> build-and-push <==\npushed\n"))
+	})
+
+	It("waits for a step to start", func() {
+		pod.Status.ContainerStatuses[1].State = corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{}}
+		onPodGet = func(pod *corev1.Pod, count int) {
+			if count > 3 {
+				pod.Status.ContainerStatuses[1].State = terminated
+			}
+		}
+
+		status, body := get("developer")
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(body).To(Equal("==> source-default <==\ncloned\n==> build-and-push <==\npushed\n"))
+		Expect(podGets).To(BeNumerically(">", 3))
+	})
+
+	It("annotates a step that did not run in a completed pod", func() {
+		pod.Status.Phase = corev1.PodFailed
+		pod.Status.ContainerStatuses[1].State = corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{}}
+
+		status, body := get("developer")
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(body).To(Equal("==> source-default <==\ncloned\n==> build-and-push <==\ncontainer step-build-and-push did not run\n"))
+	})
+
+	It("rejects a request without a valid token", func() {
+		status, _ := get("unknown")
+		Expect(status).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("rejects a user that may not get the logs of the BuildRun", func() {
+		status, _ := get("viewer")
+		Expect(status).To(Equal(http.StatusForbidden))
+	})
+
+	It("rejects a BuildRun that has not started yet", func() {
+		buildRun.Status.LatestTaskRunRef = nil
+
+		status, _ := get("developer")
+		Expect(status).To(Equal(http.StatusConflict))
+	})
+
+	It("refers to the archived logs if the pod no longer exists", func() {
+		pod = nil
+		buildRun.Status.Logs = &buildv1alpha1.BuildRunLogs{Sink: "s3", Reference: "s3://logs/default/logs-buildrun/logs-buildrun-xyz.log"}
+
+		status, body := get("developer")
+		Expect(status).To(Equal(http.StatusGone))
+		Expect(body).To(ContainSubstring("s3://logs/default/logs-buildrun/logs-buildrun-xyz.log"))
+	})
+
+	It("returns not found for an unknown path", func() {
+		resp, err := http.Get(server.URL + "/namespaces/default/buildruns/logs-buildrun")
+		Expect(err).ToNot(HaveOccurred())
+		resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+	})
+})
@@ -0,0 +1,161 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package logs_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	buildv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	"github.com/shipwright-io/build/pkg/config"
+	"github.com/shipwright-io/build/pkg/logs"
+)
+
+var _ = Describe("Sinks", func() {
+	var buildRun *buildv1alpha1.BuildRun
+
+	BeforeEach(func() {
+		buildRun = &buildv1alpha1.BuildRun{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "buildrun"}}
+	})
+
+	Context("pvc", func() {
+		It("writes the logs into a file of the BuildRun", func() {
+			directory, err := os.MkdirTemp("", "logs")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.RemoveAll(directory)
+
+			sink := logs.NewPVCSink(directory)
+			Expect(sink.Name()).To(Equal(config.LogsSinkPVC))
+
+			reference, err := sink.Store(context.TODO(), buildRun, "buildrun-abcde", strings.NewReader("logs"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(reference).To(Equal(filepath.Join(directory, "default", "buildrun", "buildrun-abcde.log")))
+
+			content, err := os.ReadFile(reference)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(content)).To(Equal("logs"))
+		})
+	})
+
+	Context("s3", func() {
+		var (
+			server   *httptest.Server
+			requests []*http.Request
+			bodies   []string
+			status   int
+		)
+
+		BeforeEach(func() {
+			requests, bodies, status = nil, nil, http.StatusOK
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				requests = append(requests, r)
+				bodies = append(bodies, string(body))
+				w.WriteHeader(status)
+			}))
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		s3Config := func() config.S3Config {
+			return config.S3Config{
+				Endpoint:        server.URL,
+				Bucket:          "logs",
+				Region:          "us-east-1",
+				AccessKeyID:     "access-key",
+				SecretAccessKey: "secret-key",
+			}
+		}
+
+		It("uploads the logs into the bucket", func() {
+			sink := logs.NewS3Sink(s3Config(), server.Client())
+			Expect(sink.Name()).To(Equal(config.LogsSinkS3))
+
+			reference, err := sink.Store(context.TODO(), buildRun, "buildrun-abcde", strings.NewReader("logs"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(reference).To(Equal("s3://logs/default/buildrun/buildrun-abcde.log"))
+
+			Expect(requests).To(HaveLen(1))
+			Expect(requests[0].Method).To(Equal(http.MethodPut))
+			Expect(requests[0].URL.Path).To(Equal("/logs/default/buildrun/buildrun-abcde.log"))
+			Expect(requests[0].Header.Get("Authorization")).To(HavePrefix("AWS4-HMAC-SHA256 Credential=access-key/"))
+			Expect(requests[0].ContentLength).To(Equal(int64(4)))
+			Expect(requests[0].Header.Get("X-Amz-Content-Sha256")).To(Equal("98f38f12db221a8cf8ca7aadfdcd759b01d52eb4ebb3eedbb2d97e92805c6960"))
+			Expect(bodies[0]).To(Equal("logs"))
+		})
+
+		It("fails if the endpoint rejects the upload", func() {
+			status = http.StatusForbidden
+
+			_, err := logs.NewS3Sink(s3Config(), server.Client()).Store(context.TODO(), buildRun, "buildrun-abcde", strings.NewReader("logs"))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("403"))
+		})
+	})
+
+	Context("oci", func() {
+		var server *httptest.Server
+
+		BeforeEach(func() {
+			server = httptest.NewServer(registry.New())
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("pushes the logs next to the output image", func() {
+			repository := fmt.Sprintf("%s/namespace/image", strings.TrimPrefix(server.URL, "http://"))
+			buildRun.Spec.Output = &buildv1alpha1.Image{Image: repository}
+			buildRun.Status.Output = &buildv1alpha1.Output{Digest: "sha256:0123456789abcdef"}
+
+			sink := logs.NewOCISink(nil)
+			Expect(sink.Name()).To(Equal(config.LogsSinkOCI))
+
+			reference, err := sink.Store(context.TODO(), buildRun, "buildrun-abcde", strings.NewReader("logs"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(reference).To(HavePrefix(repository + "@sha256:"))
+
+			tag, err := name.ParseReference(repository + ":sha256-0123456789abcdef.logs")
+			Expect(err).ToNot(HaveOccurred())
+			_, err = remote.Head(tag)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("tags the logs like the TaskRun if the digest of the output image is unknown", func() {
+			repository := fmt.Sprintf("%s/namespace/image", strings.TrimPrefix(server.URL, "http://"))
+			buildRun.Spec.Output = &buildv1alpha1.Image{Image: repository}
+
+			_, err := logs.NewOCISink(nil).Store(context.TODO(), buildRun, "buildrun-abcde", strings.NewReader("logs"))
+			Expect(err).ToNot(HaveOccurred())
+
+			tag, err := name.ParseReference(repository + ":buildrun-abcde.logs")
+			Expect(err).ToNot(HaveOccurred())
+			_, err = remote.Head(tag)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("fails if the output image is unknown", func() {
+			_, err := logs.NewOCISink(nil).Store(context.TODO(), buildRun, "buildrun-abcde", strings.NewReader("logs"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -19,6 +19,7 @@ const (
 	NamespaceLabel     string = "namespace"
 	BuildLabel         string = "build"
 	BuildRunLabel      string = "buildrun"
+	StepLabel          string = "step"
 )
 
 var (
@@ -32,6 +33,8 @@ var (
 	taskRunRampUpDuration    *prometheus.HistogramVec
 	taskRunPodRampUpDuration *prometheus.HistogramVec
 
+	buildRunStepDuration *prometheus.HistogramVec
+
 	buildStrategyLabelEnabled = false
 	namespaceLabelEnabled     = false
 	buildLabelEnabled         = false
@@ -127,6 +130,15 @@ func InitPrometheus(config *config.Config) {
 		},
 		buildRunLabels)
 
+	// the step label is always set, a step duration without it would mix all steps of a strategy
+	buildRunStepDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "build_buildrun_step_duration_seconds",
+			Help:    "BuildRun step duration in seconds (time between the start and the completion of a step).",
+			Buckets: config.Prometheus.BuildRunStepDurationBuckets,
+		},
+		append(append([]string{}, buildRunLabels...), StepLabel))
+
 	// Register custom metrics with the global prometheus registry
 	metrics.Registry.MustRegister(
 		buildCount,
@@ -136,6 +148,7 @@ func InitPrometheus(config *config.Config) {
 		buildRunRampUpDuration,
 		taskRunRampUpDuration,
 		taskRunPodRampUpDuration,
+		buildRunStepDuration,
 	)
 }
 
@@ -237,3 +250,12 @@ func TaskRunPodRampUpDurationObserve(buildStrategy string, namespace string, bui
 		taskRunPodRampUpDuration.With(createBuildRunLabels(buildStrategy, namespace, build, buildRun)).Observe(duration.Seconds())
 	}
 }
+
+// BuildRunStepDurationObserve processes the observation of the duration of a step of a buildrun
+func BuildRunStepDurationObserve(buildStrategy string, namespace string, build string, buildRun string, step string, duration time.Duration) {
+	if buildRunStepDuration != nil {
+		labels := createBuildRunLabels(buildStrategy, namespace, build, buildRun)
+		labels[StepLabel] = step
+		buildRunStepDuration.With(labels).Observe(duration.Seconds())
+	}
+}
@@ -30,10 +30,16 @@ var _ = Describe("Custom Metrics", func() {
 		buildRun      string
 	}
 
+	type stepLabels struct {
+		buildRunLabels
+		step string
+	}
+
 	var (
 		buildCounterMetrics      = map[string]map[buildLabels]float64{}
 		buildRunCounterMetrics   = map[string]map[buildRunLabels]float64{}
 		buildRunHistogramMetrics = map[string]map[buildRunLabels]float64{}
+		stepHistogramMetrics     = map[stepLabels]float64{}
 
 		promLabelPairToBuildLabels = func(in []*io_prometheus_client.LabelPair) buildLabels {
 			var result = buildLabels{}
@@ -68,6 +74,17 @@ var _ = Describe("Custom Metrics", func() {
 
 			return result
 		}
+
+		promLabelPairToStepLabels = func(in []*io_prometheus_client.LabelPair) stepLabels {
+			var result = stepLabels{buildRunLabels: promLabelPairToBuildRunLabels(in)}
+			for _, label := range in {
+				if *label.Name == StepLabel {
+					result.step = *label.Value
+				}
+			}
+
+			return result
+		}
 	)
 
 	BeforeSuite(func() {
@@ -112,6 +129,8 @@ var _ = Describe("Custom Metrics", func() {
 			BuildRunRampUpDurationObserve(buildStrategy, namespace, build, buildRun, time.Duration(1)*time.Second)
 			TaskRunRampUpDurationObserve(buildStrategy, namespace, build, buildRun, time.Duration(2)*time.Second)
 			TaskRunPodRampUpDurationObserve(buildStrategy, namespace, build, buildRun, time.Duration(3)*time.Second)
+			BuildRunStepDurationObserve(buildStrategy, namespace, build, buildRun, "source-default", time.Duration(4)*time.Second)
+			BuildRunStepDurationObserve(buildStrategy, namespace, build, buildRun, "build-and-push", time.Duration(30)*time.Second)
 		}
 
 		// gather metrics from prometheus and fill the result maps
@@ -121,11 +140,18 @@ var _ = Describe("Custom Metrics", func() {
 		}
 
 		for _, metricFamily := range metrics {
-			if metricFamily.GetType() == io_prometheus_client.MetricType_HISTOGRAM {
+			switch {
+			case metricFamily.GetName() == "build_buildrun_step_duration_seconds":
+				for _, metric := range metricFamily.GetMetric() {
+					stepHistogramMetrics[promLabelPairToStepLabels(metric.GetLabel())] = metric.GetHistogram().GetSampleSum()
+				}
+
+			case metricFamily.GetType() == io_prometheus_client.MetricType_HISTOGRAM:
 				for _, metric := range metricFamily.GetMetric() {
 					buildRunHistogramMetrics[metricFamily.GetName()][promLabelPairToBuildRunLabels(metric.GetLabel())] = metric.GetHistogram().GetSampleSum()
 				}
-			} else {
+
+			default:
 				switch metricFamily.GetName() {
 				case "build_builds_registered_total":
 					for _, metric := range metricFamily.GetMetric() {
@@ -171,6 +197,12 @@ var _ = Describe("Custom Metrics", func() {
 			Expect(buildRunHistogramMetrics["build_buildrun_taskrun_rampup_duration_seconds"][buildRunLabels{"kaniko", "default", "kaniko-build", "kaniko-buildrun"}]).To(BeNumerically(">", 0.0))
 			Expect(buildRunHistogramMetrics["build_buildrun_taskrun_pod_rampup_duration_seconds"][buildRunLabels{"kaniko", "default", "kaniko-build", "kaniko-buildrun"}]).To(BeNumerically(">", 0.0))
 		})
+
+		It("should record the kaniko step durations", func() {
+			labels := buildRunLabels{"kaniko", "default", "kaniko-build", "kaniko-buildrun"}
+			Expect(stepHistogramMetrics[stepLabels{labels, "source-default"}]).To(Equal(4.0))
+			Expect(stepHistogramMetrics[stepLabels{labels, "build-and-push"}]).To(Equal(30.0))
+		})
 	})
 
 	Context("when create a new buildpacks buildrun", func() {
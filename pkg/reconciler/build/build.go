@@ -10,6 +10,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -29,6 +30,7 @@ type ReconcileBuild struct {
 	config                *config.Config
 	client                client.Client
 	scheme                *runtime.Scheme
+	recorder              record.EventRecorder
 	setOwnerReferenceFunc setOwnerReferenceFunc
 }
 
@@ -38,6 +40,7 @@ func NewReconciler(c *config.Config, mgr manager.Manager, ownerRef setOwnerRefer
 		config:                c,
 		client:                mgr.GetClient(),
 		scheme:                mgr.GetScheme(),
+		recorder:              mgr.GetEventRecorderFor("build-controller"),
 		setOwnerReferenceFunc: ownerRef,
 	}
 }
@@ -60,55 +63,22 @@ func (r *ReconcileBuild) Reconcile(ctx context.Context, request reconcile.Reques
 		return reconcile.Result{}, nil
 	}
 
-	// Populate the status struct with default values
-	b.Status.Registered = build.ConditionStatusPtr(corev1.ConditionFalse)
-	b.Status.Reason = build.BuildReasonPtr(build.SucceedStatus)
-
-	// build a list of current validation types
-	validationTypes := []string{
-		validate.OwnerReferences,
-		validate.SourceURL,
-		validate.Secrets,
-		validate.Strategies,
-		validate.Sources,
-		validate.BuildName,
-		validate.Envs,
-		validate.Retention,
+	// trigger all current validations
+	if err := validate.ValidateBuild(ctx, b, r.client, r.scheme); err != nil {
+		return reconcile.Result{}, err
 	}
 
-	// trigger all current validations
-	for _, validationType := range validationTypes {
-		v, err := validate.NewValidation(validationType, b, r.client, r.scheme)
-		if err != nil {
-			// when the validation type is unknown
-			return reconcile.Result{}, err
-		}
-
-		if err := v.ValidatePath(ctx); err != nil {
-			// We enqueue another reconcile here. This is done only for validation
-			// types where the error can be produced from a failed API call.
-			if validationType == validate.Secrets || validationType == validate.Strategies {
-				return reconcile.Result{}, err
-			}
-			if validationType == validate.OwnerReferences {
-				// we do not want to bail out here if the owerreference validation fails, we ignore this error on purpose
-				// In case we just created the Build, we want the Build reconcile logic to continue, in order to
-				// validate the Build references ( e.g secrets, strategies )
-				ctxlog.Info(ctx, "unexpected error during ownership reference validation", namespace, request.Namespace, name, request.Name, "error", err)
-			}
-		}
-		if b.Status.Reason == nil || *b.Status.Reason != build.SucceedStatus {
-			return r.UpdateBuildStatusAndRetreat(ctx, b)
-		}
+	if b.Status.Reason == nil || *b.Status.Reason != build.SucceedStatus {
+		return r.UpdateBuildStatusAndRetreat(ctx, b)
 	}
 
-	b.Status.Registered = build.ConditionStatusPtr(corev1.ConditionTrue)
-	b.Status.Message = pointer.String(build.AllValidationsSucceeded)
 	err = r.client.Status().Update(ctx, b)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
 
+	r.recorder.Event(b, corev1.EventTypeNormal, string(build.SucceedStatus), build.AllValidationsSucceeded)
+
 	// Increase Build count in metrics
 	buildmetrics.BuildCountInc(b.Spec.Strategy.Name, b.Namespace, b.Name)
 
@@ -123,5 +93,10 @@ func (r *ReconcileBuild) UpdateBuildStatusAndRetreat(ctx context.Context, b *bui
 	if err := r.client.Status().Update(ctx, b); err != nil {
 		return reconcile.Result{}, err
 	}
+
+	if b.Status.Reason != nil {
+		r.recorder.Event(b, corev1.EventTypeWarning, string(*b.Status.Reason), pointer.StringDeref(b.Status.Message, ""))
+	}
+
 	return reconcile.Result{}, nil
 }
@@ -14,6 +14,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/pointer"
 	crc "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -29,6 +30,7 @@ import (
 var _ = Describe("Reconcile Build", func() {
 	var (
 		manager                      *fakes.FakeManager
+		recorder                     *record.FakeRecorder
 		reconciler                   reconcile.Reconciler
 		request                      reconcile.Request
 		buildSample                  *build.Build
@@ -69,6 +71,9 @@ var _ = Describe("Reconcile Build", func() {
 		statusWriter = &fakes.FakeStatusWriter{}
 		client.StatusCalls(func() crc.StatusWriter { return statusWriter })
 		manager.GetClientReturns(client)
+
+		recorder = record.NewFakeRecorder(100)
+		manager.GetEventRecorderForReturns(recorder)
 	})
 
 	JustBeforeEach(func() {
@@ -90,6 +95,41 @@ var _ = Describe("Reconcile Build", func() {
 				statusCall := ctl.StubFunc(corev1.ConditionFalse, build.SpecSourceSecretRefNotFound, "referenced secret non-existing not found")
 				statusWriter.UpdateCalls(statusCall)
 
+				_, err := reconciler.Reconcile(context.TODO(), request)
+				Expect(err).To(BeNil())
+				Expect(statusWriter.UpdateCallCount()).To(Equal(1))
+				Expect(recorder.Events).To(Receive(Equal("Warning SpecSourceSecretRefNotFound referenced secret non-existing not found")))
+			})
+
+			It("fails when the secret of a remote artifact does not exist", func() {
+				buildSample.Spec.Sources = []build.BuildSource{{
+					Name:        "logo",
+					Type:        build.HTTP,
+					URL:         "https://shipwright.io/icons/logo.svg",
+					Credentials: &corev1.LocalObjectReference{Name: "non-existing"},
+				}}
+				buildSample.Spec.Output.Credentials = nil
+
+				statusCall := ctl.StubFunc(corev1.ConditionFalse, build.SpecSourceSecretRefNotFound, "referenced secret non-existing not found")
+				statusWriter.UpdateCalls(statusCall)
+
+				_, err := reconciler.Reconcile(context.TODO(), request)
+				Expect(err).To(BeNil())
+				Expect(statusWriter.UpdateCallCount()).To(Equal(1))
+			})
+
+			It("fails when the secret with the public key of the bundle image does not exist", func() {
+				buildSample.Spec.Source = build.Source{
+					BundleContainer: &build.BundleContainer{
+						Image:     "registry.example.com/org/source:latest",
+						PublicKey: &build.PublicKeyReference{Secret: "non-existing"},
+					},
+				}
+				buildSample.Spec.Output.Credentials = nil
+
+				statusCall := ctl.StubFunc(corev1.ConditionFalse, build.SpecSourceSecretRefNotFound, "referenced secret non-existing not found")
+				statusWriter.UpdateCalls(statusCall)
+
 				_, err := reconciler.Reconcile(context.TODO(), request)
 				Expect(err).To(BeNil())
 				Expect(statusWriter.UpdateCallCount()).To(Equal(1))
@@ -420,6 +460,7 @@ var _ = Describe("Reconcile Build", func() {
 				Expect(err).ToNot(HaveOccurred())
 				Expect(statusWriter.UpdateCallCount()).To(Equal(1))
 				Expect(reconcile.Result{}).To(Equal(result))
+				Expect(recorder.Events).To(Receive(Equal("Normal Succeeded all validations succeeded")))
 			})
 
 			// skip validation because of false sourceURL annotation
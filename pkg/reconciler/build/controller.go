@@ -158,6 +158,11 @@ func add(ctx context.Context, mgr manager.Manager, r reconcile.Reconciler, maxCo
 					flagReconcile = true
 				}
 			}
+			if build.Spec.Source.BundleContainer != nil && build.Spec.Source.BundleContainer.PublicKey != nil {
+				if build.Spec.Source.BundleContainer.PublicKey.Secret == secret.Name {
+					flagReconcile = true
+				}
+			}
 			if build.Spec.Output.Credentials != nil {
 				if build.Spec.Output.Credentials.Name == secret.Name {
 					flagReconcile = true
@@ -168,6 +173,11 @@ func add(ctx context.Context, mgr manager.Manager, r reconcile.Reconciler, maxCo
 					flagReconcile = true
 				}
 			}
+			for _, source := range build.Spec.Sources {
+				if source.Credentials != nil && source.Credentials.Name == secret.Name {
+					flagReconcile = true
+				}
+			}
 			if flagReconcile {
 				reconcileList = append(reconcileList, reconcile.Request{
 					NamespacedName: types.NamespacedName{
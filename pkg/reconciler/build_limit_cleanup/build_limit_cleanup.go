@@ -16,6 +16,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -28,6 +29,7 @@ type ReconcileBuild struct {
 	config                *config.Config
 	client                client.Client
 	scheme                *runtime.Scheme
+	recorder              record.EventRecorder
 	setOwnerReferenceFunc setOwnerReferenceFunc
 }
 
@@ -36,6 +38,7 @@ func NewReconciler(c *config.Config, mgr manager.Manager, ownerRef setOwnerRefer
 		config:                c,
 		client:                mgr.GetClient(),
 		scheme:                mgr.GetScheme(),
+		recorder:              mgr.GetEventRecorderFor("build-limit-cleanup-controller"),
 		setOwnerReferenceFunc: ownerRef,
 	}
 }
@@ -100,6 +103,7 @@ func (r *ReconcileBuild) Reconcile(ctx context.Context, request reconcile.Reques
 						ctxlog.Debug(ctx, "Error deleting buildRun.", namespace, request.Namespace, name, &buildRunSucceeded[i].Name, deleteError, deleteBuildRunErr)
 						return reconcile.Result{}, nil
 					}
+					r.recorder.Eventf(b, corev1.EventTypeNormal, build.EventReasonBuildRunDeleted, "Deleted succeeded BuildRun %s as the limit of %d succeeded BuildRuns was reached", buildRunSucceeded[i].Name, *b.Spec.Retention.SucceededLimit)
 				}
 			}
 		}
@@ -118,6 +122,7 @@ func (r *ReconcileBuild) Reconcile(ctx context.Context, request reconcile.Reques
 						ctxlog.Debug(ctx, "Error deleting buildRun.", namespace, request.Namespace, name, buildRunFailed[i].Name, deleteError, deleteBuildRunErr)
 						return reconcile.Result{}, nil
 					}
+					r.recorder.Eventf(b, corev1.EventTypeNormal, build.EventReasonBuildRunDeleted, "Deleted failed BuildRun %s as the limit of %d failed BuildRuns was reached", buildRunFailed[i].Name, *b.Spec.Retention.FailedLimit)
 				}
 			}
 		}
@@ -113,14 +113,14 @@ func add(ctx context.Context, mgr manager.Manager, r reconcile.Reconciler, maxCo
 	return c.Watch(&source.Kind{Type: &buildv1alpha1.BuildRun{}}, handler.EnqueueRequestsFromMapFunc(func(o client.Object) []reconcile.Request {
 		buildRun := o.(*buildv1alpha1.BuildRun)
 		// check if Buildrun is related to a build
-		if buildRun.Spec.BuildRef.Name == "" {
+		if buildRun.BuildName() == "" {
 			return []reconcile.Request{}
 		}
 
 		return []reconcile.Request{
 			{
 				NamespacedName: types.NamespacedName{
-					Name:      buildRun.Spec.BuildRef.Name,
+					Name:      buildRun.BuildName(),
 					Namespace: buildRun.Namespace,
 				},
 			},
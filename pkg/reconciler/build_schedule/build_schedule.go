@@ -0,0 +1,233 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package build_schedule
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	"knative.dev/pkg/kmeta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	build "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	"github.com/shipwright-io/build/pkg/config"
+	"github.com/shipwright-io/build/pkg/cron"
+	"github.com/shipwright-io/build/pkg/ctxlog"
+)
+
+// ReconcileBuildSchedule creates the BuildRuns of a Build according to its
+// schedule, and deletes the ones that exceed its history limits
+type ReconcileBuildSchedule struct {
+	config   *config.Config
+	client   client.Client
+	clock    clock.PassiveClock
+	recorder record.EventRecorder
+}
+
+// NewReconciler returns the reconciler of the schedules of Builds, the clock
+// defines the current time when the reconciler decides about BuildRuns to
+// create
+func NewReconciler(c *config.Config, mgr manager.Manager, clock clock.PassiveClock) reconcile.Reconciler {
+	return &ReconcileBuildSchedule{
+		config:   c,
+		client:   mgr.GetClient(),
+		clock:    clock,
+		recorder: mgr.GetEventRecorderFor("build-schedule-controller"),
+	}
+}
+
+// Reconcile creates the BuildRun for the most recent scheduled time of a
+// Build that passed, and requeues the Build until its next scheduled time
+func (r *ReconcileBuildSchedule) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.config.CtxTimeOut)
+	defer cancel()
+
+	ctxlog.Debug(ctx, "start reconciling build-schedule", namespace, request.Namespace, name, request.Name)
+
+	b := &build.Build{}
+	if err := r.client.Get(ctx, request.NamespacedName, b); err != nil {
+		if apierrors.IsNotFound(err) {
+			ctxlog.Debug(ctx, "finish reconciling build-schedule. Build was not found", namespace, request.Namespace, name, request.Name)
+			return reconcile.Result{}, nil
+		}
+
+		return reconcile.Result{}, err
+	}
+
+	if b.Spec.Schedule == nil {
+		return reconcile.Result{}, nil
+	}
+
+	if err := r.pruneHistory(ctx, b); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// the Build controller reconciles the Build again once it is registered
+	if b.Status.Registered == nil || *b.Status.Registered != corev1.ConditionTrue {
+		ctxlog.Debug(ctx, "finish reconciling build-schedule. Build is not registered", namespace, request.Namespace, name, request.Name)
+		return reconcile.Result{}, nil
+	}
+
+	schedule, err := cron.ParseInLocation(b.Spec.Schedule.Cron, pointer.StringDeref(b.Spec.Schedule.TimeZone, ""))
+	if err != nil {
+		// the Build controller does not register a Build with an invalid schedule
+		ctxlog.Info(ctx, "invalid schedule", namespace, request.Namespace, name, request.Name, "error", err.Error())
+		return reconcile.Result{}, nil
+	}
+
+	now := r.clock.Now()
+	if scheduledTime := mostRecentScheduledTime(schedule, b, now); !scheduledTime.IsZero() {
+		if err := r.createBuildRun(ctx, b, scheduledTime); err != nil {
+			return reconcile.Result{}, err
+		}
+
+		b.Status.LastScheduleTime = &metav1.Time{Time: scheduledTime}
+		if err := r.client.Status().Update(ctx, b); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	next := schedule.Next(now)
+	if next.IsZero() {
+		return reconcile.Result{}, nil
+	}
+
+	ctxlog.Debug(ctx, "finish reconciling build-schedule", namespace, request.Namespace, name, request.Name, "next", next.String())
+	return reconcile.Result{RequeueAfter: next.Sub(now)}, nil
+}
+
+// mostRecentScheduledTime returns the latest scheduled time of the Build that
+// passed since the last BuildRun was scheduled and is within the starting
+// deadline, or the zero time if there is none
+func mostRecentScheduledTime(schedule *cron.Schedule, b *build.Build, now time.Time) time.Time {
+	earliest := b.CreationTimestamp.Time
+	if b.Status.LastScheduleTime != nil {
+		earliest = b.Status.LastScheduleTime.Time
+	}
+
+	if b.Spec.Schedule.StartingDeadline != nil {
+		if deadline := now.Add(-b.Spec.Schedule.StartingDeadline.Duration); deadline.After(earliest) {
+			earliest = deadline
+		}
+	}
+
+	var mostRecent time.Time
+	for t := schedule.Next(earliest); !t.IsZero() && !t.After(now); t = schedule.Next(t) {
+		mostRecent = t
+	}
+
+	return mostRecent
+}
+
+// createBuildRun creates the BuildRun of a scheduled time, its name is derived
+// from the scheduled time so that it is created only once. The name of a long
+// Build is shortened and hashed, so that the name of the BuildRun is still a
+// valid label value.
+func (r *ReconcileBuildSchedule) createBuildRun(ctx context.Context, b *build.Build, scheduledTime time.Time) error {
+	buildRun := &build.BuildRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      kmeta.ChildName(b.Name, fmt.Sprintf("-%d", scheduledTime.Unix()/60)),
+			Namespace: b.Namespace,
+			Labels: map[string]string{
+				build.LabelBuildSchedule: b.Name,
+			},
+			Annotations: map[string]string{
+				build.AnnotationBuildScheduledTime: scheduledTime.UTC().Format(time.RFC3339),
+			},
+		},
+		Spec: build.BuildRunSpec{
+			BuildRef: &build.BuildRef{Name: b.Name},
+		},
+	}
+
+	ctxlog.Info(ctx, "creating scheduled BuildRun", namespace, b.Namespace, name, b.Name, "buildRun", buildRun.Name, "scheduledTime", scheduledTime.String())
+	if err := r.client.Create(ctx, buildRun); err != nil {
+		if apierrors.IsAlreadyExists(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	r.recorder.Eventf(b, corev1.EventTypeNormal, build.EventReasonBuildRunCreated, "Created BuildRun %s for the scheduled time %s", buildRun.Name, scheduledTime.UTC().Format(time.RFC3339))
+	return nil
+}
+
+// pruneHistory deletes the oldest completed BuildRuns that were created by the
+// schedule of the Build and exceed its history limits
+func (r *ReconcileBuildSchedule) pruneHistory(ctx context.Context, b *build.Build) error {
+	if b.Spec.Schedule.SucceededHistoryLimit == nil && b.Spec.Schedule.FailedHistoryLimit == nil {
+		return nil
+	}
+
+	buildRuns := &build.BuildRunList{}
+	if err := r.client.List(ctx, buildRuns, scheduledBy(b)); err != nil {
+		return err
+	}
+
+	var succeeded, failed []build.BuildRun
+	for _, buildRun := range buildRuns.Items {
+		if buildRun.Status.CompletionTime == nil {
+			continue
+		}
+
+		switch buildRun.Status.GetCondition(build.Succeeded).GetStatus() {
+		case corev1.ConditionTrue:
+			succeeded = append(succeeded, buildRun)
+		case corev1.ConditionFalse:
+			failed = append(failed, buildRun)
+		}
+	}
+
+	if err := r.deleteOldest(ctx, b, succeeded, b.Spec.Schedule.SucceededHistoryLimit); err != nil {
+		return err
+	}
+
+	return r.deleteOldest(ctx, b, failed, b.Spec.Schedule.FailedHistoryLimit)
+}
+
+func (r *ReconcileBuildSchedule) deleteOldest(ctx context.Context, b *build.Build, buildRuns []build.BuildRun, limit *uint) error {
+	if limit == nil || len(buildRuns) <= int(*limit) {
+		return nil
+	}
+
+	sort.Slice(buildRuns, func(i, j int) bool {
+		return buildRuns[i].Status.CompletionTime.Before(buildRuns[j].Status.CompletionTime)
+	})
+
+	for i := range buildRuns[:len(buildRuns)-int(*limit)] {
+		ctxlog.Info(ctx, "deleting scheduled BuildRun as the history limit has been reached", namespace, buildRuns[i].Namespace, name, buildRuns[i].Name)
+		if err := r.client.Delete(ctx, &buildRuns[i]); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+
+			return err
+		}
+
+		r.recorder.Eventf(b, corev1.EventTypeNormal, build.EventReasonBuildRunDeleted, "Deleted BuildRun %s as the history limit of the schedule was reached", buildRuns[i].Name)
+	}
+
+	return nil
+}
+
+// scheduledBy lists the BuildRuns that were created by the schedule of a Build
+func scheduledBy(b *build.Build) client.ListOption {
+	return &client.ListOptions{
+		Namespace:     b.Namespace,
+		LabelSelector: labels.SelectorFromSet(labels.Set{build.LabelBuildSchedule: b.Name}),
+	}
+}
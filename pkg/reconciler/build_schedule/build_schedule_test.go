@@ -0,0 +1,249 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package build_schedule_test
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	crc "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	build "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	"github.com/shipwright-io/build/pkg/config"
+	"github.com/shipwright-io/build/pkg/controller/fakes"
+	"github.com/shipwright-io/build/pkg/reconciler/build_schedule"
+)
+
+var _ = Describe("Reconcile Build Schedule", func() {
+	var (
+		manager      *fakes.FakeManager
+		recorder     *record.FakeRecorder
+		client       *fakes.FakeClient
+		statusWriter *fakes.FakeStatusWriter
+		fakeClock    *clock.FakePassiveClock
+		reconciler   reconcile.Reconciler
+		request      reconcile.Request
+		buildSample  *build.Build
+		buildRuns    []build.BuildRun
+		created      []*build.BuildRun
+		deleted      []string
+	)
+
+	created2021 := time.Date(2021, time.September, 14, 12, 0, 0, 0, time.UTC)
+
+	BeforeEach(func() {
+		buildSample = &build.Build{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "nightly",
+				Namespace:         "default",
+				CreationTimestamp: metav1.NewTime(created2021),
+			},
+			Spec: build.BuildSpec{
+				Schedule: &build.BuildSchedule{Cron: "0 2 * * *"},
+			},
+			Status: build.BuildStatus{
+				Registered: build.ConditionStatusPtr(corev1.ConditionTrue),
+			},
+		}
+		buildRuns = nil
+		created = nil
+		deleted = nil
+
+		request = reconcile.Request{NamespacedName: types.NamespacedName{Name: "nightly", Namespace: "default"}}
+
+		client = &fakes.FakeClient{}
+		client.GetCalls(func(_ context.Context, _ types.NamespacedName, object crc.Object) error {
+			switch object := object.(type) {
+			case *build.Build:
+				buildSample.DeepCopyInto(object)
+				return nil
+			}
+
+			return errors.NewNotFound(schema.GroupResource{}, "schema not found")
+		})
+		client.CreateCalls(func(_ context.Context, object crc.Object, _ ...crc.CreateOption) error {
+			created = append(created, object.(*build.BuildRun).DeepCopy())
+			return nil
+		})
+		client.ListCalls(func(_ context.Context, list crc.ObjectList, _ ...crc.ListOption) error {
+			list.(*build.BuildRunList).Items = buildRuns
+			return nil
+		})
+		client.DeleteCalls(func(_ context.Context, object crc.Object, _ ...crc.DeleteOption) error {
+			deleted = append(deleted, object.GetName())
+			return nil
+		})
+
+		statusWriter = &fakes.FakeStatusWriter{}
+		client.StatusCalls(func() crc.StatusWriter { return statusWriter })
+
+		manager = &fakes.FakeManager{}
+		manager.GetClientReturns(client)
+
+		recorder = record.NewFakeRecorder(100)
+		manager.GetEventRecorderForReturns(recorder)
+
+		fakeClock = clock.NewFakePassiveClock(created2021)
+	})
+
+	JustBeforeEach(func() {
+		reconciler = build_schedule.NewReconciler(config.NewDefaultConfig(), manager, fakeClock)
+	})
+
+	lastScheduleTime := func() time.Time {
+		Expect(statusWriter.UpdateCallCount()).To(Equal(1))
+		_, object, _ := statusWriter.UpdateArgsForCall(0)
+		return object.(*build.Build).Status.LastScheduleTime.UTC()
+	}
+
+	Context("before the first scheduled time", func() {
+		It("requeues the Build until the scheduled time", func() {
+			result, err := reconciler.Reconcile(context.TODO(), request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(14 * time.Hour))
+			Expect(created).To(BeEmpty())
+			Expect(statusWriter.UpdateCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("after the scheduled time", func() {
+		BeforeEach(func() {
+			fakeClock.SetTime(time.Date(2021, time.September, 15, 2, 0, 30, 0, time.UTC))
+		})
+
+		It("creates a BuildRun that is linked to the schedule", func() {
+			result, err := reconciler.Reconcile(context.TODO(), request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(24*time.Hour - 30*time.Second))
+
+			Expect(created).To(HaveLen(1))
+			Expect(created[0].Name).To(Equal("nightly-27194520"))
+			Expect(created[0].Spec.BuildRef.Name).To(Equal("nightly"))
+			Expect(created[0].Labels).To(HaveKeyWithValue(build.LabelBuildSchedule, "nightly"))
+			Expect(created[0].Annotations).To(HaveKeyWithValue(build.AnnotationBuildScheduledTime, "2021-09-15T02:00:00Z"))
+
+			Expect(lastScheduleTime()).To(Equal(time.Date(2021, time.September, 15, 2, 0, 0, 0, time.UTC)))
+			Expect(recorder.Events).To(Receive(Equal("Normal BuildRunCreated Created BuildRun nightly-27194520 for the scheduled time 2021-09-15T02:00:00Z")))
+		})
+
+		It("shortens the name of a Build that is too long for the name of the BuildRun", func() {
+			buildSample.Name = strings.Repeat("a", 60)
+			request.Name = buildSample.Name
+
+			_, err := reconciler.Reconcile(context.TODO(), request)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(created).To(HaveLen(1))
+			Expect(validation.IsValidLabelValue(created[0].Name)).To(BeEmpty())
+			Expect(created[0].Name).To(HavePrefix(strings.Repeat("a", 22)))
+			Expect(created[0].Name).To(HaveSuffix("-27194520"))
+			Expect(created[0].Spec.BuildRef.Name).To(Equal(buildSample.Name))
+			Expect(created[0].Labels).To(HaveKeyWithValue(build.LabelBuildSchedule, buildSample.Name))
+		})
+
+		It("does not create the BuildRun again", func() {
+			buildSample.Status.LastScheduleTime = &metav1.Time{Time: time.Date(2021, time.September, 15, 2, 0, 0, 0, time.UTC)}
+
+			_, err := reconciler.Reconcile(context.TODO(), request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(created).To(BeEmpty())
+		})
+
+		It("ignores that the BuildRun already exists", func() {
+			client.CreateReturns(errors.NewAlreadyExists(schema.GroupResource{}, "nightly-27194520"))
+
+			_, err := reconciler.Reconcile(context.TODO(), request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(statusWriter.UpdateCallCount()).To(Equal(1))
+		})
+
+		It("does not create BuildRuns for a Build that is not registered", func() {
+			buildSample.Status.Registered = build.ConditionStatusPtr(corev1.ConditionFalse)
+
+			_, err := reconciler.Reconcile(context.TODO(), request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(created).To(BeEmpty())
+		})
+	})
+
+	Context("after several missed scheduled times", func() {
+		BeforeEach(func() {
+			fakeClock.SetTime(time.Date(2021, time.September, 18, 9, 0, 0, 0, time.UTC))
+		})
+
+		It("creates one BuildRun for the most recent scheduled time", func() {
+			_, err := reconciler.Reconcile(context.TODO(), request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(created).To(HaveLen(1))
+			Expect(lastScheduleTime()).To(Equal(time.Date(2021, time.September, 18, 2, 0, 0, 0, time.UTC)))
+		})
+
+		It("skips the scheduled time after the starting deadline", func() {
+			buildSample.Spec.Schedule.StartingDeadline = &metav1.Duration{Duration: time.Hour}
+
+			result, err := reconciler.Reconcile(context.TODO(), request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(17 * time.Hour))
+			Expect(created).To(BeEmpty())
+		})
+	})
+
+	Context("with a time zone", func() {
+		It("interprets the schedule in the time zone", func() {
+			buildSample.Spec.Schedule.TimeZone = pointer.String("America/New_York")
+			fakeClock.SetTime(time.Date(2021, time.September, 15, 6, 30, 0, 0, time.UTC))
+
+			_, err := reconciler.Reconcile(context.TODO(), request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lastScheduleTime()).To(Equal(time.Date(2021, time.September, 15, 6, 0, 0, 0, time.UTC)))
+		})
+	})
+
+	Context("with history limits", func() {
+		completedBuildRun := func(name string, status corev1.ConditionStatus, completion time.Time) build.BuildRun {
+			buildRun := build.BuildRun{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+				Status: build.BuildRunStatus{
+					CompletionTime: &metav1.Time{Time: completion},
+				},
+			}
+			buildRun.Status.SetCondition(&build.Condition{Type: build.Succeeded, Status: status})
+			return buildRun
+		}
+
+		BeforeEach(func() {
+			limit := uint(1)
+			buildSample.Spec.Schedule.SucceededHistoryLimit = &limit
+			buildSample.Spec.Schedule.FailedHistoryLimit = &limit
+			buildRuns = []build.BuildRun{
+				completedBuildRun("nightly-3", corev1.ConditionTrue, created2021.Add(3*time.Hour)),
+				completedBuildRun("nightly-1", corev1.ConditionTrue, created2021.Add(time.Hour)),
+				completedBuildRun("nightly-2", corev1.ConditionFalse, created2021.Add(2*time.Hour)),
+				{ObjectMeta: metav1.ObjectMeta{Name: "nightly-4", Namespace: "default"}},
+			}
+		})
+
+		It("deletes the oldest completed BuildRuns", func() {
+			_, err := reconciler.Reconcile(context.TODO(), request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(deleted).To(ConsistOf("nightly-1"))
+			Expect(recorder.Events).To(Receive(Equal("Normal BuildRunDeleted Deleted BuildRun nightly-1 as the history limit of the schedule was reached")))
+		})
+	})
+})
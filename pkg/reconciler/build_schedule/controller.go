@@ -0,0 +1,108 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package build_schedule
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	build "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	"github.com/shipwright-io/build/pkg/config"
+	"github.com/shipwright-io/build/pkg/ctxlog"
+)
+
+const (
+	namespace string = "namespace"
+	name      string = "name"
+)
+
+// Add creates a new Build Schedule Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(ctx context.Context, c *config.Config, mgr manager.Manager) error {
+	ctx = ctxlog.NewContext(ctx, "build-schedule-controller")
+	return add(ctx, mgr, NewReconciler(c, mgr, clock.RealClock{}), c.Controllers.Build.MaxConcurrentReconciles)
+}
+
+func add(ctx context.Context, mgr manager.Manager, r reconcile.Reconciler, maxConcurrentReconciles int) error {
+	// Create the controller options
+	options := controller.Options{
+		Reconciler: r,
+	}
+
+	if maxConcurrentReconciles > 0 {
+		options.MaxConcurrentReconciles = maxConcurrentReconciles
+	}
+
+	// Create a new controller
+	c, err := controller.New("build-schedule-controller", mgr, options)
+	if err != nil {
+		return err
+	}
+
+	pred := predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return e.Object.(*build.Build).Spec.Schedule != nil
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			o := e.ObjectOld.(*build.Build)
+			n := e.ObjectNew.(*build.Build)
+
+			// ignore the updates of the last schedule time, the Build is requeued until its next scheduled time
+			return n.Spec.Schedule != nil &&
+				(o.GetGeneration() != n.GetGeneration() || !equality.Semantic.DeepEqual(o.Status.Registered, n.Status.Registered))
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			// Never reconcile on deletion, there is nothing we have to do
+			return false
+		},
+	}
+
+	predBuildRun := predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			// Never reconcile in case of create buildrun event
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			o := e.ObjectOld.(*build.BuildRun)
+			n := e.ObjectNew.(*build.BuildRun)
+
+			// reconcile when a scheduled BuildRun completed, to apply the history limits
+			_, scheduled := n.Labels[build.LabelBuildSchedule]
+			return scheduled && o.Status.CompletionTime == nil && n.Status.CompletionTime != nil
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			// Never reconcile on deletion, there is nothing we have to do
+			return false
+		},
+	}
+
+	// Watch for changes to primary resource Build
+	if err = c.Watch(&source.Kind{Type: &build.Build{}}, &handler.EnqueueRequestForObject{}, pred); err != nil {
+		return err
+	}
+
+	// Watch for changes to the BuildRuns that were created by the schedule of a Build
+	return c.Watch(&source.Kind{Type: &build.BuildRun{}}, handler.EnqueueRequestsFromMapFunc(func(o client.Object) []reconcile.Request {
+		return []reconcile.Request{
+			{
+				NamespacedName: types.NamespacedName{
+					Name:      o.GetLabels()[build.LabelBuildSchedule],
+					Namespace: o.GetNamespace(),
+				},
+			},
+		}
+	}), predBuildRun)
+}
@@ -7,10 +7,12 @@ package buildrun
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	"knative.dev/pkg/apis"
@@ -21,22 +23,33 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	buildv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	"github.com/shipwright-io/build/pkg/bundle"
 	"github.com/shipwright-io/build/pkg/config"
 	"github.com/shipwright-io/build/pkg/ctxlog"
+	"github.com/shipwright-io/build/pkg/logs"
 	buildmetrics "github.com/shipwright-io/build/pkg/metrics"
 	"github.com/shipwright-io/build/pkg/reconciler/buildrun/resources"
+	"github.com/shipwright-io/build/pkg/validate"
 )
 
 const (
 	namespace          string = "namespace"
 	name               string = "name"
 	generatedNameRegex        = "-[a-z0-9]{5,5}$"
+
+	// uploadReadinessInterval is the interval in which a BuildRun with a LocalCopy source is checked for the
+	// waiter to become ready
+	uploadReadinessInterval = 2 * time.Second
+
+	// queuedInterval is the interval in which a queued BuildRun is checked for whether it can start
+	queuedInterval = 5 * time.Second
 )
 
 // blank assignment to verify that ReconcileBuildRun implements reconcile.Reconciler
@@ -49,15 +62,19 @@ type ReconcileBuildRun struct {
 	config                *config.Config
 	client                client.Client
 	scheme                *runtime.Scheme
+	recorder              record.EventRecorder
+	archiver              *logs.Archiver
 	setOwnerReferenceFunc setOwnerReferenceFunc
 }
 
 // NewReconciler returns a new reconcile.Reconciler
-func NewReconciler(c *config.Config, mgr manager.Manager, ownerRef setOwnerReferenceFunc) reconcile.Reconciler {
+func NewReconciler(c *config.Config, mgr manager.Manager, ownerRef setOwnerReferenceFunc, archiver *logs.Archiver) reconcile.Reconciler {
 	return &ReconcileBuildRun{
 		config:                c,
 		client:                mgr.GetClient(),
 		scheme:                mgr.GetScheme(),
+		recorder:              mgr.GetEventRecorderFor("buildrun-controller"),
+		archiver:              archiver,
 		setOwnerReferenceFunc: ownerRef,
 	}
 }
@@ -100,9 +117,8 @@ func (r *ReconcileBuildRun) Reconcile(ctx context.Context, request reconcile.Req
 	// Validating buildrun name is a valid label value
 	if errs := validation.IsValidLabelValue(buildRun.Name); len(errs) > 0 {
 		// stop reconciling and mark the BuildRun as Failed
-		if updateErr := resources.UpdateConditionWithFalseStatus(
+		if updateErr := r.updateConditionWithFalseStatus(
 			ctx,
-			r.client,
 			buildRun,
 			strings.Join(errs, ", "),
 			resources.BuildRunNameInvalid,
@@ -113,6 +129,19 @@ func (r *ReconcileBuildRun) Reconcile(ctx context.Context, request reconcile.Req
 		return reconcile.Result{}, nil
 	}
 
+	// a completed BuildRun that is annotated to be rerun is run again by a new BuildRun, the annotation of a
+	// BuildRun that did not complete yet is removed, it would otherwise stay and rerun the BuildRun when the
+	// controller restarts
+	if getBuildRunErr == nil && resources.IsRerunRequested(buildRun) {
+		if buildRun.IsDone() {
+			return reconcile.Result{}, r.rerun(ctx, buildRun)
+		}
+
+		if err := r.ignoreRerun(ctx, buildRun); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
 	// if this is a build run event after we've set the task run ref, get the task run using the task run name stored in the build run
 	if getBuildRunErr == nil && apierrors.IsNotFound(getTaskRunErr) && buildRun.Status.LatestTaskRunRef != nil {
 		getTaskRunErr = r.client.Get(ctx, types.NamespacedName{Name: *buildRun.Status.LatestTaskRunRef, Namespace: request.Namespace}, lastTaskRun)
@@ -122,16 +151,45 @@ func (r *ReconcileBuildRun) Reconcile(ctx context.Context, request reconcile.Req
 	if getTaskRunErr != nil {
 		if apierrors.IsNotFound(getTaskRunErr) {
 
+			// a BuildRun that was skipped because its inputs did not change has no TaskRun
+			if resources.IsUpToDate(buildRun) {
+				ctxlog.Debug(ctx, "buildRun is up to date", namespace, request.Namespace, name, request.Name)
+				return reconcile.Result{}, nil
+			}
+
+			// a failed attempt is retried once its backoff passed
+			if wait := resources.RetryBackoffRemaining(buildRun); wait > 0 && !buildRun.IsCanceled() {
+				ctxlog.Debug(ctx, "waiting for the backoff of the retry", namespace, request.Namespace, name, request.Name, "remaining", wait)
+				return reconcile.Result{RequeueAfter: wait}, nil
+			}
+
 			build = &buildv1alpha1.Build{}
 			err := resources.GetBuildObject(ctx, r.client, buildRun, build)
 			if err != nil {
 				if !resources.IsClientStatusUpdateError(err) && buildRun.Status.IsFailed(buildv1alpha1.Succeeded) {
+					r.recordConditionEvent(buildRun)
 					return reconcile.Result{}, nil
 				}
 				// system call failure, reconcile again
 				return reconcile.Result{}, err
 			}
 
+			// Validate the Build spec that is embedded in the BuildRun, the result is registered
+			// in the status of the in-memory Build
+			if buildRun.HasEmbeddedBuildSpec() {
+				if err := validate.ValidateEmbeddedBuild(ctx, build, r.client, r.scheme); err != nil {
+					return reconcile.Result{}, err
+				}
+
+				if *build.Status.Registered != corev1.ConditionTrue {
+					if updateErr := r.updateConditionWithFalseStatus(ctx, buildRun, pointer.StringDeref(build.Status.Message, ""), string(*build.Status.Reason)); updateErr != nil {
+						return reconcile.Result{}, updateErr
+					}
+
+					return reconcile.Result{}, nil
+				}
+			}
+
 			// Validate if the Build was successfully registered
 			if build.Status.Registered == nil || *build.Status.Registered == "" {
 				err := fmt.Errorf("the Build is not yet validated, build: %s", build.Name)
@@ -149,7 +207,7 @@ func (r *ReconcileBuildRun) Reconcile(ctx context.Context, request reconcile.Req
 				}
 
 				message := fmt.Sprintf("the Build is not registered correctly, build: %s, registered status: %s, reason: %s", build.Name, *build.Status.Registered, reason)
-				if updateErr := resources.UpdateConditionWithFalseStatus(ctx, r.client, buildRun, message, resources.ConditionBuildRegistrationFailed); updateErr != nil {
+				if updateErr := r.updateConditionWithFalseStatus(ctx, buildRun, message, resources.ConditionBuildRegistrationFailed); updateErr != nil {
 					return reconcile.Result{}, updateErr
 				}
 
@@ -163,7 +221,7 @@ func (r *ReconcileBuildRun) Reconcile(ctx context.Context, request reconcile.Req
 
 			// make sure the BuildRun has not already been cancelled
 			if buildRun.IsCanceled() {
-				if updateErr := resources.UpdateConditionWithFalseStatus(ctx, r.client, buildRun, "the BuildRun is marked canceled.", buildv1alpha1.BuildRunStateCancel); updateErr != nil {
+				if updateErr := r.updateConditionWithFalseStatus(ctx, buildRun, "the BuildRun is marked canceled.", buildv1alpha1.BuildRunStateCancel); updateErr != nil {
 					return reconcile.Result{}, updateErr
 				}
 				return reconcile.Result{}, nil
@@ -183,7 +241,7 @@ func (r *ReconcileBuildRun) Reconcile(ctx context.Context, request reconcile.Req
 			}
 
 			buildGeneration := strconv.FormatInt(build.Generation, 10)
-			if buildRun.GetLabels()[buildv1alpha1.LabelBuild] != build.Name || buildRun.GetLabels()[buildv1alpha1.LabelBuildGeneration] != buildGeneration {
+			if !buildRun.HasEmbeddedBuildSpec() && (buildRun.GetLabels()[buildv1alpha1.LabelBuild] != build.Name || buildRun.GetLabels()[buildv1alpha1.LabelBuildGeneration] != buildGeneration) {
 				buildRun.Labels[buildv1alpha1.LabelBuild] = build.Name
 				buildRun.Labels[buildv1alpha1.LabelBuildGeneration] = buildGeneration
 				ctxlog.Info(ctx, "updating BuildRun labels", namespace, request.Namespace, name, request.Name)
@@ -197,8 +255,30 @@ func (r *ReconcileBuildRun) Reconcile(ctx context.Context, request reconcile.Req
 				ctxlog.Info(ctx, fmt.Sprintf("successfully updated BuildRun %s", buildRun.Name), namespace, request.Namespace, name, request.Name)
 			}
 
-			// Set the Build spec in the BuildRun status
+			// Queue the BuildRun while the concurrency policy of the Build or the limit of running
+			// BuildRuns in the namespace do not allow it to start
+			queued, message, err := resources.IsQueued(ctx, r.client, r.config, build, buildRun)
+			if err != nil {
+				return reconcile.Result{}, err
+			}
+			if queued {
+				if resources.UpdateBuildRunQueuedCondition(buildRun, message) {
+					ctxlog.Info(ctx, "queueing BuildRun", namespace, request.Namespace, name, request.Name, "reason", message)
+					if err := r.client.Status().Update(ctx, buildRun); err != nil {
+						return reconcile.Result{}, err
+					}
+
+					r.recordConditionEvent(buildRun)
+				}
+				return reconcile.Result{RequeueAfter: queuedInterval}, nil
+			}
+
+			// Pin the sources of the Build for a BuildRun that reruns another BuildRun
+			resources.PinSources(build, buildRun)
+
+			// Set the Build spec in the BuildRun status, and link a BuildRun that reruns another BuildRun
 			buildRun.Status.BuildSpec = &build.Spec
+			buildRun.Status.RerunOf = buildRun.GetLabels()[buildv1alpha1.LabelBuildRunRerunOf]
 			ctxlog.Info(ctx, "updating BuildRun status", namespace, request.Namespace, name, request.Name)
 			if err = r.client.Status().Update(ctx, buildRun); err != nil {
 				return reconcile.Result{}, err
@@ -208,6 +288,7 @@ func (r *ReconcileBuildRun) Reconcile(ctx context.Context, request reconcile.Req
 			svcAccount, err := resources.RetrieveServiceAccount(ctx, r.client, build, buildRun)
 			if err != nil {
 				if !resources.IsClientStatusUpdateError(err) && buildRun.Status.IsFailed(buildv1alpha1.Succeeded) {
+					r.recordConditionEvent(buildRun)
 					return reconcile.Result{}, nil
 				}
 				// system call failure, reconcile again
@@ -225,12 +306,46 @@ func (r *ReconcileBuildRun) Reconcile(ctx context.Context, request reconcile.Req
 			// Validate the parameters
 			valid, reason, message := resources.ValidateBuildRunParameters(strategy.GetParameters(), build.Spec.ParamValues, buildRun.Spec.ParamValues)
 			if !valid {
-				if err := resources.UpdateConditionWithFalseStatus(ctx, r.client, buildRun, message, reason); err != nil {
+				if err := r.updateConditionWithFalseStatus(ctx, buildRun, message, reason); err != nil {
 					return reconcile.Result{}, err
 				}
 				return reconcile.Result{}, nil
 			}
 
+			// Complete the BuildRun without a TaskRun if its inputs equal the ones of the last succeeded BuildRun
+			if resources.IsSkipIfUnchanged(build, buildRun) {
+				fingerprint, err := resources.ComputeFingerprint(ctx, r.client, build, buildRun, strategy)
+				if err != nil {
+					ctxlog.Info(ctx, "the inputs of the BuildRun can not be resolved, it is not skipped", namespace, request.Namespace, name, request.Name, "reason", err.Error())
+				} else {
+					previous, err := resources.LastSucceededBuildRun(ctx, r.client, build, buildRun)
+					if err != nil {
+						return reconcile.Result{}, err
+					}
+
+					buildRun.Status.Fingerprint = fingerprint
+					if previous != nil && previous.Status.Fingerprint.Digest == fingerprint.Digest {
+						ctxlog.Info(ctx, "skipping BuildRun as its inputs did not change", namespace, request.Namespace, name, request.Name, "previous", previous.Name)
+						resources.MarkBuildRunUpToDate(buildRun, previous)
+						if err := r.client.Status().Update(ctx, buildRun); err != nil {
+							return reconcile.Result{}, err
+						}
+
+						r.recordConditionEvent(buildRun)
+
+						return reconcile.Result{}, nil
+					}
+				}
+			}
+
+			// Create the token for the upload of a LocalCopy source
+			if err := resources.EnsureUploadToken(ctx, r.client, r.config, build, buildRun); err != nil {
+				if errors.Is(err, resources.ErrUploadTokenNotOwned) {
+					return reconcile.Result{}, r.updateConditionWithFalseStatus(ctx, buildRun, err.Error(), resources.ConditionUploadTokenNotOwned)
+				}
+				return reconcile.Result{}, err
+			}
+
 			// Create the TaskRun, this needs to be the last step in this block to be idempotent
 			generatedTaskRun, err := r.createTaskRun(ctx, svcAccount, strategy, build, buildRun)
 			if err != nil {
@@ -248,6 +363,8 @@ func (r *ReconcileBuildRun) Reconcile(ctx context.Context, request reconcile.Req
 				return reconcile.Result{}, err
 			}
 
+			r.recorder.Eventf(buildRun, corev1.EventTypeNormal, buildv1alpha1.EventReasonTaskRunCreated, "Created TaskRun %s", generatedTaskRun.Name)
+
 			// Set the LastTaskRunRef in the BuildRun status
 			buildRun.Status.LatestTaskRunRef = &generatedTaskRun.Name
 			ctxlog.Info(ctx, "updating BuildRun status with TaskRun name", namespace, request.Namespace, name, request.Name, "TaskRun", generatedTaskRun.Name)
@@ -258,22 +375,25 @@ func (r *ReconcileBuildRun) Reconcile(ctx context.Context, request reconcile.Req
 				ctxlog.Error(ctx, err, "Failed to update BuildRun status is ignored", namespace, request.Namespace, name, request.Name)
 			}
 
-			// Increase BuildRun count in metrics
-			buildmetrics.BuildRunCountInc(
-				buildRun.Status.BuildSpec.StrategyName(),
-				buildRun.Namespace,
-				buildRun.Spec.BuildRef.Name,
-				buildRun.Name,
-			)
-
-			// Report buildrun ramp-up duration (time between buildrun creation and taskrun creation)
-			buildmetrics.BuildRunRampUpDurationObserve(
-				buildRun.Status.BuildSpec.StrategyName(),
-				buildRun.Namespace,
-				buildRun.Spec.BuildRef.Name,
-				buildRun.Name,
-				generatedTaskRun.CreationTimestamp.Time.Sub(buildRun.CreationTimestamp.Time),
-			)
+			// Retries are not counted as further BuildRuns
+			if len(buildRun.Status.Attempts) == 0 {
+				// Increase BuildRun count in metrics
+				buildmetrics.BuildRunCountInc(
+					buildRun.Status.BuildSpec.StrategyName(),
+					buildRun.Namespace,
+					buildRun.BuildName(),
+					buildRun.Name,
+				)
+
+				// Report buildrun ramp-up duration (time between buildrun creation and taskrun creation)
+				buildmetrics.BuildRunRampUpDurationObserve(
+					buildRun.Status.BuildSpec.StrategyName(),
+					buildRun.Namespace,
+					buildRun.BuildName(),
+					buildRun.Name,
+					generatedTaskRun.CreationTimestamp.Time.Sub(buildRun.CreationTimestamp.Time),
+				)
+			}
 		} else {
 			return reconcile.Result{}, getTaskRunErr
 		}
@@ -293,6 +413,12 @@ func (r *ReconcileBuildRun) Reconcile(ctx context.Context, request reconcile.Req
 			}
 		}
 
+		// the TaskRun of a failed attempt does not change the BuildRun after it was retried
+		if resources.IsPreviousAttempt(buildRun, lastTaskRun.Name) {
+			ctxlog.Debug(ctx, "taskRun belongs to a previous attempt", namespace, request.Namespace, name, request.Name)
+			return reconcile.Result{}, nil
+		}
+
 		if buildRun.IsCanceled() && !lastTaskRun.IsCancelled() {
 			ctxlog.Info(ctx, "buildRun marked for cancellation, patching task run", namespace, request.Namespace, name, request.Name)
 			// patch tekton taskrun a la tkn to start tekton's cancelling logic
@@ -300,6 +426,8 @@ func (r *ReconcileBuildRun) Reconcile(ctx context.Context, request reconcile.Req
 			if err := r.patchTaskRun(ctx, lastTaskRun, "replace", "/spec/status", v1beta1.TaskRunSpecStatusCancelled, metav1.PatchOptions{Force: &trueParam}); err != nil {
 				return reconcile.Result{}, err
 			}
+
+			r.recorder.Eventf(buildRun, corev1.EventTypeNormal, buildv1alpha1.BuildRunStateCancel, "Requested the cancellation of TaskRun %s", lastTaskRun.Name)
 		}
 
 		// Check if the BuildRun is already finished, this happens if the build controller is restarted.
@@ -314,6 +442,7 @@ func (r *ReconcileBuildRun) Reconcile(ctx context.Context, request reconcile.Req
 		if len(lastTaskRun.Status.TaskRunResults) > 0 {
 			ctxlog.Info(ctx, "surfacing taskRun results to BuildRun status", namespace, request.Namespace, name, request.Name)
 			resources.UpdateBuildRunUsingTaskResults(ctx, buildRun, lastTaskRun.Status.TaskRunResults, request)
+			resources.VerifyFingerprintRevisions(buildRun)
 		}
 
 		trCondition := lastTaskRun.Status.GetCondition(apis.ConditionSucceeded)
@@ -323,8 +452,33 @@ func (r *ReconcileBuildRun) Reconcile(ctx context.Context, request reconcile.Req
 			}
 
 			resources.UpdateBuildRunUsingTaskFailures(ctx, r.client, buildRun, lastTaskRun)
+			resources.UpdateBuildRunUsingTaskRunSteps(buildRun, lastTaskRun)
 			taskRunStatus := trCondition.Status
 
+			// a failed attempt with a retryable reason is recorded, the next attempt is created
+			// with a new TaskRun when the BuildRun is reconciled after the backoff
+			if taskRunStatus == corev1.ConditionFalse && resources.IsRetryable(buildRun) {
+				// the pod of the failed attempt is not archived when the BuildRun completes
+				var attemptLogs *buildv1alpha1.BuildRunLogs
+				if r.archiver != nil && lastTaskRun.Status.PodName != "" {
+					archived, err := r.archiver.Archive(ctx, buildRun, lastTaskRun)
+					if err != nil {
+						ctxlog.Error(ctx, err, "failed to archive the logs of the failed attempt", namespace, request.Namespace, name, request.Name, "TaskRun", lastTaskRun.Name)
+					}
+					attemptLogs = archived
+				}
+
+				resources.RecordRetryAttempt(buildRun, lastTaskRun, attemptLogs)
+				ctxlog.Info(ctx, "retrying BuildRun", namespace, request.Namespace, name, request.Name, "attempt", len(buildRun.Status.Attempts), "TaskRun", lastTaskRun.Name)
+				if err := r.client.Status().Update(ctx, buildRun); err != nil {
+					return reconcile.Result{}, err
+				}
+
+				r.recordConditionEvent(buildRun)
+
+				return reconcile.Result{}, nil
+			}
+
 			// check if we should delete the generated service account by checking the build run spec and that the task run is complete
 			if taskRunStatus == corev1.ConditionTrue || taskRunStatus == corev1.ConditionFalse {
 				if err := resources.DeleteServiceAccount(ctx, r.client, buildRun); err != nil {
@@ -342,7 +496,7 @@ func (r *ReconcileBuildRun) Reconcile(ctx context.Context, request reconcile.Req
 				buildmetrics.BuildRunEstablishObserve(
 					buildRun.Status.BuildSpec.StrategyName(),
 					buildRun.Namespace,
-					buildRun.Spec.BuildRef.Name,
+					buildRun.BuildName(),
 					buildRun.Name,
 					buildRun.Status.StartTime.Time.Sub(buildRun.CreationTimestamp.Time),
 				)
@@ -355,11 +509,25 @@ func (r *ReconcileBuildRun) Reconcile(ctx context.Context, request reconcile.Req
 				buildmetrics.BuildRunCompletionObserve(
 					buildRun.Status.BuildSpec.StrategyName(),
 					buildRun.Namespace,
-					buildRun.Spec.BuildRef.Name,
+					buildRun.BuildName(),
 					buildRun.Name,
 					buildRun.Status.CompletionTime.Time.Sub(buildRun.CreationTimestamp.Time),
 				)
 
+				// step durations (time between the start and the completion of each step of the taskrun)
+				for _, step := range buildRun.Status.Steps {
+					if duration, ok := resources.StepDuration(step); ok {
+						buildmetrics.BuildRunStepDurationObserve(
+							buildRun.Status.BuildSpec.StrategyName(),
+							buildRun.Namespace,
+							buildRun.BuildName(),
+							buildRun.Name,
+							step.Name,
+							duration,
+						)
+					}
+				}
+
 				// Look for the pod created by the taskrun
 				var pod = &corev1.Pod{}
 				if err := r.client.Get(ctx, types.NamespacedName{Namespace: request.Namespace, Name: lastTaskRun.Status.PodName}, pod); err == nil {
@@ -373,7 +541,7 @@ func (r *ReconcileBuildRun) Reconcile(ctx context.Context, request reconcile.Req
 							buildmetrics.TaskRunPodRampUpDurationObserve(
 								buildRun.Status.BuildSpec.StrategyName(),
 								buildRun.Namespace,
-								buildRun.Spec.BuildRef.Name,
+								buildRun.BuildName(),
 								buildRun.Name,
 								lastInitPod.State.Terminated.FinishedAt.Sub(pod.CreationTimestamp.Time),
 							)
@@ -384,17 +552,47 @@ func (r *ReconcileBuildRun) Reconcile(ctx context.Context, request reconcile.Req
 					buildmetrics.TaskRunRampUpDurationObserve(
 						buildRun.Status.BuildSpec.StrategyName(),
 						buildRun.Namespace,
-						buildRun.Spec.BuildRef.Name,
+						buildRun.BuildName(),
 						buildRun.Name,
 						pod.CreationTimestamp.Time.Sub(lastTaskRun.CreationTimestamp.Time),
 					)
 				}
+
+				// archive the logs of the steps before the pod is removed, a failure does not fail the BuildRun
+				if r.archiver != nil {
+					if buildRunLogs, err := r.archiver.Archive(ctx, buildRun, lastTaskRun); err != nil {
+						ctxlog.Error(ctx, err, "failed to archive the logs of the BuildRun", namespace, request.Namespace, name, request.Name)
+					} else {
+						buildRun.Status.Logs = buildRunLogs
+					}
+				}
+
+				// the bundle image that was kept for a retry is not needed anymore, like in the bundle
+				// step, a registry that does not support deletion does not fail the BuildRun
+				switch err := resources.PruneBundleImage(ctx, r.client, buildRun); {
+				case errors.Is(err, bundle.ErrDeletionUnsupported):
+					ctxlog.Info(ctx, "the registry does not support the deletion of the bundle image", namespace, request.Namespace, name, request.Name, "error", err.Error())
+				case err != nil:
+					ctxlog.Error(ctx, err, "failed to delete the bundle image", namespace, request.Namespace, name, request.Name)
+				}
 			}
 
 			ctxlog.Info(ctx, "updating buildRun status", namespace, request.Namespace, name, request.Name)
 			if err := r.client.Status().Update(ctx, buildRun); err != nil {
 				return reconcile.Result{}, err
 			}
+
+			// the BuildRun was completed by this reconciliation, it returns early once it is completed
+			if buildRun.Status.CompletionTime != nil {
+				r.recordConditionEvent(buildRun)
+			}
+
+			// the waiter becoming ready does not change the TaskRun, it is therefore checked
+			// again until the BuildRun is reported to wait for the upload
+			if buildRun.Status.GetCondition(buildv1alpha1.Succeeded).GetReason() != resources.ConditionWaitingForUpload &&
+				resources.IsAwaitingUpload(buildRun, lastTaskRun) {
+				return reconcile.Result{RequeueAfter: uploadReadinessInterval}, nil
+			}
 		}
 	}
 
@@ -428,7 +626,7 @@ func (r *ReconcileBuildRun) VerifyRequestName(ctx context.Context, request recon
 				// We ignore the errors from the following call, because the parent call of this function will always
 				// return back a reconcile.Result{}, nil. This is done to avoid infinite reconcile loops when a BuildRun
 				// does not longer exists
-				_ = resources.UpdateConditionWithFalseStatus(ctx, r.client, buildRun, fmt.Sprintf("taskRun %s doesn't exist", request.Name), resources.ConditionTaskRunIsMissing)
+				_ = r.updateConditionWithFalseStatus(ctx, buildRun, fmt.Sprintf("taskRun %s doesn't exist", request.Name), resources.ConditionTaskRunIsMissing)
 			}
 		}
 	}
@@ -441,7 +639,7 @@ func (r *ReconcileBuildRun) getReferencedStrategy(ctx context.Context, build *bu
 		strategy, err = resources.RetrieveBuildStrategy(ctx, r.client, build)
 		if err != nil {
 			if apierrors.IsNotFound(err) {
-				if updateErr := resources.UpdateConditionWithFalseStatus(ctx, r.client, buildRun, err.Error(), resources.BuildStrategyNotFound); updateErr != nil {
+				if updateErr := r.updateConditionWithFalseStatus(ctx, buildRun, err.Error(), resources.BuildStrategyNotFound); updateErr != nil {
 					return nil, resources.HandleError("failed to get referenced strategy", err, updateErr)
 				}
 			}
@@ -454,7 +652,7 @@ func (r *ReconcileBuildRun) getReferencedStrategy(ctx context.Context, build *bu
 		strategy, err = resources.RetrieveBuildStrategy(ctx, r.client, build)
 		if err != nil {
 			if apierrors.IsNotFound(err) {
-				if updateErr := resources.UpdateConditionWithFalseStatus(ctx, r.client, buildRun, err.Error(), resources.BuildStrategyNotFound); updateErr != nil {
+				if updateErr := r.updateConditionWithFalseStatus(ctx, buildRun, err.Error(), resources.BuildStrategyNotFound); updateErr != nil {
 					return nil, resources.HandleError("failed to get referenced strategy", err, updateErr)
 				}
 			}
@@ -463,14 +661,14 @@ func (r *ReconcileBuildRun) getReferencedStrategy(ctx context.Context, build *bu
 		strategy, err = resources.RetrieveClusterBuildStrategy(ctx, r.client, build)
 		if err != nil {
 			if apierrors.IsNotFound(err) {
-				if updateErr := resources.UpdateConditionWithFalseStatus(ctx, r.client, buildRun, err.Error(), resources.ClusterBuildStrategyNotFound); updateErr != nil {
+				if updateErr := r.updateConditionWithFalseStatus(ctx, buildRun, err.Error(), resources.ClusterBuildStrategyNotFound); updateErr != nil {
 					return nil, resources.HandleError("failed to get referenced strategy", err, updateErr)
 				}
 			}
 		}
 	default:
 		err = fmt.Errorf("unknown strategy %s", string(*build.Spec.Strategy.Kind))
-		if updateErr := resources.UpdateConditionWithFalseStatus(ctx, r.client, buildRun, err.Error(), resources.ConditionUnknownStrategyKind); updateErr != nil {
+		if updateErr := r.updateConditionWithFalseStatus(ctx, buildRun, err.Error(), resources.ConditionUnknownStrategyKind); updateErr != nil {
 			return nil, resources.HandleError("failed to get referenced strategy", err, updateErr)
 		}
 	}
@@ -485,7 +683,7 @@ func (r *ReconcileBuildRun) createTaskRun(ctx context.Context, serviceAccount *c
 
 	generatedTaskRun, err := resources.GenerateTaskRun(r.config, build, buildRun, serviceAccount.Name, strategy)
 	if err != nil {
-		if updateErr := resources.UpdateConditionWithFalseStatus(ctx, r.client, buildRun, err.Error(), resources.ConditionTaskRunGenerationFailed); updateErr != nil {
+		if updateErr := r.updateConditionWithFalseStatus(ctx, buildRun, err.Error(), resources.ConditionTaskRunGenerationFailed); updateErr != nil {
 			return nil, resources.HandleError("failed to create taskrun runtime object", err, updateErr)
 		}
 
@@ -494,7 +692,7 @@ func (r *ReconcileBuildRun) createTaskRun(ctx context.Context, serviceAccount *c
 
 	// Set OwnerReference for BuildRun and TaskRun
 	if err := r.setOwnerReferenceFunc(buildRun, generatedTaskRun, r.scheme); err != nil {
-		if updateErr := resources.UpdateConditionWithFalseStatus(ctx, r.client, buildRun, err.Error(), resources.ConditionSetOwnerReferenceFailed); updateErr != nil {
+		if updateErr := r.updateConditionWithFalseStatus(ctx, buildRun, err.Error(), resources.ConditionSetOwnerReferenceFailed); updateErr != nil {
 			return nil, resources.HandleError("failed to create taskrun runtime object", err, updateErr)
 		}
 
@@ -504,6 +702,77 @@ func (r *ReconcileBuildRun) createTaskRun(ctx context.Context, serviceAccount *c
 	return generatedTaskRun, nil
 }
 
+// rerun creates a new BuildRun that runs the BuildRun again. The annotation is removed first, so that
+// a reconciliation with an outdated BuildRun fails to update it instead of creating another BuildRun.
+func (r *ReconcileBuildRun) rerun(ctx context.Context, buildRun *buildv1alpha1.BuildRun) error {
+	number := resources.NextRerunNumber(buildRun)
+	rerun := resources.NewRerunBuildRun(buildRun)
+
+	// the BuildRun is created before the annotation is removed, so that a failed update is retried, the name of the
+	// new BuildRun is the same for a retry and an existing one is the one that was created before
+	ctxlog.Info(ctx, "creating BuildRun to rerun BuildRun", namespace, buildRun.Namespace, name, buildRun.Name)
+	if err := r.client.Create(ctx, rerun); err != nil {
+		if !apierrors.IsAlreadyExists(err) {
+			return err
+		}
+
+		existing := &buildv1alpha1.BuildRun{}
+		if err := r.client.Get(ctx, types.NamespacedName{Namespace: rerun.Namespace, Name: rerun.Name}, existing); err != nil {
+			return err
+		}
+
+		if existing.GetLabels()[buildv1alpha1.LabelBuildRunRerunOf] != buildRun.Name || existing.CreationTimestamp.Before(&buildRun.CreationTimestamp) {
+			return fmt.Errorf("the BuildRun %s exists already, but was not created to rerun BuildRun %s", rerun.Name, buildRun.Name)
+		}
+	} else {
+		r.recorder.Eventf(buildRun, corev1.EventTypeNormal, buildv1alpha1.EventReasonBuildRunCreated, "Created BuildRun %s to rerun this BuildRun", rerun.Name)
+	}
+
+	delete(buildRun.Annotations, buildv1alpha1.AnnotationBuildRunRerun)
+	buildRun.Annotations[buildv1alpha1.AnnotationBuildRunReruns] = strconv.Itoa(number)
+	return r.client.Update(ctx, buildRun)
+}
+
+// ignoreRerun removes the annotation to rerun a BuildRun that did not complete yet, and records an event so that
+// the user knows to annotate the BuildRun again once it completed
+func (r *ReconcileBuildRun) ignoreRerun(ctx context.Context, buildRun *buildv1alpha1.BuildRun) error {
+	ctxlog.Info(ctx, "ignoring the rerun of a BuildRun that did not complete yet", namespace, buildRun.Namespace, name, buildRun.Name)
+
+	delete(buildRun.Annotations, buildv1alpha1.AnnotationBuildRunRerun)
+	if err := r.client.Update(ctx, buildRun); err != nil {
+		return err
+	}
+
+	r.recorder.Event(buildRun, corev1.EventTypeWarning, buildv1alpha1.EventReasonRerunIgnored, "The BuildRun did not complete yet, annotate it again to rerun it once it completed")
+	return nil
+}
+
+// updateConditionWithFalseStatus marks the BuildRun as failed and records an event for the failure
+func (r *ReconcileBuildRun) updateConditionWithFalseStatus(ctx context.Context, buildRun *buildv1alpha1.BuildRun, errorMessage string, reason string) error {
+	if err := resources.UpdateConditionWithFalseStatus(ctx, r.client, buildRun, errorMessage, reason); err != nil {
+		return err
+	}
+
+	r.recordConditionEvent(buildRun)
+	return nil
+}
+
+// recordConditionEvent records an event with the reason and message of the Succeeded condition of the BuildRun,
+// failures and retries are warnings
+func (r *ReconcileBuildRun) recordConditionEvent(buildRun *buildv1alpha1.BuildRun) {
+	condition := buildRun.Status.GetCondition(buildv1alpha1.Succeeded)
+	if condition == nil {
+		return
+	}
+
+	eventType := corev1.EventTypeNormal
+	if condition.Status == corev1.ConditionFalse || condition.Reason == resources.ConditionRetrying {
+		eventType = corev1.EventTypeWarning
+	}
+
+	r.recorder.Event(buildRun, eventType, condition.Reason, condition.Message)
+}
+
 type patchStringValue struct {
 	Op    string `json:"op"`
 	Path  string `json:"path"`
@@ -7,6 +7,8 @@ package buildrun_test
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"time"
 
 	. "github.com/onsi/ginkgo"
@@ -19,6 +21,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/pointer"
 	knativeapi "knative.dev/pkg/apis"
 	knativev1beta1 "knative.dev/pkg/apis/duck/v1beta1"
@@ -30,6 +33,7 @@ import (
 	build "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
 	"github.com/shipwright-io/build/pkg/config"
 	"github.com/shipwright-io/build/pkg/controller/fakes"
+	"github.com/shipwright-io/build/pkg/logs"
 	buildrunctl "github.com/shipwright-io/build/pkg/reconciler/buildrun"
 	"github.com/shipwright-io/build/pkg/reconciler/buildrun/resources"
 	"github.com/shipwright-io/build/test"
@@ -38,6 +42,7 @@ import (
 var _ = Describe("Reconcile BuildRun", func() {
 	var (
 		manager                                                *fakes.FakeManager
+		recorder                                               *record.FakeRecorder
 		reconciler                                             reconcile.Reconciler
 		taskRunRequest, buildRunRequest                        reconcile.Request
 		client                                                 *fakes.FakeClient
@@ -100,6 +105,9 @@ var _ = Describe("Reconcile BuildRun", func() {
 		client.StatusCalls(func() crc.StatusWriter { return statusWriter })
 		manager.GetClientReturns(client)
 
+		recorder = record.NewFakeRecorder(100)
+		manager.GetEventRecorderForReturns(recorder)
+
 		// init the Build resource, this never change throughout this test suite
 		buildSample = ctl.DefaultBuild(buildName, strategyName, build.ClusterBuildStrategyKind)
 		buildRunSample = ctl.DefaultBuildRun(buildRunName, buildName)
@@ -110,7 +118,7 @@ var _ = Describe("Reconcile BuildRun", func() {
 	// this ensures that overrides on the BuildRun resource can happen under each
 	// Context() BeforeEach() block
 	JustBeforeEach(func() {
-		reconciler = buildrunctl.NewReconciler(config.NewDefaultConfig(), manager, controllerutil.SetControllerReference)
+		reconciler = buildrunctl.NewReconciler(config.NewDefaultConfig(), manager, controllerutil.SetControllerReference, nil)
 	})
 
 	Describe("Reconciling", func() {
@@ -340,6 +348,32 @@ var _ = Describe("Reconcile BuildRun", func() {
 				Expect(client.StatusCallCount()).To(Equal(1))
 			})
 
+			It("archives the logs of the BuildRun when it completes", func() {
+				directory, err := os.MkdirTemp("", "logs")
+				Expect(err).ToNot(HaveOccurred())
+				defer os.RemoveAll(directory)
+
+				taskRunSample = ctl.DefaultTaskRunWithStatus(taskRunName, buildRunName, ns, corev1.ConditionTrue, "Succeeded")
+				taskRunSample.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+				taskRunSample.Status.PodName = "foobar-pod"
+				reconciler = buildrunctl.NewReconciler(config.NewDefaultConfig(), manager, controllerutil.SetControllerReference, logs.NewArchiver(logs.NewPVCSink(directory), nil))
+
+				var buildRunLogs *build.BuildRunLogs
+				statusWriter.UpdateCalls(func(_ context.Context, object crc.Object, _ ...crc.UpdateOption) error {
+					if buildRun, ok := object.(*build.BuildRun); ok {
+						buildRunLogs = buildRun.Status.Logs
+					}
+					return nil
+				})
+
+				_, err = reconciler.Reconcile(context.TODO(), taskRunRequest)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(buildRunLogs).To(Equal(&build.BuildRunLogs{
+					Sink:      config.LogsSinkPVC,
+					Reference: filepath.Join(directory, buildRunSample.Namespace, buildRunName, taskRunSample.Name+".log"),
+				}))
+			})
+
 			It("should recognize the BuildRun is canceled", func() {
 				// set cancel
 				buildRunSampleCopy := buildRunSample.DeepCopy()
@@ -516,6 +550,156 @@ var _ = Describe("Reconcile BuildRun", func() {
 			})
 		})
 
+		Context("retrying a failed BuildRun", func() {
+			var evictedPod *corev1.Pod
+
+			BeforeEach(func() {
+				taskRunRequest = newReconcileRequest(taskRunName, ns)
+				buildRunRequest = newReconcileRequest(buildRunName, ns)
+
+				buildRunSample = ctl.BuildRunWithBuildSnapshot(buildRunName, buildName)
+				buildRunSample.Labels = map[string]string{build.LabelBuild: buildName}
+				buildRunSample.Spec.Retry = &build.RetryPolicy{MaxAttempts: 2}
+
+				taskRunSample = ctl.DefaultTaskRunWithFalseStatus(taskRunName, buildRunName, ns)
+				taskRunSample.Status.PodName = "foobar"
+				taskRunSample.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+				taskRunSample.Status.PodName = "foobar-pod"
+				taskRunSample.Status.Conditions[0].Reason = string(v1beta1.TaskRunReasonFailed)
+
+				evictedPod = &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Name: "foobar", Namespace: ns},
+					Status: corev1.PodStatus{
+						Reason:  "Evicted",
+						Message: "The node was low on resource: memory.",
+					},
+				}
+			})
+
+			JustBeforeEach(func() {
+				client.GetCalls(ctl.StubBuildCRDsPodAndTaskRun(
+					buildSample,
+					buildRunSample,
+					ctl.DefaultServiceAccount("foobar"),
+					ctl.DefaultClusterBuildStrategy(),
+					ctl.DefaultNamespacedBuildStrategy(),
+					taskRunSample,
+					evictedPod,
+				))
+			})
+
+			It("records the failed attempt of an evicted pod and waits for the retry", func() {
+				var updated *build.BuildRun
+				statusWriter.UpdateCalls(func(_ context.Context, object crc.Object, _ ...crc.UpdateOption) error {
+					updated = object.(*build.BuildRun).DeepCopy()
+					return nil
+				})
+
+				result, err := reconciler.Reconcile(context.TODO(), taskRunRequest)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result).To(Equal(reconcile.Result{}))
+
+				Expect(updated).ToNot(BeNil())
+				Expect(updated.Status.CompletionTime).To(BeNil())
+				Expect(updated.Status.LatestTaskRunRef).To(BeNil())
+				Expect(updated.Status.Attempts).To(HaveLen(1))
+				Expect(updated.Status.Attempts[0].TaskRunName).To(Equal(taskRunName))
+				Expect(updated.Status.Attempts[0].Reason).To(Equal(build.BuildRunStatePodEvicted))
+				Expect(updated.Status.GetCondition(build.Succeeded).Status).To(Equal(corev1.ConditionUnknown))
+				Expect(updated.Status.GetCondition(build.Succeeded).Reason).To(Equal(resources.ConditionRetrying))
+			})
+
+			It("archives the logs of the failed attempt", func() {
+				directory, err := os.MkdirTemp("", "logs")
+				Expect(err).ToNot(HaveOccurred())
+				defer os.RemoveAll(directory)
+
+				reconciler = buildrunctl.NewReconciler(config.NewDefaultConfig(), manager, controllerutil.SetControllerReference, logs.NewArchiver(logs.NewPVCSink(directory), nil))
+
+				var updated *build.BuildRun
+				statusWriter.UpdateCalls(func(_ context.Context, object crc.Object, _ ...crc.UpdateOption) error {
+					updated = object.(*build.BuildRun).DeepCopy()
+					return nil
+				})
+
+				_, err = reconciler.Reconcile(context.TODO(), taskRunRequest)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(updated).ToNot(BeNil())
+				Expect(updated.Status.Attempts).To(HaveLen(1))
+				Expect(updated.Status.Attempts[0].Logs).To(Equal(&build.BuildRunLogs{
+					Sink:      config.LogsSinkPVC,
+					Reference: filepath.Join(directory, updated.Namespace, buildRunName, taskRunName+".log"),
+				}))
+				Expect(updated.Status.Logs).To(BeNil())
+			})
+
+			It("fails the BuildRun once the maximum attempts are reached", func() {
+				buildRunSample.Status.Attempts = []build.BuildRunAttempt{{TaskRunName: "foobar-buildrun-abcde"}}
+
+				var updated *build.BuildRun
+				statusWriter.UpdateCalls(func(_ context.Context, object crc.Object, _ ...crc.UpdateOption) error {
+					updated = object.(*build.BuildRun).DeepCopy()
+					return nil
+				})
+
+				_, err := reconciler.Reconcile(context.TODO(), taskRunRequest)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(updated).ToNot(BeNil())
+				Expect(updated.Status.CompletionTime).ToNot(BeNil())
+				Expect(updated.Status.Attempts).To(HaveLen(1))
+				Expect(updated.Status.GetCondition(build.Succeeded).Status).To(Equal(corev1.ConditionFalse))
+				Expect(updated.Status.GetCondition(build.Succeeded).Reason).To(Equal(build.BuildRunStatePodEvicted))
+			})
+
+			It("does not retry failures with other reasons", func() {
+				buildRunSample.Spec.Retry.Reasons = []string{"GitRateLimited"}
+
+				var updated *build.BuildRun
+				statusWriter.UpdateCalls(func(_ context.Context, object crc.Object, _ ...crc.UpdateOption) error {
+					updated = object.(*build.BuildRun).DeepCopy()
+					return nil
+				})
+
+				_, err := reconciler.Reconcile(context.TODO(), taskRunRequest)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(updated).ToNot(BeNil())
+				Expect(updated.Status.Attempts).To(BeEmpty())
+				Expect(updated.Status.GetCondition(build.Succeeded).Status).To(Equal(corev1.ConditionFalse))
+			})
+
+			It("ignores the TaskRun of an attempt that was already retried", func() {
+				buildRunSample.Status.Attempts = []build.BuildRunAttempt{{TaskRunName: taskRunName}}
+
+				_, err := reconciler.Reconcile(context.TODO(), taskRunRequest)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(statusWriter.UpdateCallCount()).To(Equal(0))
+			})
+
+			It("waits for the backoff before it creates the TaskRun of the next attempt", func() {
+				buildRunSample.Status.Attempts = []build.BuildRunAttempt{{
+					TaskRunName:    taskRunName,
+					CompletionTime: &metav1.Time{Time: time.Now()},
+				}}
+				buildRunSample.Spec.Retry.Backoff = &metav1.Duration{Duration: time.Minute}
+
+				client.GetCalls(func(_ context.Context, nn types.NamespacedName, object crc.Object) error {
+					if buildRun, ok := object.(*build.BuildRun); ok {
+						buildRunSample.DeepCopyInto(buildRun)
+						return nil
+					}
+					return k8serrors.NewNotFound(schema.GroupResource{}, nn.Name)
+				})
+
+				result, err := reconciler.Reconcile(context.TODO(), buildRunRequest)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.RequeueAfter).To(BeNumerically(">", 50*time.Second))
+				Expect(client.CreateCallCount()).To(Equal(0))
+			})
+		})
+
 		Context("from an existing BuildRun resource", func() {
 			var (
 				saName           string
@@ -567,6 +751,131 @@ var _ = Describe("Reconcile BuildRun", func() {
 				Expect(cancelUpdateCalled).To(BeTrue())
 			})
 
+			It("creates a BuildRun that reruns a completed BuildRun annotated for a rerun", func() {
+				buildRunSample.Annotations = map[string]string{build.AnnotationBuildRunRerun: "true"}
+				buildRunSample.Status.SetCondition(&build.Condition{Type: build.Succeeded, Status: corev1.ConditionTrue, Reason: "Succeeded"})
+				buildRunSample.Status.Sources = []build.SourceResult{{Name: "default", Git: &build.GitSourceResult{CommitSha: "abc123"}}}
+				buildRunSample.Status.BuildSpec = buildSample.Spec.DeepCopy()
+
+				var rerun *build.BuildRun
+				client.CreateCalls(func(_ context.Context, object crc.Object, _ ...crc.CreateOption) error {
+					rerun = object.(*build.BuildRun)
+					return nil
+				})
+
+				_, err := reconciler.Reconcile(context.TODO(), buildRunRequest)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(client.CreateCallCount()).To(Equal(1))
+				Expect(rerun.Name).To(Equal("foobar-buildrun-rerun-1"))
+				Expect(rerun.Labels).To(HaveKeyWithValue(build.LabelBuildRunRerunOf, buildRunName))
+				Expect(rerun.Spec.BuildRef).To(BeNil())
+				Expect(rerun.Spec.BuildSpec).To(Equal(buildRunSample.Status.BuildSpec))
+				Expect(rerun.Spec.PinnedSources).To(Equal([]build.PinnedSource{{Name: "default", Revision: "abc123"}}))
+
+				Expect(client.UpdateCallCount()).To(Equal(1))
+				_, updated, _ := client.UpdateArgsForCall(0)
+				Expect(updated.GetAnnotations()).ToNot(HaveKey(build.AnnotationBuildRunRerun))
+				Expect(updated.GetAnnotations()).To(HaveKeyWithValue(build.AnnotationBuildRunReruns, "1"))
+
+				Expect(recorder.Events).To(Receive(Equal("Normal BuildRunCreated Created BuildRun foobar-buildrun-rerun-1 to rerun this BuildRun")))
+			})
+
+			It("keeps the annotation when the BuildRun that reruns a BuildRun can not be created", func() {
+				buildRunSample.Annotations = map[string]string{build.AnnotationBuildRunRerun: "true"}
+				buildRunSample.Status.SetCondition(&build.Condition{Type: build.Succeeded, Status: corev1.ConditionTrue, Reason: "Succeeded"})
+
+				client.CreateReturns(fmt.Errorf("failed to create"))
+
+				_, err := reconciler.Reconcile(context.TODO(), buildRunRequest)
+				Expect(err).To(HaveOccurred())
+				Expect(client.UpdateCallCount()).To(Equal(0))
+			})
+
+			It("removes the annotation when the BuildRun that reruns a BuildRun was created before", func() {
+				buildRunSample.Annotations = map[string]string{build.AnnotationBuildRunRerun: "true", build.AnnotationBuildRunReruns: "1"}
+				buildRunSample.Status.SetCondition(&build.Condition{Type: build.Succeeded, Status: corev1.ConditionTrue, Reason: "Succeeded"})
+
+				existing := ctl.DefaultBuildRun("foobar-buildrun-rerun-2", buildName)
+				existing.Labels = map[string]string{build.LabelBuildRunRerunOf: buildRunName}
+				client.GetCalls(func(_ context.Context, nn types.NamespacedName, object crc.Object) error {
+					if object, ok := object.(*build.BuildRun); ok {
+						if nn.Name == existing.Name {
+							existing.DeepCopyInto(object)
+						} else {
+							buildRunSample.DeepCopyInto(object)
+						}
+						return nil
+					}
+					return k8serrors.NewNotFound(schema.GroupResource{}, nn.Name)
+				})
+				client.CreateReturns(k8serrors.NewAlreadyExists(schema.GroupResource{}, existing.Name))
+
+				_, err := reconciler.Reconcile(context.TODO(), buildRunRequest)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(client.UpdateCallCount()).To(Equal(1))
+				_, updated, _ := client.UpdateArgsForCall(0)
+				Expect(updated.GetAnnotations()).ToNot(HaveKey(build.AnnotationBuildRunRerun))
+				Expect(updated.GetAnnotations()).To(HaveKeyWithValue(build.AnnotationBuildRunReruns, "2"))
+				Expect(recorder.Events).ToNot(Receive())
+			})
+
+			It("links a BuildRun that reruns another BuildRun in its status when it starts", func() {
+				buildSample = ctl.DefaultBuild(buildName, strategyName, build.NamespacedBuildStrategyKind)
+				buildRunSample.Labels = map[string]string{build.LabelBuildRunRerunOf: "original-buildrun"}
+				client.GetCalls(ctl.StubBuildRunGetWithSAandStrategies(
+					buildSample,
+					buildRunSample,
+					ctl.DefaultServiceAccount(saName),
+					ctl.DefaultClusterBuildStrategy(),
+					ctl.DefaultNamespacedBuildStrategy()),
+				)
+
+				var rerunOf string
+				statusWriter.UpdateCalls(func(_ context.Context, object crc.Object, _ ...crc.UpdateOption) error {
+					if buildRun, ok := object.(*build.BuildRun); ok && rerunOf == "" {
+						rerunOf = buildRun.Status.RerunOf
+					}
+					return nil
+				})
+
+				_, err := reconciler.Reconcile(context.TODO(), buildRunRequest)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(rerunOf).To(Equal("original-buildrun"))
+			})
+
+			It("does not rerun a BuildRun that did not complete yet", func() {
+				buildRunSample.Annotations = map[string]string{build.AnnotationBuildRunRerun: "true"}
+
+				_, err := reconciler.Reconcile(context.TODO(), buildRunRequest)
+				Expect(err).ToNot(HaveOccurred())
+
+				for i := 0; i < client.CreateCallCount(); i++ {
+					_, object, _ := client.CreateArgsForCall(i)
+					Expect(object).ToNot(BeAssignableToTypeOf(&build.BuildRun{}))
+				}
+			})
+
+			It("removes the annotation of a BuildRun that is annotated while it is running", func() {
+				buildRunSample.Annotations = map[string]string{build.AnnotationBuildRunRerun: "true"}
+				buildRunSample.Status.SetCondition(&build.Condition{Type: build.Succeeded, Status: corev1.ConditionUnknown, Reason: "Running"})
+
+				_, err := reconciler.Reconcile(context.TODO(), buildRunRequest)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(client.UpdateCallCount()).To(BeNumerically(">=", 1))
+				_, updated, _ := client.UpdateArgsForCall(0)
+				Expect(updated.GetAnnotations()).ToNot(HaveKey(build.AnnotationBuildRunRerun))
+
+				for i := 0; i < client.CreateCallCount(); i++ {
+					_, object, _ := client.CreateArgsForCall(i)
+					Expect(object).ToNot(BeAssignableToTypeOf(&build.BuildRun{}))
+				}
+
+				Expect(recorder.Events).To(Receive(HavePrefix("Warning RerunIgnored ")))
+			})
+
 			It("should return none error and stop reconciling if referenced Build is not found", func() {
 				buildRunSample = ctl.BuildRunWithoutSA(buildRunName, buildName)
 
@@ -587,6 +896,7 @@ var _ = Describe("Reconcile BuildRun", func() {
 				_, err := reconciler.Reconcile(context.TODO(), buildRunRequest)
 				Expect(err).To(BeNil())
 				Expect(resources.IsClientStatusUpdateError(err)).To(BeFalse())
+				Expect(recorder.Events).To(Receive(HavePrefix("Warning BuildNotFound ")))
 			})
 
 			It("should return an error and continue reconciling if referenced Build is not found and the status update fails", func() {
@@ -950,7 +1260,7 @@ var _ = Describe("Reconcile BuildRun", func() {
 				reconciler = buildrunctl.NewReconciler(config.NewDefaultConfig(), manager,
 					func(owner, object metav1.Object, scheme *runtime.Scheme) error {
 						return fmt.Errorf("foobar error")
-					})
+					}, nil)
 
 				// we mark the BuildRun as Failed and do not reconcile again
 				_, err := reconciler.Reconcile(context.TODO(), buildRunRequest)
@@ -985,6 +1295,100 @@ var _ = Describe("Reconcile BuildRun", func() {
 				Expect(err).ToNot(HaveOccurred())
 
 				Expect(client.CreateCallCount()).To(Equal(1))
+				Expect(recorder.Events).To(Receive(Equal("Normal TaskRunCreated Created TaskRun " + taskRunName)))
+			})
+
+			It("queues the BuildRun while an earlier BuildRun of a Build with the Forbid policy is running", func() {
+				buildSample = ctl.DefaultBuild(buildName, strategyName, build.NamespacedBuildStrategyKind)
+				buildSample.Spec.ConcurrencyPolicy = build.ConcurrencyPolicyForbid
+
+				client.GetCalls(ctl.StubBuildRunGetWithSAandStrategies(
+					buildSample,
+					buildRunSample,
+					ctl.DefaultServiceAccount(saName),
+					ctl.DefaultClusterBuildStrategy(),
+					ctl.DefaultNamespacedBuildStrategy()),
+				)
+
+				running := ctl.DefaultBuildRun("earlier-buildrun", buildName)
+				running.Status.LatestTaskRunRef = pointer.String("earlier-buildrun-abcde")
+				client.ListCalls(func(_ context.Context, list crc.ObjectList, _ ...crc.ListOption) error {
+					list.(*build.BuildRunList).Items = []build.BuildRun{*running, *buildRunSample}
+					return nil
+				})
+
+				var updated *build.BuildRun
+				statusWriter.UpdateCalls(func(_ context.Context, object crc.Object, _ ...crc.UpdateOption) error {
+					updated = object.(*build.BuildRun).DeepCopy()
+					return nil
+				})
+
+				result, err := reconciler.Reconcile(context.TODO(), buildRunRequest)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+				Expect(client.CreateCallCount()).To(Equal(0))
+
+				Expect(updated).ToNot(BeNil())
+				Expect(updated.Status.GetCondition(build.Succeeded).GetReason()).To(Equal(resources.ConditionQueued))
+				Expect(updated.Status.GetCondition(build.Succeeded).GetStatus()).To(Equal(corev1.ConditionUnknown))
+			})
+
+			Context("for a Build that skips unchanged builds", func() {
+				var previous *build.BuildRun
+
+				BeforeEach(func() {
+					buildSample = ctl.DefaultBuild(buildName, strategyName, build.NamespacedBuildStrategyKind)
+					buildSample.Spec.SkipIfUnchanged = pointer.Bool(true)
+
+					client.GetCalls(ctl.StubBuildRunGetWithSAandStrategies(
+						buildSample,
+						buildRunSample,
+						ctl.DefaultServiceAccount(saName),
+						ctl.DefaultClusterBuildStrategy(),
+						ctl.DefaultNamespacedBuildStrategy()),
+					)
+
+					fingerprint, err := resources.ComputeFingerprint(context.TODO(), client, buildSample, buildRunSample, ctl.DefaultNamespacedBuildStrategy())
+					Expect(err).ToNot(HaveOccurred())
+
+					previous = ctl.DefaultBuildRun("earlier-buildrun", buildName)
+					previous.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+					previous.Status.Fingerprint = fingerprint
+					previous.Status.Output = &build.Output{Digest: "sha256:0d6b4c3e6fc0a1f4ea9ef1c3e88b5fcbd2a1b6e4f6f39c6cd4c8a5b1b0a3e7f2"}
+					previous.Status.SetCondition(&build.Condition{Type: build.Succeeded, Status: corev1.ConditionTrue})
+
+					client.ListCalls(func(_ context.Context, list crc.ObjectList, _ ...crc.ListOption) error {
+						list.(*build.BuildRunList).Items = []build.BuildRun{*previous, *buildRunSample}
+						return nil
+					})
+				})
+
+				It("succeeds without a TaskRun when the inputs equal the ones of the last succeeded BuildRun", func() {
+					var updated *build.BuildRun
+					statusWriter.UpdateCalls(func(_ context.Context, object crc.Object, _ ...crc.UpdateOption) error {
+						updated = object.(*build.BuildRun).DeepCopy()
+						return nil
+					})
+
+					_, err := reconciler.Reconcile(context.TODO(), buildRunRequest)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(client.CreateCallCount()).To(Equal(0))
+
+					Expect(updated).ToNot(BeNil())
+					Expect(updated.Status.GetCondition(build.Succeeded).GetReason()).To(Equal(resources.ConditionUpToDate))
+					Expect(updated.Status.GetCondition(build.Succeeded).GetStatus()).To(Equal(corev1.ConditionTrue))
+					Expect(updated.Status.Output).To(Equal(previous.Status.Output))
+					Expect(updated.Status.Fingerprint).To(Equal(previous.Status.Fingerprint))
+					Expect(recorder.Events).To(Receive(HavePrefix("Normal UpToDate ")))
+				})
+
+				It("creates a TaskRun when the inputs changed", func() {
+					buildRunSample.Spec.Env = []corev1.EnvVar{{Name: "SOME_VARIABLE", Value: "changed"}}
+
+					_, err := reconciler.Reconcile(context.TODO(), buildRunRequest)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(client.CreateCallCount()).To(Equal(1))
+				})
 			})
 
 			It("succeeds creating a TaskRun from a cluster buildstrategy", func() {
@@ -1216,6 +1620,98 @@ var _ = Describe("Reconcile BuildRun", func() {
 				Expect(err).ToNot(BeNil())
 				Expect(resources.IsClientStatusUpdateError(err)).To(BeTrue())
 			})
+
+			Context("for a BuildRun with an embedded Build spec", func() {
+				var failedCondition = func(reason string) *build.Condition {
+					var condition *build.Condition
+					statusWriter.UpdateCalls(func(_ context.Context, object crc.Object, _ ...crc.UpdateOption) error {
+						condition = object.(*build.BuildRun).Status.GetCondition(build.Succeeded).DeepCopy()
+						return nil
+					})
+
+					_, err := reconciler.Reconcile(context.TODO(), buildRunRequest)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(client.CreateCallCount()).To(Equal(0))
+
+					Expect(condition).ToNot(BeNil())
+					Expect(condition.GetStatus()).To(Equal(corev1.ConditionFalse))
+					Expect(condition.GetReason()).To(Equal(reason))
+					return condition
+				}
+
+				BeforeEach(func() {
+					buildRunSample.Spec.BuildRef = nil
+					buildRunSample.Spec.BuildSpec = ctl.DefaultBuild(buildName, strategyName, build.NamespacedBuildStrategyKind).Spec.DeepCopy()
+
+					// there is no Build in the cluster
+					client.GetCalls(ctl.StubBuildRunGetWithSAandStrategies(
+						nil,
+						buildRunSample,
+						ctl.DefaultServiceAccount(saName),
+						ctl.DefaultClusterBuildStrategy(),
+						ctl.DefaultNamespacedBuildStrategy()),
+					)
+				})
+
+				It("creates a TaskRun without the labels of a Build", func() {
+					var taskRun *v1beta1.TaskRun
+					client.CreateCalls(func(_ context.Context, object crc.Object, _ ...crc.CreateOption) error {
+						taskRun = object.(*v1beta1.TaskRun).DeepCopy()
+						return nil
+					})
+
+					_, err := reconciler.Reconcile(context.TODO(), buildRunRequest)
+					Expect(err).ToNot(HaveOccurred())
+
+					Expect(taskRun).ToNot(BeNil())
+					Expect(taskRun.Labels).ToNot(HaveKey(build.LabelBuild))
+					Expect(taskRun.Labels).ToNot(HaveKey(build.LabelBuildGeneration))
+					Expect(taskRun.Labels).To(HaveKeyWithValue(build.LabelBuildRun, buildRunName))
+
+					// the BuildRun itself is not updated with the labels of a Build
+					Expect(client.UpdateCallCount()).To(Equal(0))
+				})
+
+				It("fails when the embedded Build spec does not pass the validations of a Build", func() {
+					buildRunSample.Spec.BuildSpec.Env = []corev1.EnvVar{{Name: "", Value: "some-value"}}
+
+					condition := failedCondition(string(build.SpecEnvNameCanNotBeBlank))
+					Expect(condition.GetMessage()).To(Equal("name for environment variable must not be blank"))
+				})
+
+				It("fails when the embedded Build spec references a strategy that does not exist", func() {
+					buildRunSample.Spec.BuildSpec.Strategy.Name = "unknown-strategy"
+					client.GetCalls(ctl.StubBuildRunGetWithSAandStrategies(nil, buildRunSample, ctl.DefaultServiceAccount(saName), nil, nil))
+
+					failedCondition(string(build.BuildStrategyNotFound))
+				})
+
+				It("fails when the embedded Build spec specifies a schedule", func() {
+					buildRunSample.Spec.BuildSpec.Schedule = &build.BuildSchedule{Cron: "0 * * * *"}
+
+					condition := failedCondition(string(build.EmbeddedBuildSpecUnsupported))
+					Expect(condition.GetMessage()).To(Equal("schedule can only be used in a Build, not in the buildSpec of a BuildRun"))
+				})
+
+				It("fails when the embedded Build spec specifies a retention limit", func() {
+					failedLimit := uint(3)
+					buildRunSample.Spec.BuildSpec.Retention = &build.BuildRetention{FailedLimit: &failedLimit}
+
+					failedCondition(string(build.EmbeddedBuildSpecUnsupported))
+				})
+
+				It("fails when the BuildRun specifies both a Build reference and an embedded Build spec", func() {
+					buildRunSample.Spec.BuildRef = &build.BuildRef{Name: buildName}
+
+					failedCondition(resources.ConditionBuildRunAmbiguousBuild)
+				})
+
+				It("fails when the BuildRun specifies neither a Build reference nor an embedded Build spec", func() {
+					buildRunSample.Spec.BuildSpec = nil
+
+					failedCondition(resources.ConditionBuildRunNoRefOrSpec)
+				})
+			})
 		})
 
 		Context("when environment variables are specified", func() {
@@ -26,6 +26,8 @@ import (
 	buildv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
 	"github.com/shipwright-io/build/pkg/config"
 	"github.com/shipwright-io/build/pkg/ctxlog"
+	"github.com/shipwright-io/build/pkg/logs"
+	"github.com/shipwright-io/build/pkg/reconciler/buildrun/resources"
 )
 
 type setOwnerReferenceFunc func(owner, object metav1.Object, scheme *runtime.Scheme) error
@@ -34,7 +36,13 @@ type setOwnerReferenceFunc func(owner, object metav1.Object, scheme *runtime.Sch
 // and Start it when the Manager is Started.
 func Add(ctx context.Context, c *config.Config, mgr manager.Manager) error {
 	ctx = ctxlog.NewContext(ctx, "buildrun-controller")
-	return add(ctx, mgr, NewReconciler(c, mgr, controllerutil.SetControllerReference), c.Controllers.BuildRun.MaxConcurrentReconciles)
+
+	archiver, err := logs.NewArchiverFromConfig(c, mgr.GetConfig(), mgr.GetClient())
+	if err != nil {
+		return err
+	}
+
+	return add(ctx, mgr, NewReconciler(c, mgr, controllerutil.SetControllerReference, archiver), c.Controllers.BuildRun.MaxConcurrentReconciles)
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
@@ -53,13 +61,19 @@ func add(ctx context.Context, mgr manager.Manager, r reconcile.Reconciler, maxCo
 		return err
 	}
 
+	// BuildRuns are looked up by their Build to apply the concurrency policy
+	if err := resources.IndexBuildRunsByBuild(ctx, mgr.GetFieldIndexer()); err != nil {
+		return err
+	}
+
 	predBuildRun := predicate.Funcs{
 		CreateFunc: func(e event.CreateEvent) bool {
 			o := e.Object.(*buildv1alpha1.BuildRun)
 
 			// The CreateFunc is also called when the controller is started and iterates over all objects. For those BuildRuns that have a TaskRun referenced already,
 			// we do not need to do a further reconciliation. BuildRun updates then only happen from the TaskRun.
-			return o.Status.LatestTaskRunRef == nil && o.Status.CompletionTime == nil
+			// BuildRuns that were annotated to be rerun while the controller was down are reconciled to rerun them, or to remove the annotation.
+			return (o.Status.LatestTaskRunRef == nil && o.Status.CompletionTime == nil) || resources.IsRerunRequested(o)
 		},
 		UpdateFunc: func(e event.UpdateEvent) bool {
 			// Ignore updates to CR status in which case metadata.Generation does not change
@@ -67,12 +81,18 @@ func add(ctx context.Context, mgr manager.Manager, r reconcile.Reconciler, maxCo
 			n := e.ObjectNew.(*buildv1alpha1.BuildRun)
 
 			// Avoid reconciling when for updates on the BuildRun the following takes place
-			// - the build.shipwright.io/name label is set
+			// - the build.shipwright.io/name label is set, which a BuildRun with an embedded Build spec does not get
 			// - when a BuildRun already have a referenced TaskRun, but the latest version is not canceled
 			// - when a BuildRun have a completionTime set
+			// A BuildRun is reconciled when a failed attempt was recorded, so that it gets retried
+			// A BuildRun is reconciled when it is annotated to be rerun
 			switch {
-			case o.GetLabels()[buildv1alpha1.LabelBuild] == "":
+			case resources.IsRerunRequested(n) && !resources.IsRerunRequested(o):
+				return true
+			case o.GetLabels()[buildv1alpha1.LabelBuild] == "" && !o.HasEmbeddedBuildSpec():
 				return false
+			case len(n.Status.Attempts) > len(o.Status.Attempts) && n.Status.LatestTaskRunRef == nil:
+				return true
 			case o.Status.LatestTaskRunRef != nil && !n.IsCanceled():
 				return false
 			case o.Status.CompletionTime != nil:
@@ -15,8 +15,36 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-// GetBuildObject retrieves an existing Build based on a name and namespace
+// GetBuildObject retrieves an existing Build based on a name and namespace, or
+// for a BuildRun with an embedded Build spec, a Build that only exists in memory
+// and has the name of the BuildRun
 func GetBuildObject(ctx context.Context, client client.Client, buildRun *buildv1alpha1.BuildRun, build *buildv1alpha1.Build) error {
+	switch {
+	case buildRun.Spec.BuildRef != nil && buildRun.Spec.BuildSpec != nil:
+		err := fmt.Errorf("the BuildRun specifies both a Build reference and an embedded Build spec")
+		if updateErr := UpdateConditionWithFalseStatus(ctx, client, buildRun, err.Error(), ConditionBuildRunAmbiguousBuild); updateErr != nil {
+			return HandleError("build object is ambiguous", err, updateErr)
+		}
+		return err
+
+	case buildRun.Spec.BuildRef == nil && buildRun.Spec.BuildSpec == nil:
+		err := fmt.Errorf("the BuildRun specifies neither a Build reference nor an embedded Build spec")
+		if updateErr := UpdateConditionWithFalseStatus(ctx, client, buildRun, err.Error(), ConditionBuildRunNoRefOrSpec); updateErr != nil {
+			return HandleError("build object is missing", err, updateErr)
+		}
+		return err
+
+	case buildRun.HasEmbeddedBuildSpec():
+		*build = buildv1alpha1.Build{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      buildRun.Name,
+				Namespace: buildRun.Namespace,
+			},
+			Spec: *buildRun.Spec.BuildSpec.DeepCopy(),
+		}
+		return nil
+	}
+
 	err := client.Get(ctx, types.NamespacedName{Name: buildRun.Spec.BuildRef.Name, Namespace: buildRun.Namespace}, build)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
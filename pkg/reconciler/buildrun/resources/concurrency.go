@@ -0,0 +1,155 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	buildv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	"github.com/shipwright-io/build/pkg/config"
+	"github.com/shipwright-io/build/pkg/ctxlog"
+)
+
+// ConditionQueued is the reason of a BuildRun that waits for the concurrency policy of its Build, or the limit
+// of running BuildRuns in its namespace, to allow it to start
+const ConditionQueued = "Queued"
+
+// BuildRefIndex is the name of the field index of BuildRuns by the name of their Build
+const BuildRefIndex = "spec.buildRef.name"
+
+// IndexBuildRunsByBuild registers the field index of BuildRuns by the name of their Build
+func IndexBuildRunsByBuild(ctx context.Context, indexer client.FieldIndexer) error {
+	return indexer.IndexField(ctx, &buildv1alpha1.BuildRun{}, BuildRefIndex, func(object client.Object) []string {
+		return []string{object.(*buildv1alpha1.BuildRun).BuildName()}
+	})
+}
+
+// IsQueued returns whether a BuildRun has to wait before its TaskRun is created, together with a message that
+// describes why. BuildRuns start in the order of their creation. For the Replace concurrency policy, the earlier
+// BuildRuns of the Build are canceled. The concurrency policy of an embedded Build spec has no effect.
+func IsQueued(ctx context.Context, client client.Client, cfg *config.Config, build *buildv1alpha1.Build, buildRun *buildv1alpha1.BuildRun) (bool, string, error) {
+	policy := build.Spec.ConcurrencyPolicy
+	if buildRun.HasEmbeddedBuildSpec() {
+		policy = ""
+	}
+
+	if policy == buildv1alpha1.ConcurrencyPolicyForbid || policy == buildv1alpha1.ConcurrencyPolicyReplace {
+		buildRuns := &buildv1alpha1.BuildRunList{}
+		if err := client.List(ctx, buildRuns, inNamespace(buildRun.Namespace), ofBuild(build.Name)); err != nil {
+			return false, "", err
+		}
+
+		if policy == buildv1alpha1.ConcurrencyPolicyReplace {
+			if err := cancelEarlierBuildRuns(ctx, client, buildRuns.Items, buildRun); err != nil {
+				return false, "", err
+			}
+		}
+
+		if ahead := countAhead(buildRuns.Items, buildRun); ahead > 0 {
+			return true, fmt.Sprintf("BuildRun %s is queued until %d earlier BuildRuns of Build %s completed, the concurrency policy is %s", buildRun.Name, ahead, build.Name, policy), nil
+		}
+	}
+
+	if limit := cfg.MaxRunningBuildRunsPerNamespace; limit > 0 {
+		buildRuns := &buildv1alpha1.BuildRunList{}
+		if err := client.List(ctx, buildRuns, inNamespace(buildRun.Namespace)); err != nil {
+			return false, "", err
+		}
+
+		if ahead := countAhead(buildRuns.Items, buildRun); ahead >= limit {
+			return true, fmt.Sprintf("BuildRun %s is queued until fewer than %d BuildRuns run in namespace %s, %d BuildRuns are running or queued before it", buildRun.Name, limit, buildRun.Namespace, ahead), nil
+		}
+	}
+
+	return false, "", nil
+}
+
+// UpdateBuildRunQueuedCondition sets the Queued reason on the Succeeded condition, it returns false if the
+// condition did not change
+func UpdateBuildRunQueuedCondition(buildRun *buildv1alpha1.BuildRun, message string) bool {
+	condition := buildRun.Status.GetCondition(buildv1alpha1.Succeeded)
+	if condition.GetReason() == ConditionQueued && condition.GetMessage() == message {
+		return false
+	}
+
+	buildRun.Status.SetCondition(&buildv1alpha1.Condition{
+		LastTransitionTime: metav1.Now(),
+		Type:               buildv1alpha1.Succeeded,
+		Status:             corev1.ConditionUnknown,
+		Reason:             ConditionQueued,
+		Message:            message,
+	})
+
+	return true
+}
+
+// inNamespace lists the BuildRuns of a namespace
+func inNamespace(namespace string) client.ListOption {
+	return client.InNamespace(namespace)
+}
+
+// ofBuild lists the BuildRuns of a Build using the field index
+func ofBuild(buildName string) client.ListOption {
+	return client.MatchingFields{BuildRefIndex: buildName}
+}
+
+// countAhead returns the number of BuildRuns that are running, or that are queued and were created earlier
+func countAhead(buildRuns []buildv1alpha1.BuildRun, buildRun *buildv1alpha1.BuildRun) int {
+	ahead := 0
+	for i := range buildRuns {
+		other := &buildRuns[i]
+		if other.Name == buildRun.Name {
+			continue
+		}
+
+		if isRunning(other) || (isQueued(other) && isCreatedBefore(other, buildRun)) {
+			ahead++
+		}
+	}
+
+	return ahead
+}
+
+// cancelEarlierBuildRuns requests the cancellation of the BuildRuns that were created earlier and did not complete
+func cancelEarlierBuildRuns(ctx context.Context, client client.Client, buildRuns []buildv1alpha1.BuildRun, buildRun *buildv1alpha1.BuildRun) error {
+	for i := range buildRuns {
+		other := &buildRuns[i]
+		if other.Name == buildRun.Name || other.Status.CompletionTime != nil || other.IsCanceled() || !isCreatedBefore(other, buildRun) {
+			continue
+		}
+
+		ctxlog.Info(ctx, "canceling BuildRun that is replaced", namespace, other.Namespace, name, other.Name, "replacedBy", buildRun.Name)
+		other.Spec.State = buildv1alpha1.BuildRunRequestedStatePtr(buildv1alpha1.BuildRunStateCancel)
+		if err := client.Update(ctx, other); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isRunning returns whether a BuildRun has a TaskRun, or waits for the retry of a failed attempt
+func isRunning(buildRun *buildv1alpha1.BuildRun) bool {
+	return buildRun.Status.CompletionTime == nil && (buildRun.Status.LatestTaskRunRef != nil || len(buildRun.Status.Attempts) > 0)
+}
+
+// isQueued returns whether a BuildRun waits for its TaskRun to be created
+func isQueued(buildRun *buildv1alpha1.BuildRun) bool {
+	return buildRun.Status.CompletionTime == nil && !isRunning(buildRun) && !buildRun.IsCanceled()
+}
+
+// isCreatedBefore orders BuildRuns by their creation, and by their name if they were created at the same time
+func isCreatedBefore(buildRun *buildv1alpha1.BuildRun, other *buildv1alpha1.BuildRun) bool {
+	if buildRun.CreationTimestamp.Equal(&other.CreationTimestamp) {
+		return buildRun.Name < other.Name
+	}
+
+	return buildRun.CreationTimestamp.Before(&other.CreationTimestamp)
+}
@@ -0,0 +1,174 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package resources_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	crc "sigs.k8s.io/controller-runtime/pkg/client"
+
+	buildv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	"github.com/shipwright-io/build/pkg/config"
+	"github.com/shipwright-io/build/pkg/controller/fakes"
+	"github.com/shipwright-io/build/pkg/reconciler/buildrun/resources"
+)
+
+var _ = Describe("Concurrency of BuildRuns", func() {
+	var (
+		cfg       *config.Config
+		client    *fakes.FakeClient
+		build     *buildv1alpha1.Build
+		buildRun  *buildv1alpha1.BuildRun
+		buildRuns []buildv1alpha1.BuildRun
+		updated   []*buildv1alpha1.BuildRun
+		now       time.Time
+	)
+
+	newBuildRun := func(name string, age time.Duration, taskRun *string) buildv1alpha1.BuildRun {
+		return buildv1alpha1.BuildRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				CreationTimestamp: metav1.NewTime(now.Add(-age)),
+			},
+			Spec: buildv1alpha1.BuildRunSpec{
+				BuildRef: &buildv1alpha1.BuildRef{Name: "build"},
+			},
+			Status: buildv1alpha1.BuildRunStatus{
+				LatestTaskRunRef: taskRun,
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		now = time.Now()
+		cfg = config.NewDefaultConfig()
+
+		build = &buildv1alpha1.Build{
+			ObjectMeta: metav1.ObjectMeta{Name: "build", Namespace: "default"},
+		}
+
+		current := newBuildRun("buildrun-c", time.Minute, nil)
+		buildRun = &current
+		buildRuns = nil
+		updated = nil
+
+		client = &fakes.FakeClient{}
+		client.ListCalls(func(_ context.Context, list crc.ObjectList, _ ...crc.ListOption) error {
+			buildRunList := list.(*buildv1alpha1.BuildRunList)
+			buildRunList.Items = append([]buildv1alpha1.BuildRun{*buildRun}, buildRuns...)
+			return nil
+		})
+		client.UpdateCalls(func(_ context.Context, object crc.Object, _ ...crc.UpdateOption) error {
+			updated = append(updated, object.(*buildv1alpha1.BuildRun).DeepCopy())
+			return nil
+		})
+	})
+
+	Context("for the Allow policy", func() {
+		It("does not queue a BuildRun while others are running", func() {
+			buildRuns = []buildv1alpha1.BuildRun{newBuildRun("buildrun-a", time.Hour, pointer.String("buildrun-a-xyz12"))}
+
+			queued, _, err := resources.IsQueued(context.TODO(), client, cfg, build, buildRun)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(queued).To(BeFalse())
+			Expect(client.ListCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("for the Forbid policy", func() {
+		BeforeEach(func() {
+			build.Spec.ConcurrencyPolicy = buildv1alpha1.ConcurrencyPolicyForbid
+		})
+
+		It("queues a BuildRun while an earlier one is running", func() {
+			buildRuns = []buildv1alpha1.BuildRun{newBuildRun("buildrun-a", time.Hour, pointer.String("buildrun-a-xyz12"))}
+
+			queued, message, err := resources.IsQueued(context.TODO(), client, cfg, build, buildRun)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(queued).To(BeTrue())
+			Expect(message).To(ContainSubstring("concurrency policy is Forbid"))
+		})
+
+		It("queues a BuildRun behind an earlier queued one", func() {
+			buildRuns = []buildv1alpha1.BuildRun{newBuildRun("buildrun-b", time.Hour, nil)}
+
+			queued, _, err := resources.IsQueued(context.TODO(), client, cfg, build, buildRun)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(queued).To(BeTrue())
+		})
+
+		It("starts a BuildRun before later queued ones and after completed ones", func() {
+			completed := newBuildRun("buildrun-a", time.Hour, pointer.String("buildrun-a-xyz12"))
+			completed.Status.CompletionTime = &metav1.Time{Time: now}
+			buildRuns = []buildv1alpha1.BuildRun{completed, newBuildRun("buildrun-d", time.Second, nil)}
+
+			queued, _, err := resources.IsQueued(context.TODO(), client, cfg, build, buildRun)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(queued).To(BeFalse())
+		})
+	})
+
+	Context("for the Replace policy", func() {
+		BeforeEach(func() {
+			build.Spec.ConcurrencyPolicy = buildv1alpha1.ConcurrencyPolicyReplace
+		})
+
+		It("cancels the earlier BuildRuns and waits for them to complete", func() {
+			buildRuns = []buildv1alpha1.BuildRun{
+				newBuildRun("buildrun-a", time.Hour, pointer.String("buildrun-a-xyz12")),
+				newBuildRun("buildrun-d", time.Second, nil),
+			}
+
+			queued, _, err := resources.IsQueued(context.TODO(), client, cfg, build, buildRun)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(queued).To(BeTrue())
+
+			Expect(updated).To(HaveLen(1))
+			Expect(updated[0].Name).To(Equal("buildrun-a"))
+			Expect(updated[0].IsCanceled()).To(BeTrue())
+		})
+	})
+
+	Context("with a limit of running BuildRuns per namespace", func() {
+		BeforeEach(func() {
+			cfg.MaxRunningBuildRunsPerNamespace = 2
+		})
+
+		It("queues a BuildRun when the limit is reached", func() {
+			buildRuns = []buildv1alpha1.BuildRun{
+				newBuildRun("buildrun-a", time.Hour, pointer.String("buildrun-a-xyz12")),
+				newBuildRun("buildrun-b", time.Hour, pointer.String("buildrun-b-xyz12")),
+			}
+
+			queued, message, err := resources.IsQueued(context.TODO(), client, cfg, build, buildRun)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(queued).To(BeTrue())
+			Expect(message).To(ContainSubstring("fewer than 2 BuildRuns run in namespace default"))
+		})
+
+		It("starts a BuildRun below the limit", func() {
+			buildRuns = []buildv1alpha1.BuildRun{newBuildRun("buildrun-a", time.Hour, pointer.String("buildrun-a-xyz12"))}
+
+			queued, _, err := resources.IsQueued(context.TODO(), client, cfg, build, buildRun)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(queued).To(BeFalse())
+		})
+	})
+
+	Context("updating the condition", func() {
+		It("only reports a change of the message", func() {
+			Expect(resources.UpdateBuildRunQueuedCondition(buildRun, "queued")).To(BeTrue())
+			Expect(resources.UpdateBuildRunQueuedCondition(buildRun, "queued")).To(BeFalse())
+			Expect(buildRun.Status.GetCondition(buildv1alpha1.Succeeded).GetReason()).To(Equal(resources.ConditionQueued))
+		})
+	})
+})
@@ -41,6 +41,10 @@ const (
 	ConditionIncompleteConfigMapValueParameterValues string = "IncompleteConfigMapValueParameterValues"
 	ConditionIncompleteSecretValueParameterValues    string = "IncompleteSecretValueParameterValues"
 	BuildRunNameInvalid                              string = "BuildRunNameInvalid"
+	ConditionWaitingForUpload                        string = "WaitingForUpload"
+	ConditionUploadTokenNotOwned                     string = "UploadTokenNotOwned"
+	ConditionBuildRunAmbiguousBuild                  string = "BuildRunAmbiguousBuild"
+	ConditionBuildRunNoRefOrSpec                     string = "BuildRunNoRefOrSpec"
 )
 
 // UpdateBuildRunUsingTaskRunCondition updates the BuildRun Succeeded Condition
@@ -56,6 +60,9 @@ func UpdateBuildRunUsingTaskRunCondition(ctx context.Context, client client.Clie
 			status = corev1.ConditionUnknown // in practice the taskrun status is already unknown in this case, but we are making sure here
 			reason = buildv1alpha1.BuildRunStateCancel
 			message = "The user requested the BuildRun to be canceled.  This BuildRun controller has requested the TaskRun be canceled.  That request has not been process by Tekton's TaskRun controller yet."
+		} else if isWaitingForUpload(ctx, client, buildRun, taskRun) {
+			reason = ConditionWaitingForUpload
+			message = fmt.Sprintf("BuildRun %s is waiting for the upload of the LocalCopy source into pod %s", buildRun.Name, taskRun.Status.PodName)
 		}
 	case v1beta1.TaskRunReasonCancelled:
 		if buildRun.IsCanceled() {
@@ -0,0 +1,284 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	buildv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	"github.com/shipwright-io/build/pkg/git"
+	"github.com/shipwright-io/build/pkg/image"
+)
+
+// ConditionUpToDate is the reason of a BuildRun that succeeded without a TaskRun, because its inputs equal the
+// ones of the last succeeded BuildRun of its Build
+const ConditionUpToDate = "UpToDate"
+
+// fingerprintInputs are the inputs of a BuildRun that determine the image it builds
+type fingerprintInputs struct {
+	Sources            []fingerprintSource        `json:"sources"`
+	Strategy           string                     `json:"strategy"`
+	StrategyGeneration int64                      `json:"strategyGeneration"`
+	ParamValues        []buildv1alpha1.ParamValue `json:"paramValues,omitempty"`
+	Env                []corev1.EnvVar            `json:"env,omitempty"`
+	Builder            string                     `json:"builder,omitempty"`
+	Dockerfile         string                     `json:"dockerfile,omitempty"`
+	Output             string                     `json:"output"`
+	OutputCredentials  []string                   `json:"outputCredentials,omitempty"`
+	OutputLabels       map[string]string          `json:"outputLabels,omitempty"`
+	OutputAnnotations  map[string]string          `json:"outputAnnotations,omitempty"`
+	SourceAnnotations  bool                       `json:"sourceAnnotations,omitempty"`
+}
+
+// fingerprintSource is a source with the resolved commit of a Git repository, or the digest of an image or file
+type fingerprintSource struct {
+	Name       string `json:"name"`
+	URL        string `json:"url"`
+	Revision   string `json:"revision,omitempty"`
+	Digest     string `json:"digest,omitempty"`
+	ContextDir string `json:"contextDir,omitempty"`
+	TargetPath string `json:"targetPath,omitempty"`
+}
+
+// IsSkipIfUnchanged returns whether a BuildRun succeeds without a TaskRun if its inputs did not change, the setting
+// of the BuildRun takes precedence over the one of the Build. A BuildRun with an embedded Build spec is never
+// skipped, as there are no earlier BuildRuns of its Build.
+func IsSkipIfUnchanged(build *buildv1alpha1.Build, buildRun *buildv1alpha1.BuildRun) bool {
+	if buildRun.HasEmbeddedBuildSpec() {
+		return false
+	}
+
+	if buildRun.Spec.SkipIfUnchanged != nil {
+		return *buildRun.Spec.SkipIfUnchanged
+	}
+
+	return pointer.BoolDeref(build.Spec.SkipIfUnchanged, false)
+}
+
+// ComputeFingerprint resolves the inputs of a BuildRun and returns their fingerprint. It fails if an input can not
+// be resolved, for example a LocalCopy source, an HTTP source without a digest, or a Git source with an SSH URL.
+func ComputeFingerprint(ctx context.Context, client client.Client, build *buildv1alpha1.Build, buildRun *buildv1alpha1.BuildRun, strategy buildv1alpha1.BuilderStrategy) (*buildv1alpha1.BuildRunFingerprint, error) {
+	revisions := map[string]string{}
+	inputs := fingerprintInputs{
+		Strategy:           fmt.Sprintf("%s/%s", strategyKind(build), strategy.GetName()),
+		StrategyGeneration: strategy.GetGeneration(),
+		ParamValues:        append(append([]buildv1alpha1.ParamValue{}, build.Spec.ParamValues...), buildRun.Spec.ParamValues...),
+		Env:                append(append([]corev1.EnvVar{}, build.Spec.Env...), buildRun.Spec.Env...),
+		Dockerfile:         pointer.StringDeref(build.Spec.Dockerfile, ""),
+		Output:             build.Spec.Output.Image,
+		OutputLabels:       build.Spec.Output.Labels,
+		OutputAnnotations:  build.Spec.Output.Annotations,
+		SourceAnnotations:  isSourceAnnotationsEnabled(build.Spec.Output, buildv1alpha1.Image{}),
+	}
+
+	if build.Spec.Output.Credentials != nil {
+		inputs.OutputCredentials = append(inputs.OutputCredentials, build.Spec.Output.Credentials.Name)
+	}
+
+	if buildRun.Spec.Output != nil {
+		inputs.Output = buildRun.Spec.Output.Image
+		// mergeMaps modifies its first map, the maps of the Build are copied
+		inputs.OutputLabels = mergeMaps(mergeMaps(nil, build.Spec.Output.Labels), buildRun.Spec.Output.Labels)
+		inputs.OutputAnnotations = mergeMaps(mergeMaps(nil, build.Spec.Output.Annotations), buildRun.Spec.Output.Annotations)
+		inputs.SourceAnnotations = isSourceAnnotationsEnabled(build.Spec.Output, *buildRun.Spec.Output)
+
+		if buildRun.Spec.Output.Credentials != nil {
+			inputs.OutputCredentials = append(inputs.OutputCredentials, buildRun.Spec.Output.Credentials.Name)
+		}
+	}
+
+	source := build.Spec.Source
+	switch {
+	case source.BundleContainer != nil:
+		reference := source.BundleContainer.Image
+		if source.BundleContainer.Digest != "" {
+			reference = fmt.Sprintf("%s@%s", reference, source.BundleContainer.Digest)
+		}
+
+		digest, err := resolveImageDigest(ctx, client, build.Namespace, reference, source.Credentials)
+		if err != nil {
+			return nil, err
+		}
+
+		inputs.Sources = append(inputs.Sources, fingerprintSource{Name: defaultSourceName, URL: digest, ContextDir: pointer.StringDeref(source.ContextDir, "")})
+
+	case source.URL != nil:
+		revision, err := resolveGitRevision(ctx, client, build.Namespace, *source.URL, pointer.StringDeref(source.Revision, ""), source.Credentials)
+		if err != nil {
+			return nil, err
+		}
+
+		revisions[defaultSourceName] = revision
+		inputs.Sources = append(inputs.Sources, fingerprintSource{Name: defaultSourceName, URL: *source.URL, Revision: revision, ContextDir: pointer.StringDeref(source.ContextDir, "")})
+	}
+
+	for _, buildSource := range append(append([]buildv1alpha1.BuildSource{}, build.Spec.Sources...), buildRun.Spec.Sources...) {
+		switch buildSource.Type {
+		case buildv1alpha1.LocalCopy:
+			return nil, fmt.Errorf("the content of the LocalCopy source %s is unknown before the upload", buildSource.Name)
+
+		case buildv1alpha1.Git:
+			revision, err := resolveGitRevision(ctx, client, build.Namespace, buildSource.URL, pointer.StringDeref(buildSource.Revision, ""), buildSource.Credentials)
+			if err != nil {
+				return nil, err
+			}
+
+			revisions[buildSource.Name] = revision
+			inputs.Sources = append(inputs.Sources, fingerprintSource{Name: buildSource.Name, URL: buildSource.URL, Revision: revision, TargetPath: buildSource.TargetPath})
+
+		default:
+			if buildSource.Digest == "" {
+				return nil, fmt.Errorf("the content of the HTTP source %s is unknown without a digest", buildSource.Name)
+			}
+
+			inputs.Sources = append(inputs.Sources, fingerprintSource{Name: buildSource.Name, URL: buildSource.URL, Digest: buildSource.Digest, TargetPath: buildSource.TargetPath})
+		}
+	}
+
+	if build.Spec.Builder != nil {
+		digest, err := resolveImageDigest(ctx, client, build.Namespace, build.Spec.Builder.Image, build.Spec.Builder.Credentials)
+		if err != nil {
+			return nil, err
+		}
+
+		inputs.Builder = digest
+	}
+
+	data, err := json.Marshal(inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	fingerprint := &buildv1alpha1.BuildRunFingerprint{Digest: fmt.Sprintf("sha256:%x", sha256.Sum256(data))}
+	if len(revisions) > 0 {
+		fingerprint.Revisions = revisions
+	}
+
+	return fingerprint, nil
+}
+
+// LastSucceededBuildRun returns the BuildRun of the Build that succeeded last and recorded a fingerprint, or nil
+func LastSucceededBuildRun(ctx context.Context, client client.Client, build *buildv1alpha1.Build, buildRun *buildv1alpha1.BuildRun) (*buildv1alpha1.BuildRun, error) {
+	buildRuns := &buildv1alpha1.BuildRunList{}
+	if err := client.List(ctx, buildRuns, inNamespace(buildRun.Namespace), ofBuild(build.Name)); err != nil {
+		return nil, err
+	}
+
+	var last *buildv1alpha1.BuildRun
+	for i := range buildRuns.Items {
+		other := &buildRuns.Items[i]
+		if other.Name == buildRun.Name || other.Status.Fingerprint == nil || other.Status.CompletionTime == nil {
+			continue
+		}
+
+		if other.Status.GetCondition(buildv1alpha1.Succeeded).GetStatus() != corev1.ConditionTrue {
+			continue
+		}
+
+		if last == nil || last.Status.CompletionTime.Before(other.Status.CompletionTime) {
+			last = other
+		}
+	}
+
+	return last, nil
+}
+
+// MarkBuildRunUpToDate completes a BuildRun without a TaskRun, its sources and its output are the ones of the
+// previous BuildRun with the same fingerprint
+func MarkBuildRunUpToDate(buildRun *buildv1alpha1.BuildRun, previous *buildv1alpha1.BuildRun) {
+	now := metav1.Now()
+	buildRun.Status.StartTime = &now
+	buildRun.Status.CompletionTime = &now
+	buildRun.Status.Sources = previous.Status.Sources
+	buildRun.Status.Output = previous.Status.Output
+
+	buildRun.Status.SetCondition(&buildv1alpha1.Condition{
+		LastTransitionTime: now,
+		Type:               buildv1alpha1.Succeeded,
+		Status:             corev1.ConditionTrue,
+		Reason:             ConditionUpToDate,
+		Message:            fmt.Sprintf("the inputs of the BuildRun equal the ones of BuildRun %s, its output is up to date", previous.Name),
+	})
+}
+
+// IsUpToDate returns whether a BuildRun succeeded without a TaskRun because its inputs did not change
+func IsUpToDate(buildRun *buildv1alpha1.BuildRun) bool {
+	return buildRun.Status.GetCondition(buildv1alpha1.Succeeded).GetReason() == ConditionUpToDate
+}
+
+// VerifyFingerprintRevisions removes the fingerprint of a BuildRun if a Git source was built from another commit
+// than the one that was resolved before the TaskRun was created, for example because a branch moved in between
+func VerifyFingerprintRevisions(buildRun *buildv1alpha1.BuildRun) {
+	if buildRun.Status.Fingerprint == nil {
+		return
+	}
+
+	for _, source := range buildRun.Status.Sources {
+		if source.Git == nil {
+			continue
+		}
+
+		if revision, ok := buildRun.Status.Fingerprint.Revisions[source.Name]; ok && revision != source.Git.CommitSha {
+			buildRun.Status.Fingerprint = nil
+			return
+		}
+	}
+}
+
+func strategyKind(build *buildv1alpha1.Build) buildv1alpha1.BuildStrategyKind {
+	if build.Spec.Strategy.Kind != nil {
+		return *build.Spec.Strategy.Kind
+	}
+
+	return buildv1alpha1.NamespacedBuildStrategyKind
+}
+
+// resolveGitRevision resolves the commit of a revision of a Git repository, credentials are supported for basic
+// authentication
+func resolveGitRevision(ctx context.Context, client client.Client, namespace string, url string, revision string, credentials *corev1.LocalObjectReference) (string, error) {
+	var auth transport.AuthMethod
+	if credentials != nil {
+		secret := &corev1.Secret{}
+		if err := client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: credentials.Name}, secret); err != nil {
+			return "", err
+		}
+
+		username, password := secret.Data[corev1.BasicAuthUsernameKey], secret.Data[corev1.BasicAuthPasswordKey]
+		if len(password) == 0 {
+			return "", fmt.Errorf("the credentials in secret %s do not support resolving the revision of %s", credentials.Name, url)
+		}
+
+		auth = &githttp.BasicAuth{Username: string(username), Password: string(password)}
+	}
+
+	return git.ResolveRevision(ctx, url, revision, auth)
+}
+
+// resolveImageDigest resolves the digest of an image, the credentials are a secret of type
+// kubernetes.io/dockerconfigjson
+func resolveImageDigest(ctx context.Context, client client.Client, namespace string, reference string, credentials *corev1.LocalObjectReference) (string, error) {
+	var dockerConfigJSON []byte
+	if credentials != nil {
+		secret := &corev1.Secret{}
+		if err := client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: credentials.Name}, secret); err != nil {
+			return "", err
+		}
+
+		dockerConfigJSON = secret.Data[corev1.DockerConfigJsonKey]
+	}
+
+	return image.ResolveDigest(ctx, reference, dockerConfigJSON)
+}
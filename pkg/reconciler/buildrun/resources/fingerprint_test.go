@@ -0,0 +1,175 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package resources_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	crc "sigs.k8s.io/controller-runtime/pkg/client"
+
+	buildv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	"github.com/shipwright-io/build/pkg/controller/fakes"
+	"github.com/shipwright-io/build/pkg/reconciler/buildrun/resources"
+)
+
+var _ = Describe("Fingerprint", func() {
+	const commitSha = "0c3e2d4c0c4e3bb2bc3c1d55b4de10c46d9b3e0f"
+
+	var (
+		client   *fakes.FakeClient
+		build    *buildv1alpha1.Build
+		buildRun *buildv1alpha1.BuildRun
+		strategy *buildv1alpha1.BuildStrategy
+	)
+
+	BeforeEach(func() {
+		client = &fakes.FakeClient{}
+		build = &buildv1alpha1.Build{
+			ObjectMeta: metav1.ObjectMeta{Name: "build", Namespace: "default"},
+			Spec: buildv1alpha1.BuildSpec{
+				Source: buildv1alpha1.Source{
+					URL:      pointer.String("https://github.com/shipwright-io/sample-go"),
+					Revision: pointer.String(commitSha),
+				},
+				Output: buildv1alpha1.Image{Image: "registry.example.com/some/image"},
+			},
+		}
+		buildRun = &buildv1alpha1.BuildRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "buildrun", Namespace: "default"},
+		}
+		strategy = &buildv1alpha1.BuildStrategy{
+			ObjectMeta: metav1.ObjectMeta{Name: "buildah", Generation: 1},
+		}
+	})
+
+	compute := func() *buildv1alpha1.BuildRunFingerprint {
+		fingerprint, err := resources.ComputeFingerprint(context.TODO(), client, build, buildRun, strategy)
+		Expect(err).ToNot(HaveOccurred())
+		return fingerprint
+	}
+
+	Context("computing the fingerprint", func() {
+		It("records the resolved revision of the Git source", func() {
+			fingerprint := compute()
+			Expect(fingerprint.Digest).To(MatchRegexp("^sha256:[a-f0-9]{64}$"))
+			Expect(fingerprint.Revisions).To(Equal(map[string]string{"default": commitSha}))
+			Expect(compute()).To(Equal(fingerprint))
+		})
+
+		It("changes with the generation of the strategy", func() {
+			fingerprint := compute()
+			strategy.Generation = 2
+			Expect(compute().Digest).ToNot(Equal(fingerprint.Digest))
+		})
+
+		It("changes with the environment variables of the BuildRun", func() {
+			fingerprint := compute()
+			buildRun.Spec.Env = []corev1.EnvVar{{Name: "SOME_VARIABLE", Value: "some-value"}}
+			Expect(compute().Digest).ToNot(Equal(fingerprint.Digest))
+		})
+
+		It("changes with the labels and annotations of the output image", func() {
+			fingerprint := compute()
+
+			build.Spec.Output.Labels = map[string]string{"maintainer": "team-a"}
+			labeled := compute()
+			Expect(labeled.Digest).ToNot(Equal(fingerprint.Digest))
+
+			buildRun.Spec.Output = &buildv1alpha1.Image{Image: build.Spec.Output.Image, Annotations: map[string]string{"org.opencontainers.image.url": "https://shipwright.io"}}
+			Expect(compute().Digest).ToNot(Equal(labeled.Digest))
+		})
+
+		It("changes with the credentials of the output image", func() {
+			fingerprint := compute()
+			build.Spec.Output.Credentials = &corev1.LocalObjectReference{Name: "registry-credentials"}
+			Expect(compute().Digest).ToNot(Equal(fingerprint.Digest))
+		})
+
+		It("changes with the source annotations of the output image", func() {
+			fingerprint := compute()
+			buildRun.Spec.Output = &buildv1alpha1.Image{Image: build.Spec.Output.Image, SourceAnnotations: pointer.Bool(true)}
+			Expect(compute().Digest).ToNot(Equal(fingerprint.Digest))
+		})
+
+		It("fails for a LocalCopy source", func() {
+			buildRun.Spec.Sources = []buildv1alpha1.BuildSource{{Name: "local", Type: buildv1alpha1.LocalCopy}}
+			_, err := resources.ComputeFingerprint(context.TODO(), client, build, buildRun, strategy)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("fails for an HTTP source without a digest", func() {
+			build.Spec.Sources = []buildv1alpha1.BuildSource{{Name: "logo", URL: "https://shipwright.io/icons/logo.svg"}}
+			_, err := resources.ComputeFingerprint(context.TODO(), client, build, buildRun, strategy)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("finding the last succeeded BuildRun", func() {
+		newBuildRun := func(name string, status corev1.ConditionStatus, age time.Duration, fingerprint *buildv1alpha1.BuildRunFingerprint) buildv1alpha1.BuildRun {
+			other := buildv1alpha1.BuildRun{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+				Status: buildv1alpha1.BuildRunStatus{
+					CompletionTime: &metav1.Time{Time: time.Now().Add(-age)},
+					Fingerprint:    fingerprint,
+				},
+			}
+			other.Status.SetCondition(&buildv1alpha1.Condition{Type: buildv1alpha1.Succeeded, Status: status})
+			return other
+		}
+
+		It("ignores failed BuildRuns and BuildRuns without a fingerprint", func() {
+			fingerprint := &buildv1alpha1.BuildRunFingerprint{Digest: "sha256:abc"}
+			client.ListCalls(func(_ context.Context, list crc.ObjectList, _ ...crc.ListOption) error {
+				list.(*buildv1alpha1.BuildRunList).Items = []buildv1alpha1.BuildRun{
+					newBuildRun("buildrun-a", corev1.ConditionTrue, time.Hour, fingerprint),
+					newBuildRun("buildrun-b", corev1.ConditionTrue, 30*time.Minute, fingerprint),
+					newBuildRun("buildrun-c", corev1.ConditionFalse, time.Minute, fingerprint),
+					newBuildRun("buildrun-d", corev1.ConditionTrue, time.Minute, nil),
+				}
+				return nil
+			})
+
+			last, err := resources.LastSucceededBuildRun(context.TODO(), client, build, buildRun)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(last.Name).To(Equal("buildrun-b"))
+		})
+	})
+
+	Context("verifying the revisions", func() {
+		BeforeEach(func() {
+			buildRun.Status.Fingerprint = &buildv1alpha1.BuildRunFingerprint{
+				Digest:    "sha256:abc",
+				Revisions: map[string]string{"default": commitSha},
+			}
+		})
+
+		It("keeps the fingerprint if the resolved commit was built", func() {
+			buildRun.Status.Sources = []buildv1alpha1.SourceResult{{Name: "default", Git: &buildv1alpha1.GitSourceResult{CommitSha: commitSha}}}
+			resources.VerifyFingerprintRevisions(buildRun)
+			Expect(buildRun.Status.Fingerprint).ToNot(BeNil())
+		})
+
+		It("removes the fingerprint if another commit was built", func() {
+			buildRun.Status.Sources = []buildv1alpha1.SourceResult{{Name: "default", Git: &buildv1alpha1.GitSourceResult{CommitSha: "a2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d5"}}}
+			resources.VerifyFingerprintRevisions(buildRun)
+			Expect(buildRun.Status.Fingerprint).To(BeNil())
+		})
+	})
+
+	It("prefers the setting of the BuildRun", func() {
+		build.Spec.SkipIfUnchanged = pointer.Bool(true)
+		Expect(resources.IsSkipIfUnchanged(build, buildRun)).To(BeTrue())
+
+		buildRun.Spec.SkipIfUnchanged = pointer.Bool(false)
+		Expect(resources.IsSkipIfUnchanged(build, buildRun)).To(BeFalse())
+	})
+})
@@ -11,6 +11,14 @@ import (
 
 	buildv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
 	"github.com/shipwright-io/build/pkg/config"
+	"github.com/shipwright-io/build/pkg/reconciler/buildrun/resources/sources"
+)
+
+// OCI standard annotations, see https://github.com/opencontainers/image-spec/blob/main/annotations.md
+const (
+	annotationImageSource   = "org.opencontainers.image.source"
+	annotationImageRevision = "org.opencontainers.image.revision"
+	annotationImageCreated  = "org.opencontainers.image.created"
 )
 
 // amendTaskSpecWithImageMutate add more steps to Tekton's Task in order to
@@ -19,6 +27,7 @@ func amendTaskSpecWithImageMutate(
 	cfg *config.Config,
 	taskSpec *tektonv1beta1.TaskSpec,
 	buildOutput, buildRunOutput buildv1alpha1.Image,
+	source *buildv1alpha1.Source,
 ) {
 	// initialize the step from the template
 	mutateStep := tektonv1beta1.Step{
@@ -33,10 +42,71 @@ func amendTaskSpecWithImageMutate(
 
 	mutateStep.Container.Args = mutateArgs(annotations, labels)
 
+	// the source annotations are added for those keys that were not explicitly defined
+	if source != nil {
+		mutateStep.Container.Args = append(mutateStep.Container.Args, sourceAnnotationArgs(taskSpec, *source, annotations)...)
+	}
+
 	// append the mutate step
 	taskSpec.Steps = append(taskSpec.Steps, mutateStep)
 }
 
+// isSourceAnnotationsEnabled returns whether the OCI standard source annotations
+// are requested, the BuildRun output setting overwrites the Build output setting
+func isSourceAnnotationsEnabled(buildOutput, buildRunOutput buildv1alpha1.Image) bool {
+	if buildRunOutput.SourceAnnotations != nil {
+		return *buildRunOutput.SourceAnnotations
+	}
+
+	return buildOutput.SourceAnnotations != nil && *buildOutput.SourceAnnotations
+}
+
+// sourceAnnotationArgs returns the mutate arguments for the OCI standard source
+// annotations. The revision is only known once the source step has run, and is
+// therefore passed as a reference to the result file of the source step.
+func sourceAnnotationArgs(taskSpec *tektonv1beta1.TaskSpec, source buildv1alpha1.Source, definedAnnotations map[string]string) []string {
+	var (
+		sourceValue  string
+		revisionFile string
+	)
+
+	switch {
+	case source.BundleContainer != nil && hasTaskResult(taskSpec, sources.BundleImageDigestResultName(defaultSourceName)):
+		sourceValue = source.BundleContainer.Image
+		revisionFile = fmt.Sprintf("$(results.%s.path)", sources.BundleImageDigestResultName(defaultSourceName))
+
+	case source.URL != nil && hasTaskResult(taskSpec, sources.GitCommitShaResultName(defaultSourceName)):
+		sourceValue = *source.URL
+		revisionFile = fmt.Sprintf("$(results.%s.path)", sources.GitCommitShaResultName(defaultSourceName))
+	}
+
+	var args []string
+
+	if _, defined := definedAnnotations[annotationImageSource]; !defined && sourceValue != "" {
+		args = append(args, "--annotation", fmt.Sprintf("%s=%s", annotationImageSource, sourceValue))
+	}
+
+	if _, defined := definedAnnotations[annotationImageRevision]; !defined && revisionFile != "" {
+		args = append(args, "--annotation-file", fmt.Sprintf("%s=%s", annotationImageRevision, revisionFile))
+	}
+
+	if _, defined := definedAnnotations[annotationImageCreated]; !defined {
+		args = append(args, "--annotation-created")
+	}
+
+	return args
+}
+
+func hasTaskResult(taskSpec *tektonv1beta1.TaskSpec, name string) bool {
+	for _, result := range taskSpec.Results {
+		if result.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
 // mergeMaps takes 2 maps as input and merge the second into the first
 // values in second would takes precedence if both maps have same keys
 func mergeMaps(first map[string]string, second map[string]string) map[string]string {
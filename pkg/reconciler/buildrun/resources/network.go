@@ -0,0 +1,131 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"path"
+	"strings"
+
+	v1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+
+	buildv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	"github.com/shipwright-io/build/pkg/config"
+)
+
+const (
+	caBundleVolumeName = prefixParamsResultsVolumes + "-ca-bundle"
+	caBundleMountPath  = "/etc/shipwright/ca-bundle"
+	caBundleFileName   = "ca-bundle.crt"
+)
+
+// effectiveProxy returns the proxy settings of the Build, or alternatively the
+// cluster-wide proxy settings
+func effectiveProxy(cfg *config.Config, build *buildv1alpha1.Build) *buildv1alpha1.Proxy {
+	if build.Spec.Proxy != nil {
+		return build.Spec.Proxy
+	}
+
+	if cfg.Proxy.HTTPProxy == "" && cfg.Proxy.HTTPSProxy == "" && cfg.Proxy.NoProxy == "" {
+		return nil
+	}
+
+	return &buildv1alpha1.Proxy{
+		HTTPProxy:  cfg.Proxy.HTTPProxy,
+		HTTPSProxy: cfg.Proxy.HTTPSProxy,
+		NoProxy:    cfg.Proxy.NoProxy,
+	}
+}
+
+// effectiveCABundle returns the CA bundle of the Build, or alternatively the
+// cluster-wide CA bundle
+func effectiveCABundle(cfg *config.Config, build *buildv1alpha1.Build) *buildv1alpha1.CABundle {
+	if build.Spec.CABundle != nil {
+		return build.Spec.CABundle
+	}
+
+	if cfg.CABundle.ConfigMap == "" {
+		return nil
+	}
+
+	return &buildv1alpha1.CABundle{
+		ConfigMap: cfg.CABundle.ConfigMap,
+		Key:       cfg.CABundle.Key,
+	}
+}
+
+// amendStepWithNetworkSettings configures the proxy and the CA bundle on a step that
+// the controller generates, the step receives the path of the CA bundle through the
+// --ca-bundle argument
+func amendStepWithNetworkSettings(
+	cfg *config.Config,
+	taskSpec *v1beta1.TaskSpec,
+	step *v1beta1.Step,
+	build *buildv1alpha1.Build,
+) {
+	if proxy := effectiveProxy(cfg, build); proxy != nil {
+		for _, envVar := range []corev1.EnvVar{
+			{Name: "HTTP_PROXY", Value: proxy.HTTPProxy},
+			{Name: "HTTPS_PROXY", Value: proxy.HTTPSProxy},
+			{Name: "NO_PROXY", Value: proxy.NoProxy},
+		} {
+			if envVar.Value == "" {
+				continue
+			}
+
+			// tools differ in whether they read the upper or lower case variant
+			step.Env = append(step.Env, envVar, corev1.EnvVar{
+				Name:  strings.ToLower(envVar.Name),
+				Value: envVar.Value,
+			})
+		}
+	}
+
+	caBundle := effectiveCABundle(cfg, build)
+	if caBundle == nil {
+		return
+	}
+
+	appendCABundleVolume(taskSpec, caBundle)
+
+	step.VolumeMounts = append(step.VolumeMounts, corev1.VolumeMount{
+		Name:      caBundleVolumeName,
+		MountPath: caBundleMountPath,
+		ReadOnly:  true,
+	})
+
+	step.Args = append(step.Args, "--ca-bundle", path.Join(caBundleMountPath, caBundleFileName))
+}
+
+// appendCABundleVolume adds the volume for the CA bundle ConfigMap if it does not exist yet
+func appendCABundleVolume(taskSpec *v1beta1.TaskSpec, caBundle *buildv1alpha1.CABundle) {
+	for _, volume := range taskSpec.Volumes {
+		if volume.Name == caBundleVolumeName {
+			return
+		}
+	}
+
+	key := caBundle.Key
+	if key == "" {
+		key = config.DefaultCABundleKey
+	}
+
+	taskSpec.Volumes = append(taskSpec.Volumes, corev1.Volume{
+		Name: caBundleVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: caBundle.ConfigMap,
+				},
+				Items: []corev1.KeyToPath{
+					{
+						Key:  key,
+						Path: caBundleFileName,
+					},
+				},
+			},
+		},
+	})
+}
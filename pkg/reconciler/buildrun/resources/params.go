@@ -66,6 +66,18 @@ func IsSystemReservedParameter(param string) bool {
 	return systemReservedParamKeys[param] || strings.HasPrefix(param, "shp-")
 }
 
+// FindSystemReservedParameters returns the names of the parameters of a build strategy that are reserved for the system
+func FindSystemReservedParameters(parameters []buildv1alpha1.Parameter) []string {
+	var reserved []string
+	for _, parameter := range parameters {
+		if IsSystemReservedParameter(parameter.Name) {
+			reserved = append(reserved, parameter.Name)
+		}
+	}
+
+	return reserved
+}
+
 // FindParameterByName returns the first entry in a Parameter array with a specified name, or nil
 func FindParameterByName(parameters []buildv1alpha1.Parameter, name string) *buildv1alpha1.Parameter {
 	for _, candidate := range parameters {
@@ -0,0 +1,145 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"fmt"
+	"strconv"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	buildv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+)
+
+// IsRerunRequested returns whether the BuildRun is annotated to be rerun
+func IsRerunRequested(buildRun *buildv1alpha1.BuildRun) bool {
+	return buildRun.GetAnnotations()[buildv1alpha1.AnnotationBuildRunRerun] == "true"
+}
+
+// NextRerunNumber returns the number of the next BuildRun that reruns the
+// BuildRun, it is one more than the number of its earlier reruns
+func NextRerunNumber(buildRun *buildv1alpha1.BuildRun) int {
+	reruns, err := strconv.Atoi(buildRun.GetAnnotations()[buildv1alpha1.AnnotationBuildRunReruns])
+	if err != nil || reruns < 0 {
+		return 1
+	}
+
+	return reruns + 1
+}
+
+// NewRerunBuildRun returns a BuildRun that runs the BuildRun again. It embeds
+// the Build snapshot of the BuildRun as its Build spec, so that changes of the
+// Build since the BuildRun ran do not apply, and its sources are pinned to the
+// Git commits and digests that the BuildRun resolved. Its name is derived from
+// the number of the rerun, so that it is the same for repeated attempts.
+func NewRerunBuildRun(buildRun *buildv1alpha1.BuildRun) *buildv1alpha1.BuildRun {
+	embedded := buildRun.Status.BuildSpec != nil
+
+	labels := map[string]string{}
+	for key, value := range buildRun.GetLabels() {
+		switch key {
+		// the controller sets the generation of the Build, and a rerun is not
+		// part of the history of the schedule
+		case buildv1alpha1.LabelBuildGeneration, buildv1alpha1.LabelBuildSchedule:
+		// a rerun with an embedded Build spec does not belong to the Build
+		case buildv1alpha1.LabelBuild:
+			if !embedded {
+				labels[key] = value
+			}
+		default:
+			labels[key] = value
+		}
+	}
+	labels[buildv1alpha1.LabelBuildRunRerunOf] = buildRun.Name
+
+	rerun := &buildv1alpha1.BuildRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: buildRun.Namespace,
+			Name:      fmt.Sprintf("%s-rerun-%d", buildRun.Name, NextRerunNumber(buildRun)),
+			Labels:    labels,
+		},
+		Spec: *buildRun.Spec.DeepCopy(),
+	}
+
+	// a canceled BuildRun runs again
+	rerun.Spec.State = nil
+
+	// without a snapshot, the BuildRun did not start and the rerun uses the
+	// Build as it is when the rerun starts
+	if embedded {
+		rerun.Spec.BuildRef = nil
+		rerun.Spec.BuildSpec = buildRun.Status.BuildSpec.DeepCopy()
+
+		// the settings that relate several BuildRuns of a Build are rejected
+		// in an embedded Build spec
+		rerun.Spec.BuildSpec.Schedule = nil
+		if retention := rerun.Spec.BuildSpec.Retention; retention != nil {
+			retention.FailedLimit = nil
+			retention.SucceededLimit = nil
+		}
+	}
+
+	for _, source := range buildRun.Status.Sources {
+		var pinnedSource buildv1alpha1.PinnedSource
+		switch {
+		case source.Git != nil && source.Git.CommitSha != "":
+			pinnedSource = buildv1alpha1.PinnedSource{Name: source.Name, Revision: source.Git.CommitSha}
+		case source.Bundle != nil && source.Bundle.Digest != "":
+			pinnedSource = buildv1alpha1.PinnedSource{Name: source.Name, Digest: source.Bundle.Digest}
+		case source.HTTP != nil && source.HTTP.Digest != "":
+			pinnedSource = buildv1alpha1.PinnedSource{Name: source.Name, Digest: source.HTTP.Digest}
+		default:
+			continue
+		}
+
+		rerun.Spec.PinnedSources = setPinnedSource(rerun.Spec.PinnedSources, pinnedSource)
+	}
+
+	return rerun
+}
+
+// PinSources pins the sources of the Build to the pinned sources of the BuildRun,
+// the Build is modified and must not be written back to the cluster
+func PinSources(build *buildv1alpha1.Build, buildRun *buildv1alpha1.BuildRun) {
+	for _, pinnedSource := range buildRun.Spec.PinnedSources {
+		if pinnedSource.Name == defaultSourceName {
+			switch {
+			case build.Spec.Source.BundleContainer != nil && pinnedSource.Digest != "":
+				build.Spec.Source.BundleContainer.Digest = pinnedSource.Digest
+			case build.Spec.Source.URL != nil && pinnedSource.Revision != "":
+				revision := pinnedSource.Revision
+				build.Spec.Source.Revision = &revision
+			}
+
+			continue
+		}
+
+		for i := range build.Spec.Sources {
+			source := &build.Spec.Sources[i]
+			if source.Name != pinnedSource.Name {
+				continue
+			}
+
+			switch {
+			case source.Type == buildv1alpha1.Git && pinnedSource.Revision != "":
+				revision := pinnedSource.Revision
+				source.Revision = &revision
+			case source.Type == buildv1alpha1.HTTP && pinnedSource.Digest != "":
+				source.Digest = pinnedSource.Digest
+			}
+		}
+	}
+}
+
+func setPinnedSource(pinnedSources []buildv1alpha1.PinnedSource, pinnedSource buildv1alpha1.PinnedSource) []buildv1alpha1.PinnedSource {
+	for i := range pinnedSources {
+		if pinnedSources[i].Name == pinnedSource.Name {
+			pinnedSources[i] = pinnedSource
+			return pinnedSources
+		}
+	}
+
+	return append(pinnedSources, pinnedSource)
+}
@@ -0,0 +1,171 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package resources_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	buildv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	"github.com/shipwright-io/build/pkg/reconciler/buildrun/resources"
+)
+
+var _ = Describe("Rerun", func() {
+	var buildRun *buildv1alpha1.BuildRun
+
+	BeforeEach(func() {
+		succeededLimit := uint(3)
+		buildRun = &buildv1alpha1.BuildRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      "buildrun",
+				Labels: map[string]string{
+					buildv1alpha1.LabelBuild:           "build",
+					buildv1alpha1.LabelBuildGeneration: "2",
+					buildv1alpha1.LabelBuildSchedule:   "build",
+					"team":                             "a",
+				},
+				Annotations: map[string]string{buildv1alpha1.AnnotationBuildRunRerun: "true"},
+			},
+			Spec: buildv1alpha1.BuildRunSpec{
+				BuildRef:    &buildv1alpha1.BuildRef{Name: "build"},
+				State:       buildv1alpha1.BuildRunRequestedStatePtr(buildv1alpha1.BuildRunStateCancel),
+				ParamValues: []buildv1alpha1.ParamValue{{Name: "a", SingleValue: &buildv1alpha1.SingleValue{Value: pointer.String("buildrun")}}},
+			},
+			Status: buildv1alpha1.BuildRunStatus{
+				BuildSpec: &buildv1alpha1.BuildSpec{
+					Source:   buildv1alpha1.Source{URL: pointer.String("https://github.com/shipwright-io/sample-go")},
+					Strategy: buildv1alpha1.Strategy{Name: "buildpacks-v3"},
+					ParamValues: []buildv1alpha1.ParamValue{
+						{Name: "a", SingleValue: &buildv1alpha1.SingleValue{Value: pointer.String("build")}},
+						{Name: "b", SingleValue: &buildv1alpha1.SingleValue{Value: pointer.String("build")}},
+					},
+					Schedule:  &buildv1alpha1.BuildSchedule{Cron: "0 2 * * *"},
+					Retention: &buildv1alpha1.BuildRetention{SucceededLimit: &succeededLimit},
+				},
+				Sources: []buildv1alpha1.SourceResult{
+					{Name: "default", Git: &buildv1alpha1.GitSourceResult{CommitSha: "abc123"}},
+					{Name: "artifact", HTTP: &buildv1alpha1.HTTPSourceResult{Digest: "sha256:def456"}},
+					{Name: "local", LocalCopy: &buildv1alpha1.LocalCopySourceResult{FileCount: 1}},
+				},
+			},
+		}
+	})
+
+	Context("creating the BuildRun that reruns a BuildRun", func() {
+		It("detects the annotation", func() {
+			Expect(resources.IsRerunRequested(buildRun)).To(BeTrue())
+
+			buildRun.Annotations = nil
+			Expect(resources.IsRerunRequested(buildRun)).To(BeFalse())
+		})
+
+		It("links the BuildRun with a label", func() {
+			rerun := resources.NewRerunBuildRun(buildRun)
+			Expect(rerun.Namespace).To(Equal("default"))
+			Expect(rerun.Labels).To(Equal(map[string]string{
+				buildv1alpha1.LabelBuildRunRerunOf: "buildrun",
+				"team":                             "a",
+			}))
+			Expect(rerun.Annotations).To(BeEmpty())
+		})
+
+		It("names the BuildRun after the number of the rerun", func() {
+			Expect(resources.NewRerunBuildRun(buildRun).Name).To(Equal("buildrun-rerun-1"))
+
+			buildRun.Annotations[buildv1alpha1.AnnotationBuildRunReruns] = "2"
+			Expect(resources.NextRerunNumber(buildRun)).To(Equal(3))
+			Expect(resources.NewRerunBuildRun(buildRun).Name).To(Equal("buildrun-rerun-3"))
+		})
+
+		It("copies the spec without the requested state", func() {
+			rerun := resources.NewRerunBuildRun(buildRun)
+			Expect(rerun.Spec.ParamValues).To(Equal(buildRun.Spec.ParamValues))
+			Expect(rerun.Spec.State).To(BeNil())
+		})
+
+		It("embeds the Build snapshot instead of referencing the Build", func() {
+			rerun := resources.NewRerunBuildRun(buildRun)
+			Expect(rerun.Spec.BuildRef).To(BeNil())
+			Expect(rerun.Spec.BuildSpec).ToNot(BeNil())
+			Expect(*rerun.Spec.BuildSpec.Source.URL).To(Equal("https://github.com/shipwright-io/sample-go"))
+			Expect(rerun.Spec.BuildSpec.Strategy.Name).To(Equal("buildpacks-v3"))
+			Expect(rerun.Spec.BuildSpec.ParamValues).To(HaveLen(2))
+		})
+
+		It("removes the settings that relate several BuildRuns of a Build from the embedded Build spec", func() {
+			rerun := resources.NewRerunBuildRun(buildRun)
+			Expect(rerun.Spec.BuildSpec.Schedule).To(BeNil())
+			Expect(rerun.Spec.BuildSpec.Retention.SucceededLimit).To(BeNil())
+			Expect(buildRun.Status.BuildSpec.Schedule).ToNot(BeNil())
+		})
+
+		It("references the Build if the BuildRun has no Build snapshot", func() {
+			buildRun.Status.BuildSpec = nil
+
+			rerun := resources.NewRerunBuildRun(buildRun)
+			Expect(rerun.Spec.BuildRef.Name).To(Equal("build"))
+			Expect(rerun.Spec.BuildSpec).To(BeNil())
+			Expect(rerun.Labels).To(HaveKeyWithValue(buildv1alpha1.LabelBuild, "build"))
+		})
+
+		It("pins the sources to the resolved commits and digests", func() {
+			buildRun.Spec.PinnedSources = []buildv1alpha1.PinnedSource{{Name: "default", Revision: "000000"}}
+
+			rerun := resources.NewRerunBuildRun(buildRun)
+			Expect(rerun.Spec.PinnedSources).To(Equal([]buildv1alpha1.PinnedSource{
+				{Name: "default", Revision: "abc123"},
+				{Name: "artifact", Digest: "sha256:def456"},
+			}))
+		})
+	})
+
+	Context("pinning the sources of a Build", func() {
+		var build *buildv1alpha1.Build
+
+		BeforeEach(func() {
+			build = &buildv1alpha1.Build{
+				Spec: buildv1alpha1.BuildSpec{
+					Source: buildv1alpha1.Source{URL: pointer.String("https://github.com/shipwright-io/sample-go"), Revision: pointer.String("main")},
+					Sources: []buildv1alpha1.BuildSource{
+						{Name: "artifact", Type: buildv1alpha1.HTTP, URL: "https://example.com/artifact.tar"},
+						{Name: "other", Type: buildv1alpha1.Git, URL: "https://github.com/shipwright-io/build"},
+					},
+				},
+			}
+			buildRun.Spec.PinnedSources = []buildv1alpha1.PinnedSource{
+				{Name: "default", Revision: "abc123"},
+				{Name: "artifact", Digest: "sha256:def456"},
+				{Name: "other", Revision: "fed789"},
+			}
+		})
+
+		It("pins the Git and HTTP sources", func() {
+			resources.PinSources(build, buildRun)
+			Expect(*build.Spec.Source.Revision).To(Equal("abc123"))
+			Expect(build.Spec.Sources[0].Digest).To(Equal("sha256:def456"))
+			Expect(*build.Spec.Sources[1].Revision).To(Equal("fed789"))
+		})
+
+		It("pins the bundle source", func() {
+			build.Spec.Source = buildv1alpha1.Source{BundleContainer: &buildv1alpha1.BundleContainer{Image: "ghcr.io/shipwright-io/sample-go/source-bundle:latest"}}
+			buildRun.Spec.PinnedSources = []buildv1alpha1.PinnedSource{{Name: "default", Digest: "sha256:abc123"}}
+
+			resources.PinSources(build, buildRun)
+			Expect(build.Spec.Source.BundleContainer.Digest).To(Equal("sha256:abc123"))
+		})
+
+		It("leaves the Build unchanged without pinned sources", func() {
+			buildRun.Spec.PinnedSources = nil
+
+			resources.PinSources(build, buildRun)
+			Expect(*build.Spec.Source.Revision).To(Equal("main"))
+			Expect(build.Spec.Sources[0].Digest).To(BeEmpty())
+		})
+	})
+})
@@ -69,6 +69,67 @@ var _ = Describe("TaskRun results to BuildRun", func() {
 			Expect(br.Status.Sources[0].Git.CommitAuthor).To(Equal("foo bar"))
 		})
 
+		It("should surface the TaskRun results of a git source and an overlaying LocalCopy source", func() {
+			br.Status.BuildSpec.Source.URL = pointer.String("https://github.com/shipwright-io/sample-go")
+			br.Spec.Sources = []build.BuildSource{{Name: "local", Type: build.LocalCopy, TargetPath: "overlay"}}
+
+			tr.Status.TaskRunResults = append(tr.Status.TaskRunResults,
+				pipelinev1beta1.TaskRunResult{
+					Name:  "shp-source-default-commit-sha",
+					Value: "0e0583421a5e4bf562ffe33f3651e16ba0c78591",
+				},
+				pipelinev1beta1.TaskRunResult{
+					Name:  "shp-source-local-file-count",
+					Value: "3",
+				},
+				pipelinev1beta1.TaskRunResult{
+					Name:  "shp-source-local-checksum",
+					Value: "sha256:7c6f1d38ce4d28a1fa9e1d1a9b4e8d7bd1e8e26bd7d2f9ab5ee7d3b7d5a4fd1c",
+				})
+
+			resources.UpdateBuildRunUsingTaskResults(ctx, br, tr.Status.TaskRunResults, taskRunRequest)
+
+			Expect(len(br.Status.Sources)).To(Equal(2))
+			Expect(br.Status.Sources[0].Name).To(Equal("default"))
+			Expect(br.Status.Sources[0].Git.CommitSha).To(Equal("0e0583421a5e4bf562ffe33f3651e16ba0c78591"))
+			Expect(br.Status.Sources[1].Name).To(Equal("local"))
+			Expect(br.Status.Sources[1].LocalCopy.FileCount).To(Equal(3))
+			Expect(br.Status.Sources[1].LocalCopy.Checksum).To(HavePrefix("sha256:"))
+		})
+
+		It("should surface the TaskRun results of additional Git sources named after the entries", func() {
+			br.Status.BuildSpec.Source.URL = pointer.String("https://github.com/shipwright-io/sample-go")
+			br.Status.BuildSpec.Sources = []build.BuildSource{{
+				Name:       "website",
+				Type:       build.Git,
+				URL:        "https://github.com/shipwright-io/website",
+				TargetPath: "website",
+			}}
+
+			tr.Status.TaskRunResults = append(tr.Status.TaskRunResults,
+				pipelinev1beta1.TaskRunResult{
+					Name:  "shp-source-default-commit-sha",
+					Value: "0e0583421a5e4bf562ffe33f3651e16ba0c78591",
+				},
+				pipelinev1beta1.TaskRunResult{
+					Name:  "shp-source-website-commit-sha",
+					Value: "7a51c1d9ab3a6ba3a4c63cb0c5e0fb3e89a0ea45",
+				},
+				pipelinev1beta1.TaskRunResult{
+					Name:  "shp-source-website-branch-name",
+					Value: "main",
+				})
+
+			resources.UpdateBuildRunUsingTaskResults(ctx, br, tr.Status.TaskRunResults, taskRunRequest)
+
+			Expect(len(br.Status.Sources)).To(Equal(2))
+			Expect(br.Status.Sources[0].Name).To(Equal("default"))
+			Expect(br.Status.Sources[0].Git.CommitSha).To(Equal("0e0583421a5e4bf562ffe33f3651e16ba0c78591"))
+			Expect(br.Status.Sources[1].Name).To(Equal("website"))
+			Expect(br.Status.Sources[1].Git.CommitSha).To(Equal("7a51c1d9ab3a6ba3a4c63cb0c5e0fb3e89a0ea45"))
+			Expect(br.Status.Sources[1].Git.BranchName).To(Equal("main"))
+		})
+
 		It("should surface the TaskRun results emitting from default(bundle) source step", func() {
 			bundleImageDigest := "sha256:fe1b73cd25ac3f11dec752755e2"
 			br.Status.BuildSpec.Source.BundleContainer = &build.BundleContainer{
@@ -87,6 +148,49 @@ var _ = Describe("TaskRun results to BuildRun", func() {
 			Expect(br.Status.Sources[0].Bundle.Digest).To(Equal(bundleImageDigest))
 		})
 
+		It("should surface the verification of the bundle image", func() {
+			br.Status.BuildSpec.Source.BundleContainer = &build.BundleContainer{
+				Image: "ghcr.io/shipwright-io/sample-go/source-bundle:latest",
+			}
+
+			tr.Status.TaskRunResults = append(tr.Status.TaskRunResults,
+				pipelinev1beta1.TaskRunResult{
+					Name:  "shp-source-default-image-digest",
+					Value: "sha256:fe1b73cd25ac3f11dec752755e2",
+				},
+				pipelinev1beta1.TaskRunResult{
+					Name:  "shp-source-default-verification",
+					Value: "digest,signature",
+				})
+
+			resources.UpdateBuildRunUsingTaskResults(ctx, br, tr.Status.TaskRunResults, taskRunRequest)
+
+			Expect(len(br.Status.Sources)).To(Equal(1))
+			Expect(br.Status.Sources[0].Bundle.DigestVerified).To(BeTrue())
+			Expect(br.Status.Sources[0].Bundle.SignatureVerified).To(BeTrue())
+		})
+
+		It("should surface the TaskRun results emitting from HTTP source steps", func() {
+			digest := "sha256:0d6b4c3e6fc0a1f4ea9ef1c3e88b5fcbd2a1b6e4f6f39c6cd4c8a5b1b0a3e7f2"
+			br.Status.BuildSpec.Sources = []build.BuildSource{{
+				Name: "Project_Logo",
+				Type: build.HTTP,
+				URL:  "https://shipwright.io/icons/logo.svg",
+			}}
+
+			tr.Status.TaskRunResults = append(tr.Status.TaskRunResults,
+				pipelinev1beta1.TaskRunResult{
+					Name:  "shp-source-project-logo-digest",
+					Value: digest,
+				})
+
+			resources.UpdateBuildRunUsingTaskResults(ctx, br, tr.Status.TaskRunResults, taskRunRequest)
+
+			Expect(len(br.Status.Sources)).To(Equal(1))
+			Expect(br.Status.Sources[0].Name).To(Equal("Project_Logo"))
+			Expect(br.Status.Sources[0].HTTP.Digest).To(Equal(digest))
+		})
+
 		It("should surface the TaskRun results emitting from output step", func() {
 			imageDigest := "sha256:fe1b73cd25ac3f11dec752755e2"
 
@@ -0,0 +1,170 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+
+	buildv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+)
+
+// ConditionRetrying is the reason of a BuildRun that failed and waits to be retried with a new TaskRun
+const ConditionRetrying = "Retrying"
+
+const defaultRetryBackoff = 10 * time.Second
+
+// defaultRetryReasons are the failure reasons that are retried if a retry policy does not define them,
+// they stem from evicted pods and the transient errors of the Git source step
+var defaultRetryReasons = []string{
+	buildv1alpha1.BuildRunStatePodEvicted,
+	"GitRateLimited",
+	"GitTimeout",
+	"GitHostNotResolved",
+}
+
+// EffectiveRetryPolicy returns the retry policy of the BuildRun, or the one of its Build
+func EffectiveRetryPolicy(buildRun *buildv1alpha1.BuildRun) *buildv1alpha1.RetryPolicy {
+	if buildRun.Spec.Retry != nil {
+		return buildRun.Spec.Retry
+	}
+
+	if buildRun.Status.BuildSpec != nil {
+		return buildRun.Status.BuildSpec.Retry
+	}
+
+	return nil
+}
+
+// IsRetryable returns whether the failed TaskRun of a BuildRun is retried according to its retry policy, the
+// reason of the failure is taken from the Succeeded condition and the failure details of the BuildRun
+func IsRetryable(buildRun *buildv1alpha1.BuildRun) bool {
+	if buildRun.IsCanceled() || !retriesRemain(buildRun) {
+		return false
+	}
+	policy := EffectiveRetryPolicy(buildRun)
+
+	condition := buildRun.Status.GetCondition(buildv1alpha1.Succeeded)
+	if condition == nil || condition.Status != corev1.ConditionFalse {
+		return false
+	}
+
+	reasons := policy.Reasons
+	if len(reasons) == 0 {
+		reasons = defaultRetryReasons
+	}
+
+	for _, reason := range reasons {
+		if reason == condition.Reason || (buildRun.Status.FailureDetails != nil && reason == buildRun.Status.FailureDetails.Reason) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// retriesRemain returns whether the retry policy of the BuildRun allows another attempt after the current one
+func retriesRemain(buildRun *buildv1alpha1.BuildRun) bool {
+	policy := EffectiveRetryPolicy(buildRun)
+	if policy == nil || hasLocalCopySource(buildRun) {
+		return false
+	}
+
+	// the current attempt is not part of the history yet
+	return len(buildRun.Status.Attempts)+1 < policy.MaxAttempts
+}
+
+// RecordRetryAttempt adds the failed TaskRun to the attempts of the BuildRun, and resets the status so that the
+// BuildRun can be continued with a new TaskRun once the backoff passed, logs references the archived logs of the
+// failed TaskRun if they were archived
+func RecordRetryAttempt(buildRun *buildv1alpha1.BuildRun, taskRun *v1beta1.TaskRun, logs *buildv1alpha1.BuildRunLogs) {
+	condition := buildRun.Status.GetCondition(buildv1alpha1.Succeeded)
+	reason, message := condition.Reason, condition.Message
+	if buildRun.Status.FailureDetails != nil && buildRun.Status.FailureDetails.Reason != "" {
+		reason, message = buildRun.Status.FailureDetails.Reason, buildRun.Status.FailureDetails.Message
+	}
+
+	completionTime := taskRun.Status.CompletionTime
+	if completionTime == nil {
+		now := metav1.Now()
+		completionTime = &now
+	}
+
+	buildRun.Status.Attempts = append(buildRun.Status.Attempts, buildv1alpha1.BuildRunAttempt{
+		TaskRunName:    taskRun.Name,
+		Reason:         reason,
+		Message:        message,
+		StartTime:      taskRun.Status.StartTime,
+		CompletionTime: completionTime,
+		Logs:           logs,
+	})
+
+	// the results of the failed attempt do not apply to the next one
+	buildRun.Status.LatestTaskRunRef = nil
+	buildRun.Status.Sources = nil
+	buildRun.Status.Output = nil
+	buildRun.Status.FailureDetails = nil
+	buildRun.Status.Steps = nil
+	//nolint:staticcheck // SA1019 the deprecated field is reset like the failure details
+	buildRun.Status.FailedAt = nil
+
+	policy := EffectiveRetryPolicy(buildRun)
+	buildRun.Status.SetCondition(&buildv1alpha1.Condition{
+		LastTransitionTime: metav1.Now(),
+		Type:               buildv1alpha1.Succeeded,
+		Status:             corev1.ConditionUnknown,
+		Reason:             ConditionRetrying,
+		Message: fmt.Sprintf("attempt %d of %d failed with reason %s, retrying in %s",
+			len(buildRun.Status.Attempts),
+			policy.MaxAttempts,
+			reason,
+			RetryBackoffRemaining(buildRun),
+		),
+	})
+}
+
+// RetryBackoffRemaining returns how long a BuildRun still waits before the next attempt, the backoff of the
+// retry policy is doubled for every further retry
+func RetryBackoffRemaining(buildRun *buildv1alpha1.BuildRun) time.Duration {
+	if len(buildRun.Status.Attempts) == 0 {
+		return 0
+	}
+
+	lastAttempt := buildRun.Status.Attempts[len(buildRun.Status.Attempts)-1]
+	if lastAttempt.CompletionTime == nil {
+		return 0
+	}
+
+	backoff := defaultRetryBackoff
+	if policy := EffectiveRetryPolicy(buildRun); policy != nil && policy.Backoff != nil {
+		backoff = policy.Backoff.Duration
+	}
+
+	for i := 1; i < len(buildRun.Status.Attempts); i++ {
+		backoff *= 2
+	}
+
+	if remaining := time.Until(lastAttempt.CompletionTime.Add(backoff)); remaining > 0 {
+		return remaining.Round(time.Second)
+	}
+
+	return 0
+}
+
+// IsPreviousAttempt returns whether a TaskRun belongs to a failed attempt of the BuildRun that was already retried
+func IsPreviousAttempt(buildRun *buildv1alpha1.BuildRun, taskRunName string) bool {
+	for _, attempt := range buildRun.Status.Attempts {
+		if attempt.TaskRunName == taskRunName {
+			return true
+		}
+	}
+
+	return false
+}
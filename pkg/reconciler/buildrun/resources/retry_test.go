@@ -0,0 +1,210 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package resources_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+
+	buildv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	"github.com/shipwright-io/build/pkg/config"
+	"github.com/shipwright-io/build/pkg/controller/fakes"
+	"github.com/shipwright-io/build/pkg/image"
+	"github.com/shipwright-io/build/pkg/reconciler/buildrun/resources"
+)
+
+var _ = Describe("Retry", func() {
+	var buildRun *buildv1alpha1.BuildRun
+
+	BeforeEach(func() {
+		buildRun = &buildv1alpha1.BuildRun{
+			Status: buildv1alpha1.BuildRunStatus{
+				BuildSpec: &buildv1alpha1.BuildSpec{
+					Retry: &buildv1alpha1.RetryPolicy{MaxAttempts: 3},
+				},
+			},
+		}
+		buildRun.Status.SetCondition(&buildv1alpha1.Condition{
+			Type:   buildv1alpha1.Succeeded,
+			Status: corev1.ConditionFalse,
+			Reason: "Failed",
+		})
+	})
+
+	Context("deciding whether a failure is retried", func() {
+		It("retries the transient reasons of the Git source step by default", func() {
+			buildRun.Status.FailureDetails = &buildv1alpha1.FailureDetails{Reason: "GitRateLimited"}
+			Expect(resources.IsRetryable(buildRun)).To(BeTrue())
+		})
+
+		It("does not retry other reasons by default", func() {
+			buildRun.Status.FailureDetails = &buildv1alpha1.FailureDetails{Reason: "GitRemoteRepositoryNotFound"}
+			Expect(resources.IsRetryable(buildRun)).To(BeFalse())
+		})
+
+		It("prefers the retry policy of the BuildRun", func() {
+			buildRun.Spec.Retry = &buildv1alpha1.RetryPolicy{MaxAttempts: 2, Reasons: []string{"Failed"}}
+			Expect(resources.IsRetryable(buildRun)).To(BeTrue())
+
+			buildRun.Status.Attempts = []buildv1alpha1.BuildRunAttempt{{TaskRunName: "buildrun-abcde"}}
+			Expect(resources.IsRetryable(buildRun)).To(BeFalse())
+		})
+
+		It("does not retry a canceled BuildRun", func() {
+			buildRun.Spec.State = buildv1alpha1.BuildRunRequestedStatePtr(buildv1alpha1.BuildRunStateCancel)
+			buildRun.Status.FailureDetails = &buildv1alpha1.FailureDetails{Reason: "GitRateLimited"}
+			Expect(resources.IsRetryable(buildRun)).To(BeFalse())
+		})
+	})
+
+	Context("waiting for the next attempt", func() {
+		It("doubles the backoff for every further retry", func() {
+			buildRun.Status.BuildSpec.Retry.Backoff = &metav1.Duration{Duration: time.Minute}
+			buildRun.Status.Attempts = []buildv1alpha1.BuildRunAttempt{
+				{TaskRunName: "buildrun-abcde", CompletionTime: &metav1.Time{Time: time.Now().Add(-time.Hour)}},
+				{TaskRunName: "buildrun-fghij", CompletionTime: &metav1.Time{Time: time.Now().Add(-time.Minute)}},
+			}
+
+			Expect(resources.RetryBackoffRemaining(buildRun)).To(BeNumerically("~", time.Minute, time.Second))
+		})
+
+		It("does not wait without attempts", func() {
+			Expect(resources.RetryBackoffRemaining(buildRun)).To(BeZero())
+		})
+	})
+
+	Context("pruning the bundle image", func() {
+		var build *buildv1alpha1.Build
+
+		pruneArgs := func() []string {
+			taskSpec := &pipeline.TaskSpec{}
+			resources.AmendTaskSpecWithSources(config.NewDefaultConfig(), taskSpec, build, buildRun)
+			Expect(taskSpec.Steps).ToNot(BeEmpty())
+
+			args := []string{}
+			for _, arg := range taskSpec.Steps[0].Args {
+				if arg == "--prune" {
+					args = append(args, arg)
+				}
+			}
+			return args
+		}
+
+		BeforeEach(func() {
+			prune := buildv1alpha1.PruneAfterPull
+			build = &buildv1alpha1.Build{
+				Spec: buildv1alpha1.BuildSpec{
+					Source: buildv1alpha1.Source{
+						BundleContainer: &buildv1alpha1.BundleContainer{
+							Image: "ghcr.io/shipwright-io/sample-go/source-bundle:latest",
+							Prune: &prune,
+						},
+					},
+				},
+			}
+		})
+
+		It("keeps the bundle image while attempts remain", func() {
+			Expect(pruneArgs()).To(BeEmpty())
+			Expect(*build.Spec.Source.BundleContainer.Prune).To(Equal(buildv1alpha1.PruneAfterPull))
+		})
+
+		It("prunes the bundle image in the last attempt", func() {
+			buildRun.Status.Attempts = []buildv1alpha1.BuildRunAttempt{{TaskRunName: "buildrun-abcde"}, {TaskRunName: "buildrun-fghij"}}
+			Expect(pruneArgs()).To(ConsistOf("--prune"))
+		})
+
+		It("prunes the bundle image without a retry policy", func() {
+			buildRun.Status.BuildSpec.Retry = nil
+			Expect(pruneArgs()).To(ConsistOf("--prune"))
+		})
+
+		Context("once the BuildRun completed", func() {
+			var (
+				server   *httptest.Server
+				imageRef string
+			)
+
+			exists := func() bool {
+				_, err := image.ResolveDigest(context.TODO(), imageRef, nil)
+				return err == nil
+			}
+
+			BeforeEach(func() {
+				server = httptest.NewServer(registry.New(registry.Logger(log.New(ioutil.Discard, "", 0))))
+				imageRef = fmt.Sprintf("%s/some/bundle:latest", strings.TrimPrefix(server.URL, "http://"))
+
+				ref, err := name.ParseReference(imageRef)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(remote.Write(ref, empty.Image)).To(Succeed())
+
+				digest, err := empty.Image.Digest()
+				Expect(err).ToNot(HaveOccurred())
+
+				build.Spec.Source.BundleContainer.Image = imageRef
+				buildRun.Status.BuildSpec.Source = build.Spec.Source
+				buildRun.Status.Sources = []buildv1alpha1.SourceResult{{
+					Name:   "default",
+					Bundle: &buildv1alpha1.BundleSourceResult{Digest: digest.String()},
+				}}
+			})
+
+			AfterEach(func() {
+				server.Close()
+			})
+
+			It("prunes the bundle image when the first of three attempts succeeded", func() {
+				buildRun.Status.SetCondition(&buildv1alpha1.Condition{
+					Type:   buildv1alpha1.Succeeded,
+					Status: corev1.ConditionTrue,
+					Reason: "Succeeded",
+				})
+
+				Expect(pruneArgs()).To(BeEmpty())
+				Expect(resources.PruneBundleImage(context.TODO(), &fakes.FakeClient{}, buildRun)).To(Succeed())
+				Expect(exists()).To(BeFalse())
+			})
+
+			It("prunes the bundle image when a failure is not retried", func() {
+				buildRun.Status.Attempts = []buildv1alpha1.BuildRunAttempt{{TaskRunName: "buildrun-abcde"}}
+
+				Expect(resources.IsRetryable(buildRun)).To(BeFalse())
+				Expect(resources.PruneBundleImage(context.TODO(), &fakes.FakeClient{}, buildRun)).To(Succeed())
+				Expect(exists()).To(BeFalse())
+			})
+
+			It("leaves the bundle image to the bundle step of the last attempt", func() {
+				buildRun.Status.Attempts = []buildv1alpha1.BuildRunAttempt{{TaskRunName: "buildrun-abcde"}, {TaskRunName: "buildrun-fghij"}}
+
+				Expect(resources.PruneBundleImage(context.TODO(), &fakes.FakeClient{}, buildRun)).To(Succeed())
+				Expect(exists()).To(BeTrue())
+			})
+
+			It("keeps a bundle image that was not pulled", func() {
+				buildRun.Status.Sources = nil
+
+				Expect(resources.PruneBundleImage(context.TODO(), &fakes.FakeClient{}, buildRun)).To(Succeed())
+				Expect(exists()).To(BeTrue())
+			})
+		})
+	})
+})
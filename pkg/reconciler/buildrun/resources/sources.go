@@ -5,8 +5,15 @@
 package resources
 
 import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
 	buildv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
 	"github.com/shipwright-io/build/pkg/config"
+	"github.com/shipwright-io/build/pkg/image"
 	"github.com/shipwright-io/build/pkg/reconciler/buildrun/resources/sources"
 
 	pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
@@ -33,35 +40,54 @@ func isLocalCopyBuildSource(
 	return nil
 }
 
-// AmendTaskSpecWithSources adds the necessary steps to either wait for user upload ("LocalCopy"), or
-// alternatively, configures the Task steps to use bundle and "git clone".
+// AmendTaskSpecWithSources adds the necessary steps to retrieve the sources. The Git or bundle
+// source of spec.source is retrieved first, followed by the additional Git repositories of
+// spec.sources. A "LocalCopy" upload is stored afterwards, so that it can overlay the files of
+// the repositories, and the remote artifacts are downloaded last.
 func AmendTaskSpecWithSources(
 	cfg *config.Config,
 	taskSpec *pipeline.TaskSpec,
 	build *buildv1alpha1.Build,
 	buildRun *buildv1alpha1.BuildRun,
 ) {
-	if localCopy := isLocalCopyBuildSource(build, buildRun); localCopy != nil {
-		sources.AppendLocalCopyStep(cfg, taskSpec, localCopy.Timeout)
-	} else {
-		// create the step for spec.source, either Git or Bundle
-		switch {
-		case build.Spec.Source.BundleContainer != nil:
-			sources.AppendBundleStep(cfg, taskSpec, build.Spec.Source, defaultSourceName)
-		case build.Spec.Source.URL != nil:
-			sources.AppendGitStep(cfg, taskSpec, build.Spec.Source, defaultSourceName)
+	// create the step for spec.source, either Git or Bundle
+	switch {
+	case build.Spec.Source.BundleContainer != nil:
+		sources.AppendBundleStep(cfg, taskSpec, bundleSourceForAttempt(build, buildRun), defaultSourceName)
+		amendStepWithNetworkSettings(cfg, taskSpec, lastStep(taskSpec), build)
+	case build.Spec.Source.URL != nil:
+		sources.AppendGitStep(cfg, taskSpec, build.Spec.Source, defaultSourceName)
+		amendStepWithNetworkSettings(cfg, taskSpec, lastStep(taskSpec), build)
+	}
+
+	// create a step for each additional Git repository, they are cloned into their target path
+	for _, source := range build.Spec.Sources {
+		if source.Type == buildv1alpha1.Git {
+			sources.AppendGitSourceStep(cfg, taskSpec, source)
+			amendStepWithNetworkSettings(cfg, taskSpec, lastStep(taskSpec), build)
 		}
 	}
 
+	// create the step that waits for the user upload
+	if localCopy := isLocalCopyBuildSource(build, buildRun); localCopy != nil {
+		sources.AppendLocalCopyStep(cfg, taskSpec, *localCopy)
+		amendStepWithUploadSettings(cfg, taskSpec, lastStep(taskSpec), buildRun)
+	}
+
 	// inspecting .spec.sources looking for "http" typed sources to generate the TaskSpec items
 	// in order to handle remote artifacts
 	for _, source := range build.Spec.Sources {
 		if source.Type == buildv1alpha1.HTTP {
 			sources.AppendHTTPStep(cfg, taskSpec, source)
+			amendStepWithNetworkSettings(cfg, taskSpec, lastStep(taskSpec), build)
 		}
 	}
 }
 
+func lastStep(taskSpec *pipeline.TaskSpec) *pipeline.Step {
+	return &taskSpec.Steps[len(taskSpec.Steps)-1]
+}
+
 func updateBuildRunStatusWithSourceResult(buildrun *buildv1alpha1.BuildRun, results []pipeline.TaskRunResult) {
 	buildSpec := buildrun.Status.BuildSpec
 
@@ -73,5 +99,68 @@ func updateBuildRunStatusWithSourceResult(buildrun *buildv1alpha1.BuildRun, resu
 		sources.AppendGitResult(buildrun, defaultSourceName, results)
 	}
 
-	// no results for HTTP sources yet
+	for _, source := range buildSpec.Sources {
+		switch source.Type {
+		case buildv1alpha1.Git:
+			sources.AppendGitResult(buildrun, source.Name, results)
+
+		case buildv1alpha1.HTTP:
+			sources.AppendHTTPResult(buildrun, source.Name, results)
+		}
+	}
+
+	if localCopy := isLocalCopyBuildSource(&buildv1alpha1.Build{Spec: *buildSpec}, buildrun); localCopy != nil {
+		sources.AppendLocalCopyResult(buildrun, localCopy.Name, results)
+	}
+}
+
+// bundleSourceForAttempt returns the bundle source of the Build, without the pruning of the
+// bundle image as long as the BuildRun can be retried, so that a retry can still pull it, the
+// image is then deleted by PruneBundleImage once the BuildRun completed
+func bundleSourceForAttempt(build *buildv1alpha1.Build, buildRun *buildv1alpha1.BuildRun) buildv1alpha1.Source {
+	source := *build.Spec.Source.DeepCopy()
+	if source.BundleContainer.Prune != nil && *source.BundleContainer.Prune == buildv1alpha1.PruneAfterPull && retriesRemain(buildRun) {
+		source.BundleContainer.Prune = nil
+	}
+
+	return source
+}
+
+// PruneBundleImage deletes the bundle image of a completed BuildRun, in case its bundle source
+// uses prune AfterPull and the bundle step kept the image for a retry that did not happen. The
+// bundle step of the last possible attempt deletes the image itself. An image that was not
+// pulled, which is when the BuildRun has no digest of it, is kept.
+func PruneBundleImage(ctx context.Context, client client.Client, buildRun *buildv1alpha1.BuildRun) error {
+	buildSpec := buildRun.Status.BuildSpec
+	if buildSpec == nil || buildSpec.Source.BundleContainer == nil {
+		return nil
+	}
+
+	bundleContainer := buildSpec.Source.BundleContainer
+	if bundleContainer.Prune == nil || *bundleContainer.Prune != buildv1alpha1.PruneAfterPull || !retriesRemain(buildRun) {
+		return nil
+	}
+
+	var digest string
+	for _, source := range buildRun.Status.Sources {
+		if source.Name == defaultSourceName && source.Bundle != nil {
+			digest = source.Bundle.Digest
+		}
+	}
+
+	if digest == "" {
+		return nil
+	}
+
+	var dockerConfigJSON []byte
+	if credentials := buildSpec.Source.Credentials; credentials != nil {
+		secret := &corev1.Secret{}
+		if err := client.Get(ctx, types.NamespacedName{Namespace: buildRun.Namespace, Name: credentials.Name}, secret); err != nil {
+			return err
+		}
+
+		dockerConfigJSON = secret.Data[corev1.DockerConfigJsonKey]
+	}
+
+	return image.DeleteImage(ctx, bundleContainer.Image, digest, dockerConfigJSON)
 }
@@ -6,6 +6,7 @@ package sources
 
 import (
 	"fmt"
+	"path"
 	"strings"
 
 	core "k8s.io/api/core/v1"
@@ -16,6 +17,10 @@ import (
 	pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 )
 
+// defaultPublicKeyKey is the key in the Secret that contains the public key,
+// in case the Build does not define one
+const defaultPublicKeyKey = "cosign.pub"
+
 // AppendBundleStep appends the bundle step to the TaskSpec
 func AppendBundleStep(
 	cfg *config.Config,
@@ -23,10 +28,13 @@ func AppendBundleStep(
 	source build.Source,
 	name string,
 ) {
-	// append the result
+	// append the results
 	taskSpec.Results = append(taskSpec.Results, pipeline.TaskResult{
 		Name:        fmt.Sprintf("%s-source-%s-image-digest", prefixParamsResultsVolumes, name),
 		Description: "The digest of the bundle image.",
+	}, pipeline.TaskResult{
+		Name:        BundleVerificationResultName(name),
+		Description: "The verifications of the bundle image that succeeded.",
 	})
 
 	// initialize the step from the template
@@ -40,6 +48,39 @@ func AppendBundleStep(
 		"--image", source.BundleContainer.Image,
 		"--target", fmt.Sprintf("$(params.%s-%s)", prefixParamsResultsVolumes, paramSourceRoot),
 		"--result-file-image-digest", fmt.Sprintf("$(results.%s-source-%s-image-digest.path)", prefixParamsResultsVolumes, name),
+		"--result-file-verification", fmt.Sprintf("$(results.%s.path)", BundleVerificationResultName(name)),
+		"--result-file-error-message", fmt.Sprintf("$(results.%s-error-message.path)", prefixParamsResultsVolumes),
+		"--result-file-error-reason", fmt.Sprintf("$(results.%s-error-reason.path)", prefixParamsResultsVolumes),
+	}
+
+	if source.BundleContainer.Digest != "" {
+		bundleStep.Container.Args = append(bundleStep.Container.Args, "--digest", source.BundleContainer.Digest)
+	}
+
+	if source.BundleContainer.Prune != nil && *source.BundleContainer.Prune == build.PruneAfterPull {
+		bundleStep.Container.Args = append(bundleStep.Container.Args, "--prune")
+	}
+
+	// add public key mount, if the signature needs to be verified
+	if publicKey := source.BundleContainer.PublicKey; publicKey != nil {
+		AppendSecretVolume(taskSpec, publicKey.Secret)
+
+		publicKeyMountPath := fmt.Sprintf("/workspace/%s-source-%s-public-key", prefixParamsResultsVolumes, name)
+
+		key := publicKey.Key
+		if key == "" {
+			key = defaultPublicKeyKey
+		}
+
+		bundleStep.VolumeMounts = append(bundleStep.VolumeMounts, core.VolumeMount{
+			Name:      SanitizeVolumeNameForSecretName(publicKey.Secret),
+			MountPath: publicKeyMountPath,
+			ReadOnly:  true,
+		})
+
+		bundleStep.Container.Args = append(bundleStep.Container.Args,
+			"--public-key", path.Join(publicKeyMountPath, key),
+		)
 	}
 
 	// add credentials mount, if provided
@@ -69,11 +110,32 @@ func AppendBundleResult(buildRun *build.BuildRun, name string, results []pipelin
 	imageDigest := findResultValue(results, fmt.Sprintf("%s-source-%s-image-digest", prefixParamsResultsVolumes, name))
 
 	if strings.TrimSpace(imageDigest) != "" {
+		bundleResult := &build.BundleSourceResult{
+			Digest: imageDigest,
+		}
+
+		for _, verification := range strings.Split(findResultValue(results, BundleVerificationResultName(name)), ",") {
+			switch strings.TrimSpace(verification) {
+			case "digest":
+				bundleResult.DigestVerified = true
+			case "signature":
+				bundleResult.SignatureVerified = true
+			}
+		}
+
 		buildRun.Status.Sources = append(buildRun.Status.Sources, build.SourceResult{
-			Name: name,
-			Bundle: &build.BundleSourceResult{
-				Digest: imageDigest,
-			},
+			Name:   name,
+			Bundle: bundleResult,
 		})
 	}
 }
+
+// BundleImageDigestResultName returns the name of the result that holds the image digest of the bundle source with the given name
+func BundleImageDigestResultName(name string) string {
+	return fmt.Sprintf("%s-source-%s-image-digest", prefixParamsResultsVolumes, name)
+}
+
+// BundleVerificationResultName returns the name of the result that holds the succeeded verifications of the bundle source with the given name
+func BundleVerificationResultName(name string) string {
+	return fmt.Sprintf("%s-source-%s-verification", prefixParamsResultsVolumes, name)
+}
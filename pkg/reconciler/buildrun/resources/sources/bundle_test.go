@@ -0,0 +1,99 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package sources_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	buildv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	"github.com/shipwright-io/build/pkg/config"
+	"github.com/shipwright-io/build/pkg/reconciler/buildrun/resources/sources"
+
+	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+)
+
+var _ = Describe("Bundle", func() {
+
+	cfg := config.NewDefaultConfig()
+
+	Context("when a TaskSpec does not contain an step", func() {
+
+		var taskSpec *tektonv1beta1.TaskSpec
+
+		BeforeEach(func() {
+			taskSpec = &tektonv1beta1.TaskSpec{}
+		})
+
+		It("adds the bundle step", func() {
+			sources.AppendBundleStep(cfg, taskSpec, buildv1alpha1.Source{
+				BundleContainer: &buildv1alpha1.BundleContainer{
+					Image: "registry.example.com/org/source:latest",
+				},
+			}, "default")
+
+			Expect(len(taskSpec.Results)).To(Equal(2))
+			Expect(taskSpec.Results[0].Name).To(Equal("shp-source-default-image-digest"))
+			Expect(taskSpec.Results[1].Name).To(Equal("shp-source-default-verification"))
+
+			Expect(len(taskSpec.Steps)).To(Equal(1))
+			Expect(taskSpec.Steps[0].Name).To(Equal("source-default"))
+			Expect(taskSpec.Steps[0].Args).To(Equal([]string{
+				"--image", "registry.example.com/org/source:latest",
+				"--target", "$(params.shp-source-root)",
+				"--result-file-image-digest", "$(results.shp-source-default-image-digest.path)",
+				"--result-file-verification", "$(results.shp-source-default-verification.path)",
+				"--result-file-error-message", "$(results.shp-error-message.path)",
+				"--result-file-error-reason", "$(results.shp-error-reason.path)",
+			}))
+		})
+
+		It("passes the digest and mounts the public key", func() {
+			sources.AppendBundleStep(cfg, taskSpec, buildv1alpha1.Source{
+				BundleContainer: &buildv1alpha1.BundleContainer{
+					Image:     "registry.example.com/org/source:latest",
+					Digest:    "sha256:0d6b4c3e6fc0a1f4ea9ef1c3e88b5fcbd2a1b6e4f6f39c6cd4c8a5b1b0a3e7f2",
+					PublicKey: &buildv1alpha1.PublicKeyReference{Secret: "bundle-signing"},
+				},
+			}, "default")
+
+			Expect(taskSpec.Steps[0].Args).To(ContainElements(
+				"--digest", "sha256:0d6b4c3e6fc0a1f4ea9ef1c3e88b5fcbd2a1b6e4f6f39c6cd4c8a5b1b0a3e7f2",
+				"--public-key", "/workspace/shp-source-default-public-key/cosign.pub",
+			))
+
+			Expect(len(taskSpec.Volumes)).To(Equal(1))
+			Expect(taskSpec.Volumes[0].Secret.SecretName).To(Equal("bundle-signing"))
+
+			Expect(len(taskSpec.Steps[0].VolumeMounts)).To(Equal(1))
+			Expect(taskSpec.Steps[0].VolumeMounts[0].Name).To(Equal(taskSpec.Volumes[0].Name))
+			Expect(taskSpec.Steps[0].VolumeMounts[0].MountPath).To(Equal("/workspace/shp-source-default-public-key"))
+		})
+
+		It("passes the prune flag in case the image should be deleted after the pull", func() {
+			prune := buildv1alpha1.PruneAfterPull
+			sources.AppendBundleStep(cfg, taskSpec, buildv1alpha1.Source{
+				BundleContainer: &buildv1alpha1.BundleContainer{
+					Image: "registry.example.com/org/source:latest",
+					Prune: &prune,
+				},
+			}, "default")
+
+			Expect(taskSpec.Steps[0].Args).To(ContainElement("--prune"))
+		})
+
+		It("does not pass the prune flag in case the image should never be deleted", func() {
+			prune := buildv1alpha1.PruneNever
+			sources.AppendBundleStep(cfg, taskSpec, buildv1alpha1.Source{
+				BundleContainer: &buildv1alpha1.BundleContainer{
+					Image: "registry.example.com/org/source:latest",
+					Prune: &prune,
+				},
+			}, "default")
+
+			Expect(taskSpec.Steps[0].Args).ToNot(ContainElement("--prune"))
+		})
+	})
+})
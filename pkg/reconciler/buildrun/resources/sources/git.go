@@ -26,6 +26,31 @@ func AppendGitStep(
 	taskSpec *tektonv1beta1.TaskSpec,
 	source buildv1alpha1.Source,
 	name string,
+) {
+	appendGitStep(cfg, taskSpec, source, name, fmt.Sprintf("$(params.%s-%s)", prefixParamsResultsVolumes, paramSourceRoot))
+}
+
+// AppendGitSourceStep appends the Git step and results and volume if needed to the TaskSpec
+// for an additional Git repository of the sources, which is cloned into its target path
+func AppendGitSourceStep(
+	cfg *config.Config,
+	taskSpec *tektonv1beta1.TaskSpec,
+	source buildv1alpha1.BuildSource,
+) {
+	url := source.URL
+	appendGitStep(cfg, taskSpec, buildv1alpha1.Source{
+		URL:         &url,
+		Revision:    source.Revision,
+		Credentials: source.Credentials,
+	}, SanitizeSourceName(source.Name), sourceTarget(source))
+}
+
+func appendGitStep(
+	cfg *config.Config,
+	taskSpec *tektonv1beta1.TaskSpec,
+	source buildv1alpha1.Source,
+	name string,
+	target string,
 ) {
 	// append the result
 	taskSpec.Results = append(taskSpec.Results, tektonv1beta1.TaskResult{
@@ -50,7 +75,7 @@ func AppendGitStep(
 		"--url",
 		*source.URL,
 		"--target",
-		fmt.Sprintf("$(params.%s-%s)", prefixParamsResultsVolumes, paramSourceRoot),
+		target,
 		"--result-file-commit-sha",
 		fmt.Sprintf("$(results.%s-source-%s-%s.path)", prefixParamsResultsVolumes, name, commitSHAResult),
 		"--result-file-commit-author",
@@ -105,9 +130,10 @@ func AppendGitStep(
 
 // AppendGitResult append git source result to build run
 func AppendGitResult(buildRun *buildv1alpha1.BuildRun, name string, results []tektonv1beta1.TaskRunResult) {
-	commitAuthor := findResultValue(results, fmt.Sprintf("%s-source-%s-%s", prefixParamsResultsVolumes, name, commitAuthorResult))
-	commitSha := findResultValue(results, fmt.Sprintf("%s-source-%s-%s", prefixParamsResultsVolumes, name, commitSHAResult))
-	branchName := findResultValue(results, fmt.Sprintf("%s-source-%s-%s", prefixParamsResultsVolumes, name, branchName))
+	resultName := SanitizeSourceName(name)
+	commitAuthor := findResultValue(results, fmt.Sprintf("%s-source-%s-%s", prefixParamsResultsVolumes, resultName, commitAuthorResult))
+	commitSha := findResultValue(results, fmt.Sprintf("%s-source-%s-%s", prefixParamsResultsVolumes, resultName, commitSHAResult))
+	branchName := findResultValue(results, fmt.Sprintf("%s-source-%s-%s", prefixParamsResultsVolumes, resultName, branchName))
 
 	if strings.TrimSpace(commitAuthor) != "" || strings.TrimSpace(commitSha) != "" || strings.TrimSpace(branchName) != "" {
 		buildRun.Status.Sources = append(buildRun.Status.Sources, buildv1alpha1.SourceResult{
@@ -120,3 +146,8 @@ func AppendGitResult(buildRun *buildv1alpha1.BuildRun, name string, results []te
 		})
 	}
 }
+
+// GitCommitShaResultName returns the name of the result that holds the commit SHA of the Git source with the given name
+func GitCommitShaResultName(name string) string {
+	return fmt.Sprintf("%s-source-%s-%s", prefixParamsResultsVolumes, name, commitSHAResult)
+}
@@ -124,4 +124,79 @@ var _ = Describe("Git", func() {
 			Expect(taskSpec.Steps[0].VolumeMounts[0].ReadOnly).To(BeTrue())
 		})
 	})
+
+	Context("when adding an additional Git source of the sources", func() {
+
+		var taskSpec *tektonv1beta1.TaskSpec
+
+		BeforeEach(func() {
+			taskSpec = &tektonv1beta1.TaskSpec{}
+		})
+
+		JustBeforeEach(func() {
+			sources.AppendGitSourceStep(cfg, taskSpec, buildv1alpha1.BuildSource{
+				Name:       "Website_Docs",
+				Type:       buildv1alpha1.Git,
+				URL:        "https://github.com/shipwright-io/website",
+				Revision:   pointer.String("main"),
+				TargetPath: "docs",
+				Credentials: &corev1.LocalObjectReference{
+					Name: "website-secret",
+				},
+			})
+		})
+
+		It("adds results named after the sanitized source name", func() {
+			Expect(len(taskSpec.Results)).To(Equal(3))
+			Expect(taskSpec.Results[0].Name).To(Equal("shp-source-website-docs-commit-sha"))
+			Expect(taskSpec.Results[1].Name).To(Equal("shp-source-website-docs-commit-author"))
+			Expect(taskSpec.Results[2].Name).To(Equal("shp-source-website-docs-branch-name"))
+		})
+
+		It("adds a step that clones into the target path", func() {
+			Expect(len(taskSpec.Steps)).To(Equal(1))
+			Expect(taskSpec.Steps[0].Name).To(Equal("source-website-docs"))
+			Expect(taskSpec.Steps[0].Args).To(Equal([]string{
+				"--url",
+				"https://github.com/shipwright-io/website",
+				"--target",
+				"$(params.shp-source-root)/docs",
+				"--result-file-commit-sha",
+				"$(results.shp-source-website-docs-commit-sha.path)",
+				"--result-file-commit-author",
+				"$(results.shp-source-website-docs-commit-author.path)",
+				"--result-file-branch-name",
+				"$(results.shp-source-website-docs-branch-name.path)",
+				"--result-file-error-message",
+				"$(results.shp-error-message.path)",
+				"--result-file-error-reason",
+				"$(results.shp-error-reason.path)",
+				"--revision",
+				"main",
+				"--secret-path",
+				"/workspace/shp-source-secret",
+			}))
+			Expect(len(taskSpec.Volumes)).To(Equal(1))
+			Expect(taskSpec.Volumes[0].VolumeSource.Secret.SecretName).To(Equal("website-secret"))
+		})
+	})
+
+	Context("when reading the result of an additional Git source", func() {
+
+		It("adds a source result named after the source", func() {
+			buildRun := &buildv1alpha1.BuildRun{}
+			sources.AppendGitResult(buildRun, "Website_Docs", []tektonv1beta1.TaskRunResult{{
+				Name:  "shp-source-website-docs-commit-sha",
+				Value: "0e0583421a5e4bf562ffe33f3651e16ba0c78591",
+			}, {
+				Name:  "shp-source-website-docs-branch-name",
+				Value: "main",
+			}})
+
+			Expect(len(buildRun.Status.Sources)).To(Equal(1))
+			Expect(buildRun.Status.Sources[0].Name).To(Equal("Website_Docs"))
+			Expect(buildRun.Status.Sources[0].Git.CommitSha).To(Equal("0e0583421a5e4bf562ffe33f3651e16ba0c78591"))
+			Expect(buildRun.Status.Sources[0].Git.BranchName).To(Equal("main"))
+		})
+	})
 })
@@ -6,6 +6,7 @@ package sources
 
 import (
 	"fmt"
+	"strings"
 
 	buildv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
 	"github.com/shipwright-io/build/pkg/config"
@@ -13,47 +14,99 @@ import (
 	corev1 "k8s.io/api/core/v1"
 )
 
-// RemoteArtifactsContainerName name for the container dealing with remote artifacts download.
-const RemoteArtifactsContainerName = "sources-http"
+const digestResult = "digest"
 
-// AppendHTTPStep appends the step for a HTTP source to the TaskSpec
+// AppendHTTPStep appends the step and the result for a HTTP source to the TaskSpec
 func AppendHTTPStep(
 	cfg *config.Config,
 	taskSpec *tektonv1beta1.TaskSpec,
 	source buildv1alpha1.BuildSource,
 ) {
-	// HTTP is done currently all in a single step, see if there is already one
-	httpStep := findExistingHTTPSourcesStep(taskSpec)
-	if httpStep != nil {
-		httpStep.Container.Args[3] = fmt.Sprintf("%s ; wget \"%s\"", httpStep.Container.Args[3], source.URL)
-	} else {
-		httpStep := tektonv1beta1.Step{
-			Container: corev1.Container{
-				Name:       RemoteArtifactsContainerName,
-				Image:      cfg.RemoteArtifactsContainerImage,
-				WorkingDir: fmt.Sprintf("$(params.%s-%s)", prefixParamsResultsVolumes, paramSourceRoot),
-				Command: []string{
-					"/bin/sh",
-				},
-				Args: []string{
-					"-e",
-					"-x",
-					"-c",
-					fmt.Sprintf("wget \"%s\"", source.URL),
-				},
-			},
-		}
+	name := SanitizeSourceName(source.Name)
+
+	// append the result
+	taskSpec.Results = append(taskSpec.Results, tektonv1beta1.TaskResult{
+		Name:        HTTPDigestResultName(name),
+		Description: "The digest of the downloaded file.",
+	})
+
+	// initialize the step from the template
+	httpStep := tektonv1beta1.Step{
+		Container: *cfg.HTTPContainerTemplate.DeepCopy(),
+	}
+
+	// add the build-specific details
+	httpStep.Container.Name = fmt.Sprintf("source-%s", name)
+	httpStep.Container.Args = []string{
+		"--url",
+		source.URL,
+		"--target",
+		sourceTarget(source),
+		"--result-file-digest",
+		fmt.Sprintf("$(results.%s.path)", HTTPDigestResultName(name)),
+		"--result-file-error-message",
+		fmt.Sprintf("$(results.%s-error-message.path)", prefixParamsResultsVolumes),
+		"--result-file-error-reason",
+		fmt.Sprintf("$(results.%s-error-reason.path)", prefixParamsResultsVolumes),
+	}
+
+	if source.Timeout != nil {
+		httpStep.Container.Args = append(httpStep.Container.Args, "--timeout", source.Timeout.Duration.String())
+	}
+
+	if source.Digest != "" {
+		httpStep.Container.Args = append(httpStep.Container.Args, "--sha256", source.Digest)
+	}
+
+	if source.Extract != nil {
+		httpStep.Container.Args = append(httpStep.Container.Args, "--extract", string(*source.Extract))
+	}
+
+	if source.Credentials != nil {
+		// ensure the value is there
+		AppendSecretVolume(taskSpec, source.Credentials.Name)
+
+		secretMountPath := fmt.Sprintf("/workspace/%s-source-%s-secret", prefixParamsResultsVolumes, name)
 
-		// append the git step
-		taskSpec.Steps = append(taskSpec.Steps, httpStep)
+		// define the volume mount on the container
+		httpStep.VolumeMounts = append(httpStep.VolumeMounts, corev1.VolumeMount{
+			Name:      SanitizeVolumeNameForSecretName(source.Credentials.Name),
+			MountPath: secretMountPath,
+			ReadOnly:  true,
+		})
+
+		// append the argument
+		httpStep.Container.Args = append(
+			httpStep.Container.Args,
+			"--secret-path",
+			secretMountPath,
+		)
 	}
+
+	// append the http step
+	taskSpec.Steps = append(taskSpec.Steps, httpStep)
 }
 
-func findExistingHTTPSourcesStep(taskSpec *tektonv1beta1.TaskSpec) *tektonv1beta1.Step {
-	for _, candidateStep := range taskSpec.Steps {
-		if candidateStep.Name == RemoteArtifactsContainerName {
-			return &candidateStep
-		}
+// AppendHTTPResult append HTTP source result to build run
+func AppendHTTPResult(buildRun *buildv1alpha1.BuildRun, name string, results []tektonv1beta1.TaskRunResult) {
+	digest := findResultValue(results, HTTPDigestResultName(SanitizeSourceName(name)))
+
+	if strings.TrimSpace(digest) != "" {
+		buildRun.Status.Sources = append(buildRun.Status.Sources, buildv1alpha1.SourceResult{
+			Name: name,
+			HTTP: &buildv1alpha1.HTTPSourceResult{
+				Digest: digest,
+			},
+		})
 	}
-	return nil
+}
+
+// HTTPDigestResultName returns the name of the result that holds the digest of the HTTP source with the given name
+func HTTPDigestResultName(name string) string {
+	return fmt.Sprintf("%s-source-%s-%s", prefixParamsResultsVolumes, name, digestResult)
+}
+
+// SanitizeSourceName turns the name of a source into a name that can be used for steps and results
+func SanitizeSourceName(name string) string {
+	return strings.Trim(dnsLabel1123Forbidden.ReplaceAllString(strings.ToLower(name), "-"), "-")
 }
@@ -5,6 +5,8 @@
 package sources_test
 
 import (
+	"time"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
@@ -14,6 +16,7 @@ import (
 
 	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 var _ = Describe("HTTP", func() {
@@ -34,82 +37,90 @@ var _ = Describe("HTTP", func() {
 				URL:  "https://shipwright.io/icons/logo.svg",
 			})
 
+			Expect(len(taskSpec.Results)).To(Equal(1))
+			Expect(taskSpec.Results[0].Name).To(Equal("shp-source-logo-digest"))
+
 			Expect(len(taskSpec.Steps)).To(Equal(1))
-			Expect(taskSpec.Steps[0].Name).To(Equal(sources.RemoteArtifactsContainerName))
-			Expect(taskSpec.Steps[0].Image).To(Equal(cfg.RemoteArtifactsContainerImage))
-			Expect(taskSpec.Steps[0].WorkingDir).To(Equal("$(params.shp-source-root)"))
-			Expect(taskSpec.Steps[0].Args[3]).To(Equal("wget \"https://shipwright.io/icons/logo.svg\""))
+			Expect(taskSpec.Steps[0].Name).To(Equal("source-logo"))
+			Expect(taskSpec.Steps[0].Image).To(Equal(cfg.HTTPContainerTemplate.Image))
+			Expect(taskSpec.Steps[0].Args).To(Equal([]string{
+				"--url", "https://shipwright.io/icons/logo.svg",
+				"--target", "$(params.shp-source-root)",
+				"--result-file-digest", "$(results.shp-source-logo-digest.path)",
+				"--result-file-error-message", "$(results.shp-error-message.path)",
+				"--result-file-error-reason", "$(results.shp-error-reason.path)",
+			}))
 		})
-	})
 
-	Context("when a TaskSpec already contains the http step", func() {
+		It("passes the timeout and the credentials", func() {
+			sources.AppendHTTPStep(cfg, taskSpec, buildv1alpha1.BuildSource{
+				Name:        "logo",
+				URL:         "https://shipwright.io/icons/logo.svg",
+				Timeout:     &metav1.Duration{Duration: 2 * time.Minute},
+				Credentials: &corev1.LocalObjectReference{Name: "download-secret"},
+			})
 
-		var taskSpec *tektonv1beta1.TaskSpec
+			Expect(len(taskSpec.Volumes)).To(Equal(1))
+			Expect(taskSpec.Volumes[0].Secret.SecretName).To(Equal("download-secret"))
 
-		BeforeEach(func() {
-			taskSpec = &tektonv1beta1.TaskSpec{
-				Steps: []tektonv1beta1.Step{
-					{
-						Container: corev1.Container{
-							Name:       sources.RemoteArtifactsContainerName,
-							Image:      cfg.RemoteArtifactsContainerImage,
-							WorkingDir: "$(params.shp-source-root)",
-							Command: []string{
-								"/bin/sh",
-							},
-							Args: []string{
-								"-e",
-								"-x",
-								"-c",
-								"wget \"https://tekton.dev/images/tekton-horizontal-color.png\"",
-							},
-						},
-					},
-				},
-			}
+			Expect(taskSpec.Steps[0].VolumeMounts).To(Equal([]corev1.VolumeMount{{
+				Name:      "shp-download-secret",
+				MountPath: "/workspace/shp-source-logo-secret",
+				ReadOnly:  true,
+			}}))
+			Expect(taskSpec.Steps[0].Args).To(ContainElements("--timeout", "2m0s"))
+			Expect(taskSpec.Steps[0].Args).To(ContainElements("--secret-path", "/workspace/shp-source-logo-secret"))
 		})
 
-		It("updates the existing step", func() {
+		It("passes the digest, the archive format and the target path", func() {
+			format := buildv1alpha1.ArchiveFormatTarGz
 			sources.AppendHTTPStep(cfg, taskSpec, buildv1alpha1.BuildSource{
-				Name: "logo",
+				Name:       "dependencies",
+				URL:        "https://shipwright.io/dependencies.tar.gz",
+				Digest:     "sha256:0d6b4c3e6fc0a1f4ea9ef1c3e88b5fcbd2a1b6e4f6f39c6cd4c8a5b1b0a3e7f2",
+				Extract:    &format,
+				TargetPath: "vendor/lib",
+			})
+
+			Expect(taskSpec.Steps[0].Args).To(ContainElements("--target", "$(params.shp-source-root)/vendor/lib"))
+			Expect(taskSpec.Steps[0].Args).To(ContainElements("--sha256", "sha256:0d6b4c3e6fc0a1f4ea9ef1c3e88b5fcbd2a1b6e4f6f39c6cd4c8a5b1b0a3e7f2"))
+			Expect(taskSpec.Steps[0].Args).To(ContainElements("--extract", "tar.gz"))
+		})
+
+		It("sanitizes the name of the source", func() {
+			sources.AppendHTTPStep(cfg, taskSpec, buildv1alpha1.BuildSource{
+				Name: "Project_Logo",
 				URL:  "https://shipwright.io/icons/logo.svg",
 			})
 
-			Expect(len(taskSpec.Steps)).To(Equal(1))
-			Expect(taskSpec.Steps[0].Name).To(Equal(sources.RemoteArtifactsContainerName))
-			Expect(taskSpec.Steps[0].Image).To(Equal(cfg.RemoteArtifactsContainerImage))
-			Expect(taskSpec.Steps[0].WorkingDir).To(Equal("$(params.shp-source-root)"))
-			Expect(taskSpec.Steps[0].Args[3]).To(Equal("wget \"https://tekton.dev/images/tekton-horizontal-color.png\" ; wget \"https://shipwright.io/icons/logo.svg\""))
+			Expect(taskSpec.Steps[0].Name).To(Equal("source-project-logo"))
+			Expect(taskSpec.Results[0].Name).To(Equal("shp-source-project-logo-digest"))
 		})
 	})
 
-	Context("when a TaskSpec already another source step step", func() {
+	Context("when a TaskSpec already contains a http step", func() {
 
 		var taskSpec *tektonv1beta1.TaskSpec
 
 		BeforeEach(func() {
-			taskSpec = &tektonv1beta1.TaskSpec{
-				Steps: []tektonv1beta1.Step{
-					{
-						Container: corev1.Container{
-							Name: "source-something",
-						},
-					},
-				},
-			}
+			taskSpec = &tektonv1beta1.TaskSpec{}
+			sources.AppendHTTPStep(cfg, taskSpec, buildv1alpha1.BuildSource{
+				Name: "tekton-logo",
+				URL:  "https://tekton.dev/images/tekton-horizontal-color.png",
+			})
 		})
 
-		It("appends the http step", func() {
+		It("appends a separate step", func() {
 			sources.AppendHTTPStep(cfg, taskSpec, buildv1alpha1.BuildSource{
 				Name: "logo",
 				URL:  "https://shipwright.io/icons/logo.svg",
 			})
 
 			Expect(len(taskSpec.Steps)).To(Equal(2))
-			Expect(taskSpec.Steps[1].Name).To(Equal(sources.RemoteArtifactsContainerName))
-			Expect(taskSpec.Steps[1].Image).To(Equal(cfg.RemoteArtifactsContainerImage))
-			Expect(taskSpec.Steps[1].WorkingDir).To(Equal("$(params.shp-source-root)"))
-			Expect(taskSpec.Steps[1].Args[3]).To(Equal("wget \"https://shipwright.io/icons/logo.svg\""))
+			Expect(taskSpec.Steps[0].Name).To(Equal("source-tekton-logo"))
+			Expect(taskSpec.Steps[0].Args[1]).To(Equal("https://tekton.dev/images/tekton-horizontal-color.png"))
+			Expect(taskSpec.Steps[1].Name).To(Equal("source-logo"))
+			Expect(taskSpec.Steps[1].Args[1]).To(Equal("https://shipwright.io/icons/logo.svg"))
 		})
 	})
 })
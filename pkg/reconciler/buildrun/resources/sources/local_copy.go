@@ -5,9 +5,12 @@ package sources
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/api/core/v1"
 
+	buildv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
 	"github.com/shipwright-io/build/pkg/config"
 	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 )
@@ -15,16 +18,83 @@ import (
 // WaiterContainerName name given to the container watier container.
 const WaiterContainerName = "source-local"
 
+const (
+	fileCountResult = "file-count"
+	checksumResult  = "checksum"
+)
+
 // AppendLocalCopyStep defines and append a new task based on the waiter container template, passed
 // by the configuration instance.
-func AppendLocalCopyStep(cfg *config.Config, taskSpec *tektonv1beta1.TaskSpec, timeout *metav1.Duration) {
+func AppendLocalCopyStep(cfg *config.Config, taskSpec *tektonv1beta1.TaskSpec, source buildv1alpha1.BuildSource) {
+	name := SanitizeSourceName(source.Name)
+
+	// append the results
+	taskSpec.Results = append(taskSpec.Results, tektonv1beta1.TaskResult{
+		Name:        LocalCopyFileCountResultName(name),
+		Description: "The number of files in the target path after the upload.",
+	}, tektonv1beta1.TaskResult{
+		Name:        LocalCopyChecksumResultName(name),
+		Description: "The checksum of the files in the target path after the upload.",
+	})
+
 	step := tektonv1beta1.Step{Container: *cfg.WaiterContainerTemplate.DeepCopy()}
 	// the data upload mechanism targets a specific POD, and in this POD it aims for a specific
 	// container name, and having a static name, makes this process straight forward.
 	step.Name = WaiterContainerName
 
-	if timeout != nil {
-		step.Args = append(step.Args, fmt.Sprintf("--timeout=%s", timeout.Duration.String()))
+	step.Args = append(step.Args,
+		fmt.Sprintf("--directory=%s", sourceTarget(source)),
+		fmt.Sprintf("--result-file-file-count=$(results.%s.path)", LocalCopyFileCountResultName(name)),
+		fmt.Sprintf("--result-file-checksum=$(results.%s.path)", LocalCopyChecksumResultName(name)),
+		fmt.Sprintf("--result-file-error-message=$(results.%s-error-message.path)", prefixParamsResultsVolumes),
+		fmt.Sprintf("--result-file-error-reason=$(results.%s-error-reason.path)", prefixParamsResultsVolumes),
+	)
+
+	if source.Timeout != nil {
+		step.Args = append(step.Args, fmt.Sprintf("--timeout=%s", source.Timeout.Duration.String()))
 	}
+
+	// the waiter is ready once it waits for the source code, this allows the
+	// controller to report that the BuildRun is waiting for the upload
+	if len(step.Command) > 0 && step.ReadinessProbe == nil {
+		step.ReadinessProbe = &corev1.Probe{
+			Handler: corev1.Handler{
+				Exec: &corev1.ExecAction{
+					Command: append(append([]string{}, step.Command...), "ready"),
+				},
+			},
+			PeriodSeconds: 2,
+		}
+	}
+
 	taskSpec.Steps = append(taskSpec.Steps, step)
 }
+
+// AppendLocalCopyResult append LocalCopy source result to build run
+func AppendLocalCopyResult(buildRun *buildv1alpha1.BuildRun, name string, results []tektonv1beta1.TaskRunResult) {
+	sanitizedName := SanitizeSourceName(name)
+	checksum := strings.TrimSpace(findResultValue(results, LocalCopyChecksumResultName(sanitizedName)))
+	if checksum == "" {
+		return
+	}
+
+	fileCount, _ := strconv.Atoi(strings.TrimSpace(findResultValue(results, LocalCopyFileCountResultName(sanitizedName))))
+
+	buildRun.Status.Sources = append(buildRun.Status.Sources, buildv1alpha1.SourceResult{
+		Name: name,
+		LocalCopy: &buildv1alpha1.LocalCopySourceResult{
+			FileCount: fileCount,
+			Checksum:  checksum,
+		},
+	})
+}
+
+// LocalCopyFileCountResultName returns the name of the result that holds the file count of the LocalCopy source with the given name
+func LocalCopyFileCountResultName(name string) string {
+	return fmt.Sprintf("%s-source-%s-%s", prefixParamsResultsVolumes, name, fileCountResult)
+}
+
+// LocalCopyChecksumResultName returns the name of the result that holds the checksum of the LocalCopy source with the given name
+func LocalCopyChecksumResultName(name string) string {
+	return fmt.Sprintf("%s-source-%s-%s", prefixParamsResultsVolumes, name, checksumResult)
+}
@@ -12,6 +12,7 @@ import (
 
 	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 
+	buildv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
 	"github.com/shipwright-io/build/pkg/config"
 	"github.com/shipwright-io/build/pkg/reconciler/buildrun/resources/sources"
 )
@@ -24,15 +25,69 @@ var _ = Describe("LocalCopy", func() {
 
 		BeforeEach(func() {
 			taskSpec = &tektonv1beta1.TaskSpec{}
-			sources.AppendLocalCopyStep(cfg, taskSpec, &metav1.Duration{Duration: time.Minute})
+			sources.AppendLocalCopyStep(cfg, taskSpec, buildv1alpha1.BuildSource{
+				Name:    "local",
+				Type:    buildv1alpha1.LocalCopy,
+				Timeout: &metav1.Duration{Duration: time.Minute},
+			})
 		})
 
 		It("produces a local-copy step", func() {
-			Expect(len(taskSpec.Results)).To(Equal(0))
+			Expect(len(taskSpec.Results)).To(Equal(2))
+			Expect(taskSpec.Results[0].Name).To(Equal("shp-source-local-file-count"))
+			Expect(taskSpec.Results[1].Name).To(Equal("shp-source-local-checksum"))
 			Expect(len(taskSpec.Steps)).To(Equal(1))
 			Expect(taskSpec.Steps[0].Name).To(Equal(sources.WaiterContainerName))
 			Expect(taskSpec.Steps[0].Image).To(Equal(cfg.WaiterContainerTemplate.Image))
-			Expect(taskSpec.Steps[0].Args).To(Equal([]string{"start", "--timeout=1m0s"}))
+			Expect(taskSpec.Steps[0].Args).To(Equal([]string{
+				"start",
+				"--directory=$(params.shp-source-root)",
+				"--result-file-file-count=$(results.shp-source-local-file-count.path)",
+				"--result-file-checksum=$(results.shp-source-local-checksum.path)",
+				"--result-file-error-message=$(results.shp-error-message.path)",
+				"--result-file-error-reason=$(results.shp-error-reason.path)",
+				"--timeout=1m0s",
+			}))
+		})
+
+		It("checks the readiness of the waiter", func() {
+			Expect(taskSpec.Steps[0].ReadinessProbe).ToNot(BeNil())
+			Expect(taskSpec.Steps[0].ReadinessProbe.Exec.Command).To(Equal([]string{"/ko-app/waiter", "ready"}))
+		})
+	})
+
+	Context("when the LocalCopy source has a target path", func() {
+		It("stores the upload in the sub-directory", func() {
+			taskSpec := &tektonv1beta1.TaskSpec{}
+			sources.AppendLocalCopyStep(cfg, taskSpec, buildv1alpha1.BuildSource{
+				Name:       "overlay",
+				Type:       buildv1alpha1.LocalCopy,
+				TargetPath: "src/local",
+			})
+
+			Expect(taskSpec.Steps[0].Name).To(Equal(sources.WaiterContainerName))
+			Expect(taskSpec.Steps[0].Args).To(ContainElement("--directory=$(params.shp-source-root)/src/local"))
+		})
+	})
+
+	Context("when the results of the LocalCopy source are surfaced", func() {
+		It("appends the file count and the checksum", func() {
+			buildRun := &buildv1alpha1.BuildRun{}
+			sources.AppendLocalCopyResult(buildRun, "local", []tektonv1beta1.TaskRunResult{
+				{Name: "shp-source-local-file-count", Value: "42"},
+				{Name: "shp-source-local-checksum", Value: "sha256:abc"},
+			})
+
+			Expect(buildRun.Status.Sources).To(Equal([]buildv1alpha1.SourceResult{{
+				Name:      "local",
+				LocalCopy: &buildv1alpha1.LocalCopySourceResult{FileCount: 42, Checksum: "sha256:abc"},
+			}}))
+		})
+
+		It("does not append a result without checksum", func() {
+			buildRun := &buildv1alpha1.BuildRun{}
+			sources.AppendLocalCopyResult(buildRun, "local", nil)
+			Expect(buildRun.Status.Sources).To(BeEmpty())
 		})
 	})
 })
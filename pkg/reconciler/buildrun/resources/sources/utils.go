@@ -6,8 +6,10 @@ package sources
 
 import (
 	"fmt"
+	"path"
 	"regexp"
 
+	buildv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
 	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/utils/pointer"
@@ -74,3 +76,13 @@ func findResultValue(results []tektonv1beta1.TaskRunResult, name string) string
 
 	return ""
 }
+
+// sourceTarget returns the directory that a source is downloaded, cloned, or stored into
+func sourceTarget(source buildv1alpha1.BuildSource) string {
+	target := fmt.Sprintf("$(params.%s-%s)", prefixParamsResultsVolumes, paramSourceRoot)
+	if source.TargetPath != "" {
+		target = path.Join(target, source.TargetPath)
+	}
+
+	return target
+}
@@ -0,0 +1,60 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"time"
+
+	pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	buildv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+)
+
+// UpdateBuildRunUsingTaskRunSteps surfaces the start and completion times, exit codes and termination reasons of
+// the steps of a TaskRun in the BuildRun status
+func UpdateBuildRunUsingTaskRunSteps(buildRun *buildv1alpha1.BuildRun, taskRun *pipeline.TaskRun) {
+	if len(taskRun.Status.Steps) == 0 {
+		return
+	}
+
+	steps := make([]buildv1alpha1.BuildRunStep, 0, len(taskRun.Status.Steps))
+	for _, stepState := range taskRun.Status.Steps {
+		step := buildv1alpha1.BuildRunStep{Name: stepState.Name}
+
+		switch {
+		case stepState.Terminated != nil:
+			step.StartTime = timeOrNil(stepState.Terminated.StartedAt)
+			step.CompletionTime = timeOrNil(stepState.Terminated.FinishedAt)
+			exitCode := stepState.Terminated.ExitCode
+			step.ExitCode = &exitCode
+			step.Reason = stepState.Terminated.Reason
+
+		case stepState.Running != nil:
+			step.StartTime = timeOrNil(stepState.Running.StartedAt)
+		}
+
+		steps = append(steps, step)
+	}
+
+	buildRun.Status.Steps = steps
+}
+
+// StepDuration returns the duration of a step, and whether the step terminated
+func StepDuration(step buildv1alpha1.BuildRunStep) (time.Duration, bool) {
+	if step.StartTime == nil || step.CompletionTime == nil {
+		return 0, false
+	}
+
+	return step.CompletionTime.Sub(step.StartTime.Time), true
+}
+
+func timeOrNil(t metav1.Time) *metav1.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return t.DeepCopy()
+}
@@ -0,0 +1,96 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package resources_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	buildv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	"github.com/shipwright-io/build/pkg/reconciler/buildrun/resources"
+)
+
+var _ = Describe("Steps", func() {
+	var (
+		buildRun *buildv1alpha1.BuildRun
+		taskRun  *pipeline.TaskRun
+		start    metav1.Time
+	)
+
+	BeforeEach(func() {
+		buildRun = &buildv1alpha1.BuildRun{}
+		taskRun = &pipeline.TaskRun{}
+		start = metav1.NewTime(time.Date(2021, time.September, 15, 10, 30, 0, 0, time.UTC))
+	})
+
+	Context("surfacing the steps of a TaskRun", func() {
+		It("records terminated, running and waiting steps", func() {
+			taskRun.Status.Steps = []pipeline.StepState{
+				{Name: "source-default", ContainerState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					ExitCode:   0,
+					Reason:     "Completed",
+					StartedAt:  start,
+					FinishedAt: metav1.NewTime(start.Add(4 * time.Minute)),
+				}}},
+				{Name: "build-and-push", ContainerState: corev1.ContainerState{Running: &corev1.ContainerStateRunning{
+					StartedAt: metav1.NewTime(start.Add(4 * time.Minute)),
+				}}},
+				{Name: "image-processing", ContainerState: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+					Reason: "PodInitializing",
+				}}},
+			}
+
+			resources.UpdateBuildRunUsingTaskRunSteps(buildRun, taskRun)
+
+			Expect(buildRun.Status.Steps).To(HaveLen(3))
+
+			Expect(buildRun.Status.Steps[0].Name).To(Equal("source-default"))
+			Expect(*buildRun.Status.Steps[0].ExitCode).To(BeZero())
+			Expect(buildRun.Status.Steps[0].Reason).To(Equal("Completed"))
+			duration, ok := resources.StepDuration(buildRun.Status.Steps[0])
+			Expect(ok).To(BeTrue())
+			Expect(duration).To(Equal(4 * time.Minute))
+
+			Expect(buildRun.Status.Steps[1].StartTime.Time).To(Equal(start.Add(4 * time.Minute)))
+			Expect(buildRun.Status.Steps[1].CompletionTime).To(BeNil())
+			Expect(buildRun.Status.Steps[1].ExitCode).To(BeNil())
+			_, ok = resources.StepDuration(buildRun.Status.Steps[1])
+			Expect(ok).To(BeFalse())
+
+			Expect(buildRun.Status.Steps[2]).To(Equal(buildv1alpha1.BuildRunStep{Name: "image-processing"}))
+		})
+
+		It("records the exit code and reason of a failed step", func() {
+			taskRun.Status.Steps = []pipeline.StepState{
+				{Name: "build-and-push", ContainerState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					ExitCode:   137,
+					Reason:     "OOMKilled",
+					StartedAt:  start,
+					FinishedAt: metav1.NewTime(start.Add(30 * time.Second)),
+				}}},
+			}
+
+			resources.UpdateBuildRunUsingTaskRunSteps(buildRun, taskRun)
+
+			Expect(buildRun.Status.Steps).To(HaveLen(1))
+			Expect(*buildRun.Status.Steps[0].ExitCode).To(Equal(int32(137)))
+			Expect(buildRun.Status.Steps[0].Reason).To(Equal("OOMKilled"))
+		})
+
+		It("keeps the steps if the TaskRun does not report any", func() {
+			buildRun.Status.Steps = []buildv1alpha1.BuildRunStep{{Name: "source-default"}}
+
+			resources.UpdateBuildRunUsingTaskRunSteps(buildRun, taskRun)
+
+			Expect(buildRun.Status.Steps).To(HaveLen(1))
+		})
+	})
+})
@@ -229,11 +229,19 @@ func GenerateTaskSpec(
 		buildRunOutput = &buildv1alpha1.Image{}
 	}
 
+	// the OCI standard annotations are derived from the source, when requested
+	var annotatedSource *buildv1alpha1.Source
+	if isSourceAnnotationsEnabled(build.Spec.Output, *buildRunOutput) {
+		annotatedSource = &build.Spec.Source
+	}
+
 	// Amending task spec with image mutate step if annotations or labels are
 	// specified in build manifest or buildRun manifest
 	if len(build.Spec.Output.Annotations) > 0 || len(build.Spec.Output.Labels) > 0 ||
-		len(buildRunOutput.Annotations) > 0 || len(buildRunOutput.Labels) > 0 {
-		amendTaskSpecWithImageMutate(cfg, &generatedTaskSpec, build.Spec.Output, *buildRunOutput)
+		len(buildRunOutput.Annotations) > 0 || len(buildRunOutput.Labels) > 0 ||
+		annotatedSource != nil {
+		amendTaskSpecWithImageMutate(cfg, &generatedTaskSpec, build.Spec.Output, *buildRunOutput, annotatedSource)
+		amendStepWithNetworkSettings(cfg, &generatedTaskSpec, lastStep(&generatedTaskSpec), build)
 	}
 
 	return &generatedTaskSpec, nil
@@ -272,8 +280,6 @@ func GenerateTaskRun(
 			GenerateName: buildRun.Name + "-",
 			Namespace:    buildRun.Namespace,
 			Labels: map[string]string{
-				buildv1alpha1.LabelBuild:              build.Name,
-				buildv1alpha1.LabelBuildGeneration:    strconv.FormatInt(build.Generation, 10),
 				buildv1alpha1.LabelBuildRun:           buildRun.Name,
 				buildv1alpha1.LabelBuildRunGeneration: strconv.FormatInt(buildRun.Generation, 10),
 			},
@@ -291,6 +297,12 @@ func GenerateTaskRun(
 		},
 	}
 
+	// a Build that is embedded in the BuildRun does not exist in the cluster
+	if !buildRun.HasEmbeddedBuildSpec() {
+		expectedTaskRun.Labels[buildv1alpha1.LabelBuild] = build.Name
+		expectedTaskRun.Labels[buildv1alpha1.LabelBuildGeneration] = strconv.FormatInt(build.Generation, 10)
+	}
+
 	// assign the annotations from the build strategy, filter out those that should not be propagated
 	taskRunAnnotations := make(map[string]string)
 	for key, value := range strategy.GetAnnotations() {
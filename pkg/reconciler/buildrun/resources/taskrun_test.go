@@ -17,6 +17,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
 
 	buildv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
 	"github.com/shipwright-io/build/pkg/config"
@@ -196,6 +197,226 @@ var _ = Describe("GenerateTaskrun", func() {
 			})
 		})
 
+		Context("when the source annotations are enabled", func() {
+			BeforeEach(func() {
+				build, err = ctl.LoadBuildYAML([]byte(test.BuildahBuildWithSourceAnnotations))
+				Expect(err).To(BeNil())
+
+				buildRun, err = ctl.LoadBuildRunFromBytes([]byte(test.MinimalBuildahBuildRun))
+				Expect(err).To(BeNil())
+
+				buildStrategy, err = ctl.LoadBuildStrategyFromBytes([]byte(test.MinimalBuildahBuildStrategy))
+				Expect(err).To(BeNil())
+			})
+
+			JustBeforeEach(func() {
+				got, err = resources.GenerateTaskSpec(config.NewDefaultConfig(), build, buildRun, buildStrategy.Spec.BuildSteps, []buildv1alpha1.Parameter{})
+				Expect(err).To(BeNil())
+			})
+
+			It("should contain a step to mutate the image with the source annotations", func() {
+				Expect(got.Steps[3].Name).To(Equal("mutate-image"))
+				Expect(got.Steps[3].Args).To(Equal([]string{
+					"--image",
+					"$(params.shp-output-image)",
+					"--result-file-image-digest",
+					"$(results.shp-image-digest.path)",
+					"result-file-image-size",
+					"$(results.shp-image-size.path)",
+					"--annotation",
+					"org.opencontainers.image.source=https://github.com/shipwright-io/sample-go",
+					"--annotation-file",
+					"org.opencontainers.image.revision=$(results.shp-source-default-commit-sha.path)",
+					"--annotation-created",
+				}))
+			})
+
+			Context("and an annotation is explicitly defined", func() {
+				BeforeEach(func() {
+					build.Spec.Output.Annotations = map[string]string{
+						"org.opencontainers.image.source": "https://github.com/org/repo",
+					}
+				})
+
+				It("should not overwrite the explicitly defined annotation", func() {
+					Expect(got.Steps[3].Name).To(Equal("mutate-image"))
+					Expect(got.Steps[3].Args).To(Equal([]string{
+						"--image",
+						"$(params.shp-output-image)",
+						"--result-file-image-digest",
+						"$(results.shp-image-digest.path)",
+						"result-file-image-size",
+						"$(results.shp-image-size.path)",
+						"--annotation",
+						"org.opencontainers.image.source=https://github.com/org/repo",
+						"--annotation-file",
+						"org.opencontainers.image.revision=$(results.shp-source-default-commit-sha.path)",
+						"--annotation-created",
+					}))
+				})
+			})
+
+			Context("and the BuildRun disables them", func() {
+				BeforeEach(func() {
+					buildRun.Spec.Output = &buildv1alpha1.Image{
+						Image:             "image-registry.openshift-image-registry.svc:5000/example/buildpacks-app",
+						SourceAnnotations: pointer.Bool(false),
+					}
+				})
+
+				It("should not contain a step to mutate the image", func() {
+					for _, step := range got.Steps {
+						Expect(step.Name).ToNot(Equal("mutate-image"))
+					}
+				})
+			})
+
+			Context("and the source is a bundle", func() {
+				BeforeEach(func() {
+					build.Spec.Source = buildv1alpha1.Source{
+						BundleContainer: &buildv1alpha1.BundleContainer{
+							Image: "ghcr.io/shipwright-io/sample-go/source-bundle:latest",
+						},
+					}
+				})
+
+				It("should use the bundle image and digest for the source annotations", func() {
+					Expect(got.Steps[3].Name).To(Equal("mutate-image"))
+					Expect(got.Steps[3].Args).To(ContainElements(
+						"org.opencontainers.image.source=ghcr.io/shipwright-io/sample-go/source-bundle:latest",
+						"org.opencontainers.image.revision=$(results.shp-source-default-image-digest.path)",
+					))
+				})
+			})
+		})
+
+		Context("when a proxy and a CA bundle are configured", func() {
+			var cfg *config.Config
+
+			findStep := func(name string) v1beta1.Step {
+				for _, step := range got.Steps {
+					if step.Name == name {
+						return step
+					}
+				}
+
+				Fail(fmt.Sprintf("step %s not found", name))
+				return v1beta1.Step{}
+			}
+
+			BeforeEach(func() {
+				build, err = ctl.LoadBuildYAML([]byte(test.BuildahBuildWithAnnotationAndLabel))
+				Expect(err).To(BeNil())
+
+				buildRun, err = ctl.LoadBuildRunFromBytes([]byte(test.MinimalBuildahBuildRun))
+				Expect(err).To(BeNil())
+
+				buildStrategy, err = ctl.LoadBuildStrategyFromBytes([]byte(test.MinimalBuildahBuildStrategy))
+				Expect(err).To(BeNil())
+
+				cfg = config.NewDefaultConfig()
+				cfg.Proxy = config.ProxyConfig{
+					HTTPSProxy: "http://proxy.example.com:3128",
+					NoProxy:    ".svc",
+				}
+				cfg.CABundle.ConfigMap = "cluster-ca-bundle"
+			})
+
+			JustBeforeEach(func() {
+				got, err = resources.GenerateTaskSpec(cfg, build, buildRun, buildStrategy.Spec.BuildSteps, []buildv1alpha1.Parameter{})
+				Expect(err).To(BeNil())
+			})
+
+			It("should mount the cluster-wide CA bundle", func() {
+				Expect(got.Volumes).To(ContainElement(corev1.Volume{
+					Name: "shp-ca-bundle",
+					VolumeSource: corev1.VolumeSource{
+						ConfigMap: &corev1.ConfigMapVolumeSource{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "cluster-ca-bundle"},
+							Items:                []corev1.KeyToPath{{Key: "ca-bundle.crt", Path: "ca-bundle.crt"}},
+						},
+					},
+				}))
+
+				for _, name := range []string{"source-default", "mutate-image"} {
+					step := findStep(name)
+					Expect(step.VolumeMounts).To(ContainElement(corev1.VolumeMount{
+						Name:      "shp-ca-bundle",
+						MountPath: "/etc/shipwright/ca-bundle",
+						ReadOnly:  true,
+					}))
+					Expect(step.Args).To(ContainElements("--ca-bundle", "/etc/shipwright/ca-bundle/ca-bundle.crt"))
+				}
+			})
+
+			It("should configure the cluster-wide proxy", func() {
+				for _, name := range []string{"source-default", "mutate-image"} {
+					Expect(findStep(name).Env).To(ContainElements(
+						corev1.EnvVar{Name: "HTTPS_PROXY", Value: "http://proxy.example.com:3128"},
+						corev1.EnvVar{Name: "https_proxy", Value: "http://proxy.example.com:3128"},
+						corev1.EnvVar{Name: "NO_PROXY", Value: ".svc"},
+						corev1.EnvVar{Name: "no_proxy", Value: ".svc"},
+					))
+				}
+			})
+
+			It("should not configure the build strategy steps", func() {
+				for _, buildStep := range buildStrategy.Spec.BuildSteps {
+					step := findStep(buildStep.Name)
+					Expect(step.Args).ToNot(ContainElement("--ca-bundle"))
+					for _, envVar := range step.Env {
+						Expect(envVar.Name).ToNot(Equal("HTTPS_PROXY"))
+					}
+				}
+			})
+
+			Context("and the Build defines its own settings", func() {
+				BeforeEach(func() {
+					build.Spec.Proxy = &buildv1alpha1.Proxy{
+						HTTPProxy: "http://build-proxy.example.com:8080",
+					}
+					build.Spec.CABundle = &buildv1alpha1.CABundle{
+						ConfigMap: "build-ca-bundle",
+						Key:       "certs.pem",
+					}
+				})
+
+				It("should use the settings of the Build", func() {
+					Expect(got.Volumes).To(ContainElement(corev1.Volume{
+						Name: "shp-ca-bundle",
+						VolumeSource: corev1.VolumeSource{
+							ConfigMap: &corev1.ConfigMapVolumeSource{
+								LocalObjectReference: corev1.LocalObjectReference{Name: "build-ca-bundle"},
+								Items:                []corev1.KeyToPath{{Key: "certs.pem", Path: "ca-bundle.crt"}},
+							},
+						},
+					}))
+
+					Expect(findStep("source-default").Env).To(Equal([]corev1.EnvVar{
+						{Name: "HTTP_PROXY", Value: "http://build-proxy.example.com:8080"},
+						{Name: "http_proxy", Value: "http://build-proxy.example.com:8080"},
+					}))
+				})
+			})
+
+			Context("and the Build has HTTP sources", func() {
+				BeforeEach(func() {
+					build.Spec.Sources = []buildv1alpha1.BuildSource{
+						{Name: "logo", Type: buildv1alpha1.HTTP, URL: "https://example.com/logo.png"},
+						{Name: "readme", Type: buildv1alpha1.HTTP, URL: "https://example.com/README.md"},
+					}
+				})
+
+				It("should configure the step of every HTTP source", func() {
+					for _, name := range []string{"source-logo", "source-readme"} {
+						step := findStep(name)
+						Expect(step.Args).To(ContainElements("--ca-bundle", "/etc/shipwright/ca-bundle/ca-bundle.crt"))
+						Expect(step.Env).To(ContainElement(corev1.EnvVar{Name: "NO_PROXY", Value: ".svc"}))
+					}
+				})
+			})
+		})
+
 		Context("when env vars are defined", func() {
 			BeforeEach(func() {
 				build, err = ctl.LoadBuildYAML([]byte(test.MinimalBuildahBuild))
@@ -0,0 +1,172 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package resources
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path"
+
+	pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	buildv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	"github.com/shipwright-io/build/pkg/config"
+	"github.com/shipwright-io/build/pkg/ctxlog"
+	"github.com/shipwright-io/build/pkg/reconciler/buildrun/resources/sources"
+	"github.com/shipwright-io/build/pkg/upload"
+)
+
+const uploadTokenMountPath = "/workspace/" + prefixParamsResultsVolumes + "-upload-token"
+
+// ErrUploadTokenNotOwned is returned when the Secret for the upload token exists, but is not
+// controlled by the BuildRun, its token could be known to whoever created the Secret
+var ErrUploadTokenNotOwned = errors.New("upload token secret is not controlled by the BuildRun")
+
+// EnsureUploadToken creates the Secret with the token that the upload endpoint
+// authenticates with at the waiter of a BuildRun with a LocalCopy source. It
+// does nothing when the upload endpoint is disabled. It returns ErrUploadTokenNotOwned
+// if a Secret with the name of the token exists that the BuildRun does not control.
+func EnsureUploadToken(ctx context.Context, client client.Client, cfg *config.Config, build *buildv1alpha1.Build, buildRun *buildv1alpha1.BuildRun) error {
+	if cfg.UploadServer.Address == "" || isLocalCopyBuildSource(build, buildRun) == nil {
+		return nil
+	}
+
+	secret := &corev1.Secret{}
+	err := client.Get(ctx, types.NamespacedName{Name: upload.TokenSecretName(buildRun), Namespace: buildRun.Namespace}, secret)
+	switch {
+	case err == nil:
+		return verifyUploadTokenOwner(secret, buildRun)
+
+	case apierrors.IsNotFound(err):
+		token := make([]byte, 32)
+		if _, err := rand.Read(token); err != nil {
+			return err
+		}
+
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      upload.TokenSecretName(buildRun),
+				Namespace: buildRun.Namespace,
+				Labels:    map[string]string{buildv1alpha1.LabelBuildRun: buildRun.Name},
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(buildRun, buildv1alpha1.SchemeGroupVersion.WithKind("BuildRun")),
+				},
+			},
+			Type: corev1.SecretTypeOpaque,
+			Data: map[string][]byte{
+				upload.TokenKey: []byte(hex.EncodeToString(token)),
+			},
+		}
+
+		if err := client.Create(ctx, secret); err != nil {
+			if !apierrors.IsAlreadyExists(err) {
+				return err
+			}
+
+			// someone else created the Secret in the meantime
+			existing := &corev1.Secret{}
+			if err := client.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}, existing); err != nil {
+				return err
+			}
+			return verifyUploadTokenOwner(existing, buildRun)
+		}
+
+		ctxlog.Info(ctx, "created upload token for BuildRun", namespace, buildRun.Namespace, name, secret.Name, "BuildRun", buildRun.Name)
+		return nil
+
+	default:
+		return err
+	}
+}
+
+// verifyUploadTokenOwner ensures that the Secret of the upload token is controlled by the BuildRun
+func verifyUploadTokenOwner(secret *corev1.Secret, buildRun *buildv1alpha1.BuildRun) error {
+	if !metav1.IsControlledBy(secret, buildRun) {
+		return fmt.Errorf("%w: %s", ErrUploadTokenNotOwned, secret.Name)
+	}
+	return nil
+}
+
+// amendStepWithUploadSettings configures the waiter step to receive the upload
+// from the upload endpoint
+func amendStepWithUploadSettings(cfg *config.Config, taskSpec *pipeline.TaskSpec, step *pipeline.Step, buildRun *buildv1alpha1.BuildRun) {
+	if cfg.UploadServer.Address == "" {
+		return
+	}
+
+	secretName := upload.TokenSecretName(buildRun)
+	sources.AppendSecretVolume(taskSpec, secretName)
+
+	step.VolumeMounts = append(step.VolumeMounts, corev1.VolumeMount{
+		Name:      sources.SanitizeVolumeNameForSecretName(secretName),
+		MountPath: uploadTokenMountPath,
+		ReadOnly:  true,
+	})
+
+	step.Args = append(step.Args, fmt.Sprintf("--upload-token-file=%s", path.Join(uploadTokenMountPath, upload.TokenKey)))
+
+	step.Ports = append(step.Ports, corev1.ContainerPort{
+		Name:          "upload",
+		ContainerPort: upload.ReceiverPort,
+		Protocol:      corev1.ProtocolTCP,
+	})
+}
+
+// IsAwaitingUpload returns whether the waiter step of a BuildRun with a
+// LocalCopy source did not complete yet
+func IsAwaitingUpload(buildRun *buildv1alpha1.BuildRun, taskRun *pipeline.TaskRun) bool {
+	if !hasLocalCopySource(buildRun) || taskRun.IsDone() {
+		return false
+	}
+
+	for _, step := range taskRun.Status.Steps {
+		if step.Name == sources.WaiterContainerName {
+			return step.Terminated == nil
+		}
+	}
+
+	return true
+}
+
+// isWaitingForUpload returns whether the waiter step of a BuildRun with a
+// LocalCopy source is ready to receive the source code
+func isWaitingForUpload(ctx context.Context, client client.Client, buildRun *buildv1alpha1.BuildRun, taskRun *pipeline.TaskRun) bool {
+	if !IsAwaitingUpload(buildRun, taskRun) || taskRun.Status.PodName == "" {
+		return false
+	}
+
+	pod := &corev1.Pod{}
+	if err := client.Get(ctx, types.NamespacedName{Namespace: taskRun.Namespace, Name: taskRun.Status.PodName}, pod); err != nil {
+		ctxlog.Debug(ctx, "failed to retrieve the pod of the TaskRun", namespace, taskRun.Namespace, name, taskRun.Status.PodName, "error", err.Error())
+		return false
+	}
+
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if containerStatus.Name == "step-"+sources.WaiterContainerName {
+			return containerStatus.State.Running != nil && containerStatus.Ready
+		}
+	}
+
+	return false
+}
+
+// hasLocalCopySource returns whether the BuildRun or the Build that it
+// references define a LocalCopy source
+func hasLocalCopySource(buildRun *buildv1alpha1.BuildRun) bool {
+	build := &buildv1alpha1.Build{}
+	if buildRun.Status.BuildSpec != nil {
+		build.Spec = *buildRun.Status.BuildSpec
+	}
+
+	return isLocalCopyBuildSource(build, buildRun) != nil
+}
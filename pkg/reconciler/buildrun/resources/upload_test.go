@@ -0,0 +1,260 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package resources_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"knative.dev/pkg/apis"
+	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
+	crc "sigs.k8s.io/controller-runtime/pkg/client"
+
+	buildv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	"github.com/shipwright-io/build/pkg/config"
+	"github.com/shipwright-io/build/pkg/controller/fakes"
+	"github.com/shipwright-io/build/pkg/reconciler/buildrun/resources"
+	"github.com/shipwright-io/build/pkg/upload"
+	"github.com/shipwright-io/build/test"
+)
+
+var _ = Describe("Upload of LocalCopy sources", func() {
+	var (
+		ctl      test.Catalog
+		cfg      *config.Config
+		client   *fakes.FakeClient
+		build    *buildv1alpha1.Build
+		buildRun *buildv1alpha1.BuildRun
+	)
+
+	BeforeEach(func() {
+		cfg = config.NewDefaultConfig()
+		cfg.UploadServer.Address = ":8443"
+
+		client = &fakes.FakeClient{}
+		client.GetCalls(func(_ context.Context, nn types.NamespacedName, _ crc.Object) error {
+			return k8serrors.NewNotFound(schema.GroupResource{}, nn.Name)
+		})
+
+		build = ctl.DefaultBuild("upload-build", "buildah", buildv1alpha1.ClusterBuildStrategyKind)
+		buildRun = ctl.DefaultBuildRun("upload-buildrun", "upload-build")
+		buildRun.UID = "upload-buildrun-uid"
+		buildRun.Spec.Sources = []buildv1alpha1.BuildSource{{Name: "local", Type: buildv1alpha1.LocalCopy}}
+	})
+
+	Context("creating the upload token", func() {
+		It("creates a Secret with a random token that is owned by the BuildRun", func() {
+			Expect(resources.EnsureUploadToken(context.TODO(), client, cfg, build, buildRun)).To(Succeed())
+			Expect(client.CreateCallCount()).To(Equal(1))
+
+			_, object, _ := client.CreateArgsForCall(0)
+			secret, ok := object.(*corev1.Secret)
+			Expect(ok).To(BeTrue())
+			Expect(secret.Name).To(Equal("upload-buildrun-upload-token"))
+			Expect(secret.Labels).To(HaveKeyWithValue(buildv1alpha1.LabelBuildRun, "upload-buildrun"))
+			Expect(secret.OwnerReferences).To(HaveLen(1))
+			Expect(secret.OwnerReferences[0].Name).To(Equal("upload-buildrun"))
+			Expect(secret.Data[upload.TokenKey]).To(HaveLen(64))
+		})
+
+		It("keeps an existing token", func() {
+			client.GetCalls(func(_ context.Context, _ types.NamespacedName, object crc.Object) error {
+				object.SetOwnerReferences([]metav1.OwnerReference{
+					*metav1.NewControllerRef(buildRun, buildv1alpha1.SchemeGroupVersion.WithKind("BuildRun")),
+				})
+				return nil
+			})
+
+			Expect(resources.EnsureUploadToken(context.TODO(), client, cfg, build, buildRun)).To(Succeed())
+			Expect(client.CreateCallCount()).To(Equal(0))
+		})
+
+		It("refuses an existing token that the BuildRun does not control", func() {
+			client.GetCalls(func(_ context.Context, _ types.NamespacedName, _ crc.Object) error {
+				return nil
+			})
+
+			err := resources.EnsureUploadToken(context.TODO(), client, cfg, build, buildRun)
+			Expect(err).To(MatchError(resources.ErrUploadTokenNotOwned))
+			Expect(client.CreateCallCount()).To(Equal(0))
+		})
+
+		It("refuses a token that was created by someone else in the meantime", func() {
+			client.CreateReturns(k8serrors.NewAlreadyExists(schema.GroupResource{}, "upload-buildrun-upload-token"))
+			client.GetCalls(func(_ context.Context, nn types.NamespacedName, _ crc.Object) error {
+				if client.CreateCallCount() == 0 {
+					return k8serrors.NewNotFound(schema.GroupResource{}, nn.Name)
+				}
+				return nil
+			})
+
+			err := resources.EnsureUploadToken(context.TODO(), client, cfg, build, buildRun)
+			Expect(err).To(MatchError(resources.ErrUploadTokenNotOwned))
+		})
+
+		It("does nothing when the upload endpoint is disabled", func() {
+			cfg.UploadServer.Address = ""
+
+			Expect(resources.EnsureUploadToken(context.TODO(), client, cfg, build, buildRun)).To(Succeed())
+			Expect(client.GetCallCount()).To(Equal(0))
+			Expect(client.CreateCallCount()).To(Equal(0))
+		})
+
+		It("does nothing for a BuildRun without LocalCopy source", func() {
+			buildRun.Spec.Sources = nil
+
+			Expect(resources.EnsureUploadToken(context.TODO(), client, cfg, build, buildRun)).To(Succeed())
+			Expect(client.CreateCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("amending the waiter step", func() {
+		It("configures the waiter to receive the upload", func() {
+			taskSpec := &pipeline.TaskSpec{}
+			resources.AmendTaskSpecWithSources(cfg, taskSpec, build, buildRun)
+
+			Expect(taskSpec.Steps).To(HaveLen(1))
+			step := taskSpec.Steps[0]
+			Expect(step.Name).To(Equal("source-local"))
+			Expect(step.Args).To(ContainElements(
+				"--directory=$(params.shp-source-root)",
+				"--upload-token-file=/workspace/shp-upload-token/token",
+			))
+			Expect(step.Ports).To(ContainElement(corev1.ContainerPort{Name: "upload", ContainerPort: upload.ReceiverPort, Protocol: corev1.ProtocolTCP}))
+			Expect(step.VolumeMounts).To(HaveLen(1))
+			Expect(step.VolumeMounts[0].MountPath).To(Equal("/workspace/shp-upload-token"))
+
+			Expect(taskSpec.Volumes).To(HaveLen(1))
+			Expect(taskSpec.Volumes[0].Secret.SecretName).To(Equal("upload-buildrun-upload-token"))
+		})
+
+		It("waits for the upload after the Git source was cloned", func() {
+			build.Spec.Source.URL = pointer.String("https://github.com/shipwright-io/sample-go")
+			build.Spec.Sources = []buildv1alpha1.BuildSource{{Name: "logo", Type: buildv1alpha1.HTTP, URL: "https://shipwright.io/icons/logo.svg"}}
+			buildRun.Spec.Sources[0].TargetPath = "overlay"
+
+			taskSpec := &pipeline.TaskSpec{}
+			resources.AmendTaskSpecWithSources(cfg, taskSpec, build, buildRun)
+
+			Expect(taskSpec.Steps).To(HaveLen(3))
+			Expect(taskSpec.Steps[0].Name).To(Equal("source-default"))
+			Expect(taskSpec.Steps[1].Name).To(Equal("source-local"))
+			Expect(taskSpec.Steps[1].Args).To(ContainElement("--directory=$(params.shp-source-root)/overlay"))
+			Expect(taskSpec.Steps[2].Name).To(Equal("source-logo"))
+		})
+
+		It("waits for the upload after the additional Git sources were cloned", func() {
+			build.Spec.Source.URL = pointer.String("https://github.com/shipwright-io/sample-go")
+			build.Spec.Sources = []buildv1alpha1.BuildSource{
+				{Name: "logo", Type: buildv1alpha1.HTTP, URL: "https://shipwright.io/icons/logo.svg"},
+				{Name: "website", Type: buildv1alpha1.Git, URL: "https://github.com/shipwright-io/website", TargetPath: "website"},
+			}
+
+			taskSpec := &pipeline.TaskSpec{}
+			resources.AmendTaskSpecWithSources(cfg, taskSpec, build, buildRun)
+
+			Expect(taskSpec.Steps).To(HaveLen(4))
+			Expect(taskSpec.Steps[0].Name).To(Equal("source-default"))
+			Expect(taskSpec.Steps[1].Name).To(Equal("source-website"))
+			Expect(taskSpec.Steps[1].Args).To(ContainElement("$(params.shp-source-root)/website"))
+			Expect(taskSpec.Steps[2].Name).To(Equal("source-local"))
+			Expect(taskSpec.Steps[3].Name).To(Equal("source-logo"))
+		})
+
+		It("keeps the waiter unchanged when the upload endpoint is disabled", func() {
+			cfg.UploadServer.Address = ""
+
+			taskSpec := &pipeline.TaskSpec{}
+			resources.AmendTaskSpecWithSources(cfg, taskSpec, build, buildRun)
+
+			Expect(taskSpec.Steps).To(HaveLen(1))
+			Expect(taskSpec.Steps[0].Ports).To(BeEmpty())
+			Expect(taskSpec.Volumes).To(BeEmpty())
+		})
+	})
+
+	Context("reporting that the BuildRun waits for the upload", func() {
+		var (
+			taskRun     *pipeline.TaskRun
+			pod         *corev1.Pod
+			trCondition *apis.Condition
+		)
+
+		BeforeEach(func() {
+			trCondition = &apis.Condition{
+				Type:   apis.ConditionSucceeded,
+				Status: corev1.ConditionUnknown,
+				Reason: "Running",
+			}
+
+			taskRun = &pipeline.TaskRun{
+				ObjectMeta: metav1.ObjectMeta{Name: "upload-buildrun-xyz", Namespace: "default"},
+				Status: pipeline.TaskRunStatus{
+					Status: duckv1beta1.Status{Conditions: duckv1beta1.Conditions{*trCondition}},
+					TaskRunStatusFields: pipeline.TaskRunStatusFields{
+						PodName: "upload-buildrun-xyz-pod",
+						Steps: []pipeline.StepState{{
+							Name:           "source-local",
+							ContainerState: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+						}},
+					},
+				},
+			}
+
+			pod = &corev1.Pod{
+				Status: corev1.PodStatus{
+					ContainerStatuses: []corev1.ContainerStatus{{
+						Name:  "step-source-local",
+						Ready: true,
+						State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+					}},
+				},
+			}
+
+			client.GetCalls(func(_ context.Context, nn types.NamespacedName, object crc.Object) error {
+				if object, ok := object.(*corev1.Pod); ok && nn.Name == taskRun.Status.PodName {
+					pod.DeepCopyInto(object)
+					return nil
+				}
+
+				return k8serrors.NewNotFound(schema.GroupResource{}, nn.Name)
+			})
+		})
+
+		It("sets the WaitingForUpload reason when the waiter is ready", func() {
+			Expect(resources.UpdateBuildRunUsingTaskRunCondition(context.TODO(), client, buildRun, taskRun, trCondition)).To(Succeed())
+
+			condition := buildRun.Status.GetCondition(buildv1alpha1.Succeeded)
+			Expect(condition.GetStatus()).To(Equal(corev1.ConditionUnknown))
+			Expect(condition.GetReason()).To(Equal(resources.ConditionWaitingForUpload))
+			Expect(condition.GetMessage()).To(ContainSubstring("upload-buildrun-xyz-pod"))
+		})
+
+		It("keeps the Running reason while the waiter is not ready", func() {
+			pod.Status.ContainerStatuses[0].Ready = false
+
+			Expect(resources.UpdateBuildRunUsingTaskRunCondition(context.TODO(), client, buildRun, taskRun, trCondition)).To(Succeed())
+			Expect(buildRun.Status.GetCondition(buildv1alpha1.Succeeded).GetReason()).To(Equal("Running"))
+			Expect(resources.IsAwaitingUpload(buildRun, taskRun)).To(BeTrue())
+		})
+
+		It("keeps the Running reason after the upload", func() {
+			taskRun.Status.Steps[0].ContainerState = corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{}}
+
+			Expect(resources.UpdateBuildRunUsingTaskRunCondition(context.TODO(), client, buildRun, taskRun, trCondition)).To(Succeed())
+			Expect(buildRun.Status.GetCondition(buildv1alpha1.Succeeded).GetReason()).To(Equal("Running"))
+			Expect(resources.IsAwaitingUpload(buildRun, taskRun)).To(BeFalse())
+		})
+	})
+})
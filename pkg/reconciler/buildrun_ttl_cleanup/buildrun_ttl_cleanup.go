@@ -6,6 +6,7 @@ package buildrun_ttl_cleanup
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	buildv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
@@ -15,6 +16,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -28,6 +30,7 @@ type ReconcileBuildRun struct {
 	config                *config.Config
 	client                client.Client
 	scheme                *runtime.Scheme
+	recorder              record.EventRecorder
 	setOwnerReferenceFunc setOwnerReferenceFunc
 }
 
@@ -36,6 +39,7 @@ func NewReconciler(c *config.Config, mgr manager.Manager, ownerRef setOwnerRefer
 		config:                c,
 		client:                mgr.GetClient(),
 		scheme:                mgr.GetScheme(),
+		recorder:              mgr.GetEventRecorderFor("buildrun-ttl-cleanup-controller"),
 		setOwnerReferenceFunc: ownerRef,
 	}
 }
@@ -81,6 +85,7 @@ func (r *ReconcileBuildRun) Reconcile(ctx context.Context, request reconcile.Req
 					ctxlog.Debug(ctx, "Error deleting buildRun.", namespace, request.Namespace, name, br.Name, deleteError, deleteBuildRunErr)
 					return reconcile.Result{}, deleteBuildRunErr
 				}
+				r.recordDeletion(ctx, br, fmt.Sprintf("Deleted BuildRun %s as its TTL of %s after succeeding was reached", br.Name, br.Status.BuildSpec.Retention.TtlAfterSucceeded.Duration))
 			} else {
 				timeLeft := br.Status.CompletionTime.Add(br.Status.BuildSpec.Retention.TtlAfterSucceeded.Duration).Sub(time.Now())
 				return reconcile.Result{Requeue: true, RequeueAfter: timeLeft}, nil
@@ -96,6 +101,7 @@ func (r *ReconcileBuildRun) Reconcile(ctx context.Context, request reconcile.Req
 					ctxlog.Debug(ctx, "Error deleting buildRun.", namespace, request.Namespace, name, br.Name, deleteError, deleteBuildRunErr)
 					return reconcile.Result{}, deleteBuildRunErr
 				}
+				r.recordDeletion(ctx, br, fmt.Sprintf("Deleted BuildRun %s as its TTL of %s after failing was reached", br.Name, br.Status.BuildSpec.Retention.TtlAfterFailed.Duration))
 			} else {
 				timeLeft := br.Status.CompletionTime.Add(br.Status.BuildSpec.Retention.TtlAfterFailed.Duration).Sub(time.Now())
 				return reconcile.Result{Requeue: true, RequeueAfter: timeLeft}, nil
@@ -105,3 +111,19 @@ func (r *ReconcileBuildRun) Reconcile(ctx context.Context, request reconcile.Req
 	ctxlog.Debug(ctx, "finishing reconciling request from a BuildRun event", namespace, request.Namespace, name, request.Name)
 	return reconcile.Result{}, nil
 }
+
+// recordDeletion records the deletion of a BuildRun as an event of its Build, because the events of the deleted
+// BuildRun can not be seen anymore, it is not recorded for a BuildRun without a Build
+func (r *ReconcileBuildRun) recordDeletion(ctx context.Context, br *buildv1alpha1.BuildRun, message string) {
+	if br.BuildName() == "" {
+		return
+	}
+
+	b := &buildv1alpha1.Build{}
+	if err := r.client.Get(ctx, types.NamespacedName{Name: br.BuildName(), Namespace: br.Namespace}, b); err != nil {
+		ctxlog.Debug(ctx, "not recording the deletion of the buildRun, its build was not found", namespace, br.Namespace, name, br.Name)
+		return
+	}
+
+	r.recorder.Event(b, corev1.EventTypeNormal, buildv1alpha1.EventReasonBuildRunDeleted, message)
+}
@@ -6,13 +6,22 @@ package buildstrategy
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
-	"github.com/shipwright-io/build/pkg/config"
-	"github.com/shipwright-io/build/pkg/ctxlog"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	buildv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	"github.com/shipwright-io/build/pkg/config"
+	"github.com/shipwright-io/build/pkg/ctxlog"
+	"github.com/shipwright-io/build/pkg/reconciler/buildrun/resources"
 )
 
 // blank assignment to verify that ReconcileBuildStrategy implements reconcile.Reconciler
@@ -22,17 +31,19 @@ var _ reconcile.Reconciler = &ReconcileBuildStrategy{}
 type ReconcileBuildStrategy struct {
 	// This client, initialized using mgr.Client() above, is a split client
 	// that reads objects from the cache and writes to the apiserver
-	config *config.Config
-	client client.Client
-	scheme *runtime.Scheme
+	config   *config.Config
+	client   client.Client
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
 }
 
 // NewReconciler returns a new reconcile.Reconciler
 func NewReconciler(c *config.Config, mgr manager.Manager) reconcile.Reconciler {
 	return &ReconcileBuildStrategy{
-		config: c,
-		client: mgr.GetClient(),
-		scheme: mgr.GetScheme(),
+		config:   c,
+		client:   mgr.GetClient(),
+		scheme:   mgr.GetScheme(),
+		recorder: mgr.GetEventRecorderFor("buildstrategy-controller"),
 	}
 }
 
@@ -45,5 +56,22 @@ func (r *ReconcileBuildStrategy) Reconcile(ctx context.Context, request reconcil
 	defer cancel()
 
 	ctxlog.Info(ctx, "reconciling BuildStrategy", "namespace", request.Namespace, "name", request.Name)
+
+	strategy := &buildv1alpha1.BuildStrategy{}
+	if err := r.client.Get(ctx, types.NamespacedName{Name: request.Name, Namespace: request.Namespace}, strategy); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+
+		return reconcile.Result{}, err
+	}
+
+	// a parameter with a reserved name collides with the parameters that the controller sets on the TaskRun
+	if reserved := resources.FindSystemReservedParameters(strategy.GetParameters()); len(reserved) > 0 {
+		r.recorder.Event(strategy, corev1.EventTypeWarning, string(buildv1alpha1.RestrictedParametersInUse), fmt.Sprintf("the parameters %s are reserved for the system and cannot be defined by a strategy", strings.Join(reserved, ", ")))
+		return reconcile.Result{}, nil
+	}
+
+	r.recorder.Event(strategy, corev1.EventTypeNormal, string(buildv1alpha1.SucceedStatus), buildv1alpha1.AllValidationsSucceeded)
 	return reconcile.Result{}, nil
 }
@@ -10,9 +10,15 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	crc "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	buildv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
 	"github.com/shipwright-io/build/pkg/config"
 	"github.com/shipwright-io/build/pkg/controller/fakes"
 	"github.com/shipwright-io/build/pkg/reconciler/clusterbuildstrategy"
@@ -21,6 +27,8 @@ import (
 var _ = Describe("Reconcile ClusterBuildStrategy", func() {
 	var (
 		manager           *fakes.FakeManager
+		recorder          *record.FakeRecorder
+		strategy          *buildv1alpha1.ClusterBuildStrategy
 		reconciler        reconcile.Reconciler
 		request           reconcile.Request
 		buildStrategyName string
@@ -31,6 +39,29 @@ var _ = Describe("Reconcile ClusterBuildStrategy", func() {
 
 		// Fake the manager and get a reconcile Request
 		manager = &fakes.FakeManager{}
+
+		strategy = &buildv1alpha1.ClusterBuildStrategy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: buildStrategyName,
+			},
+			Spec: buildv1alpha1.BuildStrategySpec{
+				Parameters: []buildv1alpha1.Parameter{{Name: "sleep-time"}},
+			},
+		}
+
+		client := &fakes.FakeClient{}
+		client.GetCalls(func(_ context.Context, nn types.NamespacedName, object crc.Object) error {
+			if strategy != nil && nn.Name == strategy.Name {
+				strategy.DeepCopyInto(object.(*buildv1alpha1.ClusterBuildStrategy))
+				return nil
+			}
+
+			return k8serrors.NewNotFound(schema.GroupResource{}, nn.Name)
+		})
+		manager.GetClientReturns(client)
+
+		recorder = record.NewFakeRecorder(10)
+		manager.GetEventRecorderForReturns(recorder)
 		request = reconcile.Request{NamespacedName: types.NamespacedName{Name: buildStrategyName}}
 	})
 
@@ -45,6 +76,25 @@ var _ = Describe("Reconcile ClusterBuildStrategy", func() {
 				result, err := reconciler.Reconcile(context.TODO(), request)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(reconcile.Result{}).To(Equal(result))
+				Expect(recorder.Events).To(Receive(Equal("Normal Succeeded all validations succeeded")))
+			})
+
+			It("records a warning when a parameter name is reserved", func() {
+				strategy.Spec.Parameters = append(strategy.Spec.Parameters, buildv1alpha1.Parameter{Name: "shp-output-image"})
+
+				_, err := reconciler.Reconcile(context.TODO(), request)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(recorder.Events).To(Receive(Equal("Warning RestrictedParametersInUse the parameters shp-output-image are reserved for the system and cannot be defined by a strategy")))
+			})
+		})
+
+		Context("when the ClusterBuildStrategy was deleted", func() {
+			It("succeed without any event", func() {
+				strategy = nil
+
+				_, err := reconciler.Reconcile(context.TODO(), request)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(recorder.Events).ToNot(Receive())
 			})
 		})
 	})
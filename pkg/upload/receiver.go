@@ -0,0 +1,114 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package upload
+
+import (
+	"compress/gzip"
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/shipwright-io/build/pkg/auth"
+	"github.com/shipwright-io/build/pkg/bundle"
+)
+
+// Receiver runs in the build pod, and unpacks an uploaded tar stream into the
+// source directory. It accepts one successful upload, and calls the done
+// function afterwards, so that the build continues.
+type Receiver struct {
+	// Progress is called with the number of bytes of the upload that were
+	// received so far, optional
+	Progress bundle.ProgressFunc
+
+	target string
+	token  string
+	done   func()
+
+	mutex    sync.Mutex
+	received bool
+}
+
+// NewReceiver returns a receiver that unpacks the upload into the target
+// directory, it only accepts requests that authenticate with the token
+func NewReceiver(target string, token string, done func()) *Receiver {
+	return &Receiver{
+		target: target,
+		token:  token,
+		done:   done,
+	}
+}
+
+// ServeHTTP implements http.Handler
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path != ReceiverPath {
+		http.NotFound(w, req)
+		return
+	}
+
+	if req.Method != http.MethodPut && req.Method != http.MethodPost {
+		http.Error(w, "only PUT and POST requests are supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if token, ok := auth.BearerToken(req); !ok || subtle.ConstantTimeCompare([]byte(token), []byte(r.token)) != 1 {
+		http.Error(w, "invalid upload token", http.StatusUnauthorized)
+		return
+	}
+
+	// uploads are processed one after the other, a failed upload can be
+	// repeated until one succeeded
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.received {
+		http.Error(w, "the source code was already uploaded", http.StatusConflict)
+		return
+	}
+
+	var in io.Reader = req.Body
+	if r.Progress != nil {
+		in = &progressReader{reader: in, progress: r.Progress}
+	}
+
+	if req.Header.Get("Content-Encoding") == "gzip" {
+		gzipReader, err := gzip.NewReader(in)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to read the gzip stream: %v", err), http.StatusBadRequest)
+			return
+		}
+		defer gzipReader.Close()
+
+		in = gzipReader
+	}
+
+	if err := bundle.UnpackWithOptions(in, r.target, bundle.UnpackOptions{PreserveSymlinks: true}); err != nil {
+		http.Error(w, fmt.Sprintf("failed to unpack the source code: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	r.received = true
+	w.WriteHeader(http.StatusOK)
+
+	r.done()
+}
+
+// progressReader reports the number of bytes that were read
+type progressReader struct {
+	reader    io.Reader
+	progress  bundle.ProgressFunc
+	processed int64
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.reader.Read(b)
+	p.processed += int64(n)
+	if n > 0 {
+		p.progress(p.processed)
+	}
+
+	return n, err
+}
@@ -0,0 +1,149 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package upload_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/shipwright-io/build/pkg/bundle"
+	. "github.com/shipwright-io/build/pkg/upload"
+)
+
+// sourceStream returns the tar stream of a directory with a single file
+func sourceStream() []byte {
+	directory, err := ioutil.TempDir("", "upload-source")
+	Expect(err).ToNot(HaveOccurred())
+	defer os.RemoveAll(directory)
+
+	Expect(ioutil.WriteFile(filepath.Join(directory, "main.go"), []byte("package main\n"), 0644)).To(Succeed())
+
+	reader, err := bundle.Pack(directory)
+	Expect(err).ToNot(HaveOccurred())
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	Expect(err).ToNot(HaveOccurred())
+	return data
+}
+
+var _ = Describe("Receiver", func() {
+	var (
+		target    string
+		done      int
+		processed int64
+		server    *httptest.Server
+	)
+
+	var put = func(token string, contentEncoding string, body []byte) *http.Response {
+		req, err := http.NewRequest(http.MethodPut, server.URL+ReceiverPath, bytes.NewReader(body))
+		Expect(err).ToNot(HaveOccurred())
+
+		req.Header.Set("Authorization", "Bearer "+token)
+		if contentEncoding != "" {
+			req.Header.Set("Content-Encoding", contentEncoding)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		Expect(err).ToNot(HaveOccurred())
+		defer resp.Body.Close()
+
+		return resp
+	}
+
+	BeforeEach(func() {
+		var err error
+		target, err = ioutil.TempDir("", "upload-target")
+		Expect(err).ToNot(HaveOccurred())
+
+		done = 0
+		processed = 0
+
+		receiver := NewReceiver(target, "secret-token", func() { done++ })
+		receiver.Progress = func(n int64) { processed = n }
+		server = httptest.NewServer(receiver)
+	})
+
+	AfterEach(func() {
+		server.Close()
+		Expect(os.RemoveAll(target)).To(Succeed())
+	})
+
+	It("should unpack the upload into the target directory", func() {
+		resp := put("secret-token", "", sourceStream())
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(done).To(Equal(1))
+
+		content, err := ioutil.ReadFile(filepath.Join(target, "main.go"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(content)).To(Equal("package main\n"))
+	})
+
+	It("should unpack a gzip compressed upload", func() {
+		var buf bytes.Buffer
+		gzipWriter := gzip.NewWriter(&buf)
+		_, err := gzipWriter.Write(sourceStream())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(gzipWriter.Close()).To(Succeed())
+
+		resp := put("secret-token", "gzip", buf.Bytes())
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(filepath.Join(target, "main.go")).To(BeAnExistingFile())
+	})
+
+	It("should report the progress of an upload", func() {
+		body := sourceStream()
+
+		resp := put("secret-token", "", body)
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(processed).To(BeNumerically(">", 0))
+		Expect(processed).To(BeNumerically("<=", len(body)))
+	})
+
+	It("should report the progress of a gzip compressed upload in compressed bytes", func() {
+		var buf bytes.Buffer
+		gzipWriter := gzip.NewWriter(&buf)
+		_, err := gzipWriter.Write(sourceStream())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(gzipWriter.Close()).To(Succeed())
+
+		resp := put("secret-token", "gzip", buf.Bytes())
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(processed).To(BeNumerically(">", 0))
+		Expect(processed).To(BeNumerically("<=", buf.Len()))
+	})
+
+	It("should reject an upload with a wrong token", func() {
+		resp := put("wrong-token", "", sourceStream())
+		Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+		Expect(done).To(Equal(0))
+		Expect(filepath.Join(target, "main.go")).ToNot(BeAnExistingFile())
+	})
+
+	It("should reject an invalid tar stream and accept a repeated upload", func() {
+		resp := put("secret-token", "gzip", []byte("not a gzip stream"))
+		Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		Expect(done).To(Equal(0))
+
+		resp = put("secret-token", "", sourceStream())
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(done).To(Equal(1))
+	})
+
+	It("should reject a second upload", func() {
+		Expect(put("secret-token", "", sourceStream()).StatusCode).To(Equal(http.StatusOK))
+		Expect(put("secret-token", "", sourceStream()).StatusCode).To(Equal(http.StatusConflict))
+		Expect(done).To(Equal(1))
+	})
+})
@@ -0,0 +1,259 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package upload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+
+	buildv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	"github.com/shipwright-io/build/pkg/auth"
+	"github.com/shipwright-io/build/pkg/config"
+	"github.com/shipwright-io/build/pkg/ctxlog"
+	"github.com/shipwright-io/build/pkg/reconciler/buildrun/resources/sources"
+)
+
+// uploadPath matches the path of an upload, for example
+// /namespaces/default/buildruns/sample-build-run/upload
+var uploadPath = regexp.MustCompile(`^/namespaces/([^/]+)/buildruns/([^/]+)/upload$`)
+
+// Server is the endpoint of the controller that receives the source code of
+// the LocalCopy source of a BuildRun, and streams it to the waiter in the
+// build pod. Users need to be allowed to create the upload subresource of
+// the BuildRun, instead of to exec into the build pod.
+type Server struct {
+	// ReceiverPort is the port of the waiter in the build pod
+	ReceiverPort int
+
+	config     config.UploadServerConfig
+	client     client.Client
+	authorizer auth.Authorizer
+	httpClient *http.Client
+}
+
+// NewServer returns the upload endpoint for the configuration
+func NewServer(cfg *config.Config, client client.Client, authorizer auth.Authorizer) *Server {
+	return &Server{
+		ReceiverPort: ReceiverPort,
+		config:       cfg.UploadServer,
+		client:       client,
+		authorizer:   authorizer,
+		httpClient:   &http.Client{},
+	}
+}
+
+// Start serves the upload endpoint until the context is done
+func (s *Server) Start(ctx context.Context) error {
+	ctx = ctxlog.NewContext(ctx, "upload")
+
+	server := &http.Server{
+		Addr:        s.config.Address,
+		Handler:     s,
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = server.Shutdown(context.Background())
+	}()
+
+	ctxlog.Info(ctx, "starting the upload endpoint", "address", s.config.Address)
+
+	var err error
+	switch {
+	case s.config.TLSCertFile != "":
+		err = server.ListenAndServeTLS(s.config.TLSCertFile, s.config.TLSKeyFile)
+	case s.config.Insecure:
+		ctxlog.Info(ctx, "the upload endpoint serves plain HTTP, bearer tokens are sent unencrypted")
+		err = server.ListenAndServe()
+	default:
+		return errors.New("the upload endpoint requires a TLS certificate and key")
+	}
+
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+
+	return err
+}
+
+// NeedLeaderElection returns false, so that every replica of the controller
+// serves uploads
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	match := uploadPath.FindStringSubmatch(req.URL.Path)
+	if match == nil {
+		http.NotFound(w, req)
+		return
+	}
+
+	if req.Method != http.MethodPut && req.Method != http.MethodPost {
+		http.Error(w, "only PUT and POST requests are supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	namespace, name := match[1], match[2]
+
+	token, ok := auth.BearerToken(req)
+	if !ok {
+		http.Error(w, "a bearer token is required", http.StatusUnauthorized)
+		return
+	}
+
+	user, err := s.authorizer.Authorize(ctx, token, namespace, name)
+	switch {
+	case errors.Is(err, auth.ErrUnauthenticated):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+
+	case errors.Is(err, auth.ErrForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+
+	case err != nil:
+		ctxlog.Error(ctx, err, "failed to authorize the upload", "namespace", namespace, "name", name)
+		http.Error(w, "failed to authorize the upload", http.StatusInternalServerError)
+		return
+	}
+
+	receiverURL, receiverToken, status, err := s.receiver(ctx, namespace, name)
+	if err != nil {
+		if status == http.StatusInternalServerError {
+			ctxlog.Error(ctx, err, "failed to find the waiter of the BuildRun", "namespace", namespace, "name", name)
+		}
+
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	ctxlog.Info(ctx, "streaming the upload to the build pod", "namespace", namespace, "name", name, "user", user)
+
+	proxyReq, err := http.NewRequestWithContext(ctx, http.MethodPut, receiverURL, req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	proxyReq.Header.Set("Authorization", "Bearer "+receiverToken)
+	if contentEncoding := req.Header.Get("Content-Encoding"); contentEncoding != "" {
+		proxyReq.Header.Set("Content-Encoding", contentEncoding)
+	}
+
+	resp, err := s.httpClient.Do(proxyReq)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to stream the upload to the build pod: %v", err), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	w.Header().Set("Content-Type", resp.Header.Get("Content-Type"))
+	w.WriteHeader(resp.StatusCode)
+	_, _ = io.Copy(w, resp.Body)
+}
+
+// receiver returns the URL and the token of the waiter of a BuildRun, or an
+// error together with the HTTP status that describes it
+func (s *Server) receiver(ctx context.Context, namespace string, name string) (string, string, int, error) {
+	buildRun := &buildv1alpha1.BuildRun{}
+	if err := s.client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, buildRun); err != nil {
+		return notFoundOrError(err, "BuildRun %s not found", name)
+	}
+
+	if !hasLocalCopySource(buildRun) {
+		return "", "", http.StatusBadRequest, fmt.Errorf("BuildRun %s does not have a LocalCopy source", name)
+	}
+
+	if buildRun.IsDone() {
+		return "", "", http.StatusConflict, fmt.Errorf("BuildRun %s is already completed", name)
+	}
+
+	if buildRun.Status.LatestTaskRunRef == nil {
+		return "", "", http.StatusConflict, fmt.Errorf("BuildRun %s is not waiting for an upload yet", name)
+	}
+
+	taskRun := &pipelinev1beta1.TaskRun{}
+	if err := s.client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: *buildRun.Status.LatestTaskRunRef}, taskRun); err != nil {
+		return notFoundOrError(err, "TaskRun %s not found", *buildRun.Status.LatestTaskRunRef)
+	}
+
+	if taskRun.Status.PodName == "" {
+		return "", "", http.StatusConflict, fmt.Errorf("BuildRun %s is not waiting for an upload yet", name)
+	}
+
+	pod := &corev1.Pod{}
+	if err := s.client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: taskRun.Status.PodName}, pod); err != nil {
+		return notFoundOrError(err, "Pod %s not found", taskRun.Status.PodName)
+	}
+
+	if !isWaiting(pod) {
+		return "", "", http.StatusConflict, fmt.Errorf("BuildRun %s is not waiting for an upload", name)
+	}
+
+	secret := &corev1.Secret{}
+	if err := s.client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: TokenSecretName(buildRun)}, secret); err != nil {
+		return notFoundOrError(err, "BuildRun %s does not accept uploads through this endpoint", name)
+	}
+
+	receiverURL := fmt.Sprintf("http://%s%s", net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(s.ReceiverPort)), ReceiverPath)
+	return receiverURL, string(secret.Data[TokenKey]), http.StatusOK, nil
+}
+
+func notFoundOrError(err error, format string, args ...interface{}) (string, string, int, error) {
+	if apierrors.IsNotFound(err) {
+		return "", "", http.StatusNotFound, fmt.Errorf(format, args...)
+	}
+
+	return "", "", http.StatusInternalServerError, err
+}
+
+func hasLocalCopySource(buildRun *buildv1alpha1.BuildRun) bool {
+	var buildSources []buildv1alpha1.BuildSource
+	if buildRun.Status.BuildSpec != nil {
+		buildSources = append(buildSources, buildRun.Status.BuildSpec.Sources...)
+	}
+
+	for _, source := range append(buildSources, buildRun.Spec.Sources...) {
+		if source.Type == buildv1alpha1.LocalCopy {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isWaiting returns whether the waiter container of the build pod is running
+// and ready to receive the upload
+func isWaiting(pod *corev1.Pod) bool {
+	if pod.Status.PodIP == "" {
+		return false
+	}
+
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if containerStatus.Name == "step-"+sources.WaiterContainerName {
+			return containerStatus.State.Running != nil && containerStatus.Ready
+		}
+	}
+
+	return false
+}
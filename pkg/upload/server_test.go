@@ -0,0 +1,205 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package upload_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	crc "sigs.k8s.io/controller-runtime/pkg/client"
+
+	buildv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	"github.com/shipwright-io/build/pkg/auth"
+	"github.com/shipwright-io/build/pkg/config"
+	"github.com/shipwright-io/build/pkg/controller/fakes"
+	. "github.com/shipwright-io/build/pkg/upload"
+)
+
+type fakeAuthorizer struct {
+	users map[string]error
+}
+
+func (a *fakeAuthorizer) Authorize(_ context.Context, token string, _ string, _ string) (string, error) {
+	err, ok := a.users[token]
+	if !ok {
+		return "", auth.ErrUnauthenticated
+	}
+
+	return token, err
+}
+
+var _ = Describe("Server", func() {
+	var (
+		client      *fakes.FakeClient
+		buildRun    *buildv1alpha1.BuildRun
+		pod         *corev1.Pod
+		target      string
+		receiver    *httptest.Server
+		done        bool
+		uploadToken string
+		server      *httptest.Server
+	)
+
+	var upload = func(token string, body []byte) (int, string) {
+		req, err := http.NewRequest(http.MethodPut, server.URL+"/namespaces/default/buildruns/upload-buildrun/upload", bytes.NewReader(body))
+		Expect(err).ToNot(HaveOccurred())
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := http.DefaultClient.Do(req)
+		Expect(err).ToNot(HaveOccurred())
+		defer resp.Body.Close()
+
+		message, err := ioutil.ReadAll(resp.Body)
+		Expect(err).ToNot(HaveOccurred())
+		return resp.StatusCode, string(message)
+	}
+
+	BeforeEach(func() {
+		var err error
+		target, err = ioutil.TempDir("", "upload-target")
+		Expect(err).ToNot(HaveOccurred())
+
+		done = false
+		uploadToken = "waiter-token"
+		receiver = httptest.NewServer(NewReceiver(target, "waiter-token", func() { done = true }))
+
+		taskRunName := "upload-buildrun-xyz"
+		buildRun = &buildv1alpha1.BuildRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "upload-buildrun", Namespace: "default"},
+			Spec: buildv1alpha1.BuildRunSpec{
+				Sources: []buildv1alpha1.BuildSource{{Name: "local", Type: buildv1alpha1.LocalCopy}},
+			},
+			Status: buildv1alpha1.BuildRunStatus{LatestTaskRunRef: &taskRunName},
+		}
+
+		pod = &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "upload-buildrun-xyz-pod", Namespace: "default"},
+			Status: corev1.PodStatus{
+				PodIP: "127.0.0.1",
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:  "step-source-local",
+					Ready: true,
+					State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+				}},
+			},
+		}
+
+		client = &fakes.FakeClient{}
+		client.GetCalls(func(_ context.Context, nn types.NamespacedName, object crc.Object) error {
+			switch object := object.(type) {
+			case *buildv1alpha1.BuildRun:
+				if nn.Name == buildRun.Name {
+					buildRun.DeepCopyInto(object)
+					return nil
+				}
+
+			case *pipelinev1beta1.TaskRun:
+				if nn.Name == taskRunName {
+					object.Name = taskRunName
+					object.Status.PodName = pod.Name
+					return nil
+				}
+
+			case *corev1.Pod:
+				if nn.Name == pod.Name {
+					pod.DeepCopyInto(object)
+					return nil
+				}
+
+			case *corev1.Secret:
+				if nn.Name == TokenSecretName(buildRun) {
+					object.Data = map[string][]byte{TokenKey: []byte(uploadToken)}
+					return nil
+				}
+			}
+
+			return k8serrors.NewNotFound(schema.GroupResource{}, nn.Name)
+		})
+
+		uploadServer := NewServer(config.NewDefaultConfig(), client, &fakeAuthorizer{users: map[string]error{
+			"developer": nil,
+			"viewer":    fmt.Errorf("%w: not allowed", auth.ErrForbidden),
+		}})
+
+		receiverPort, err := strconv.Atoi(receiver.URL[strings.LastIndex(receiver.URL, ":")+1:])
+		Expect(err).ToNot(HaveOccurred())
+		uploadServer.ReceiverPort = receiverPort
+
+		server = httptest.NewServer(uploadServer)
+	})
+
+	AfterEach(func() {
+		server.Close()
+		receiver.Close()
+		Expect(os.RemoveAll(target)).To(Succeed())
+	})
+
+	It("should stream the upload into the waiting build pod", func() {
+		status, _ := upload("developer", sourceStream())
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(done).To(BeTrue())
+		Expect(filepath.Join(target, "main.go")).To(BeAnExistingFile())
+	})
+
+	It("should reject unauthenticated users", func() {
+		status, _ := upload("unknown", sourceStream())
+		Expect(status).To(Equal(http.StatusUnauthorized))
+		Expect(done).To(BeFalse())
+	})
+
+	It("should reject users that are not allowed to upload", func() {
+		status, _ := upload("viewer", sourceStream())
+		Expect(status).To(Equal(http.StatusForbidden))
+		Expect(done).To(BeFalse())
+	})
+
+	It("should reject uploads for BuildRuns that do not exist", func() {
+		buildRun.Name = "other-buildrun"
+
+		status, message := upload("developer", sourceStream())
+		Expect(status).To(Equal(http.StatusNotFound))
+		Expect(message).To(ContainSubstring("BuildRun upload-buildrun not found"))
+	})
+
+	It("should reject uploads for BuildRuns without a LocalCopy source", func() {
+		buildRun.Spec.Sources = nil
+
+		status, _ := upload("developer", sourceStream())
+		Expect(status).To(Equal(http.StatusBadRequest))
+	})
+
+	It("should reject uploads while the build pod is not waiting", func() {
+		pod.Status.ContainerStatuses[0].Ready = false
+
+		status, _ := upload("developer", sourceStream())
+		Expect(status).To(Equal(http.StatusConflict))
+		Expect(done).To(BeFalse())
+	})
+
+	It("should fail when the upload token does not match the one of the waiter", func() {
+		uploadToken = "another-token"
+
+		status, _ := upload("developer", sourceStream())
+		Expect(status).To(Equal(http.StatusUnauthorized))
+		Expect(done).To(BeFalse())
+	})
+})
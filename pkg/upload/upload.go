@@ -0,0 +1,27 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package upload
+
+import (
+	buildv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+)
+
+const (
+	// ReceiverPort is the port on which the waiter of a LocalCopy source
+	// receives the upload in the build pod
+	ReceiverPort = 8090
+
+	// ReceiverPath is the path of the upload endpoint of the waiter
+	ReceiverPath = "/upload"
+
+	// TokenKey is the key in the upload token Secret that contains the token
+	TokenKey = "token"
+)
+
+// TokenSecretName returns the name of the Secret with the token that the upload
+// endpoint authenticates with at the waiter of the BuildRun
+func TokenSecretName(buildRun *buildv1alpha1.BuildRun) string {
+	return buildRun.Name + "-upload-token"
+}
@@ -0,0 +1,48 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package validate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/utils/pointer"
+
+	build "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+)
+
+// EmbeddedBuildSpecRef contains all required fields
+// to validate the spec of a Build that is embedded in a BuildRun
+type EmbeddedBuildSpecRef struct {
+	Build *build.Build // build instance for analysis
+}
+
+// ValidatePath implements BuildPath interface and validates that the
+// embedded spec does not use the settings that relate several BuildRuns
+// of a Build, which have no Build to act upon
+func (e *EmbeddedBuildSpecRef) ValidatePath(_ context.Context) error {
+	var unsupported []string
+
+	if e.Build.Spec.Schedule != nil {
+		unsupported = append(unsupported, "schedule")
+	}
+
+	if retention := e.Build.Spec.Retention; retention != nil {
+		if retention.FailedLimit != nil {
+			unsupported = append(unsupported, "retention.failedLimit")
+		}
+		if retention.SucceededLimit != nil {
+			unsupported = append(unsupported, "retention.succeededLimit")
+		}
+	}
+
+	if len(unsupported) > 0 {
+		e.Build.Status.Reason = build.BuildReasonPtr(build.EmbeddedBuildSpecUnsupported)
+		e.Build.Status.Message = pointer.String(fmt.Sprintf("%s can only be used in a Build, not in the buildSpec of a BuildRun", strings.Join(unsupported, ", ")))
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+package validate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	build "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+)
+
+func TestEmbeddedBuildSpecRef_ValidatePath(t *testing.T) {
+	limit := uint(3)
+
+	testCases := []struct {
+		description     string
+		expectSupported bool
+		b               *build.Build
+	}{{
+		description:     "no schedule and no retention",
+		expectSupported: true,
+		b:               &build.Build{},
+	}, {
+		description:     "retention with TTLs only",
+		expectSupported: true,
+		b: &build.Build{Spec: build.BuildSpec{Retention: &build.BuildRetention{
+			TtlAfterFailed: &metav1.Duration{Duration: time.Hour},
+		}}},
+	}, {
+		description:     "schedule",
+		expectSupported: false,
+		b: &build.Build{Spec: build.BuildSpec{Schedule: &build.BuildSchedule{
+			Cron: "0 2 * * *",
+		}}},
+	}, {
+		description:     "failed limit",
+		expectSupported: false,
+		b: &build.Build{Spec: build.BuildSpec{Retention: &build.BuildRetention{
+			FailedLimit: &limit,
+		}}},
+	}, {
+		description:     "succeeded limit",
+		expectSupported: false,
+		b: &build.Build{Spec: build.BuildSpec{Retention: &build.BuildRetention{
+			SucceededLimit: &limit,
+		}}},
+	}}
+
+	for _, tc := range testCases {
+		e := &EmbeddedBuildSpecRef{Build: tc.b}
+		if err := e.ValidatePath(context.TODO()); err != nil {
+			t.Fatalf("%s: unexpected error '%v'", tc.description, err)
+		}
+
+		if tc.expectSupported && tc.b.Status.Reason != nil {
+			t.Fatalf("%s: expected no reason, got %s", tc.description, *tc.b.Status.Reason)
+		}
+
+		if !tc.expectSupported && (tc.b.Status.Reason == nil || *tc.b.Status.Reason != build.EmbeddedBuildSpecUnsupported) {
+			t.Fatalf("%s: expected reason %s, got %v", tc.description, build.EmbeddedBuildSpecUnsupported, tc.b.Status.Reason)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package validate
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/utils/pointer"
+
+	build "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	"github.com/shipwright-io/build/pkg/cron"
+)
+
+// ScheduleRef contains all required fields
+// to validate the schedule of a Build
+type ScheduleRef struct {
+	Build *build.Build // build instance for analysis
+}
+
+// ValidatePath implements BuildPath interface and validates
+// the cron syntax and the time zone of `spec.schedule`
+func (s *ScheduleRef) ValidatePath(_ context.Context) error {
+	schedule := s.Build.Spec.Schedule
+	if schedule == nil {
+		return nil
+	}
+
+	parsed, err := cron.ParseInLocation(schedule.Cron, pointer.StringDeref(schedule.TimeZone, ""))
+	if err != nil {
+		return s.invalid(err.Error())
+	}
+
+	if parsed.Next(time.Now()).IsZero() {
+		return s.invalid(fmt.Sprintf("schedule %q never matches", schedule.Cron))
+	}
+
+	return nil
+}
+
+func (s *ScheduleRef) invalid(message string) error {
+	s.Build.Status.Reason = build.BuildReasonPtr(build.ScheduleInvalid)
+	s.Build.Status.Message = pointer.String(message)
+	return fmt.Errorf("%s", message)
+}
@@ -0,0 +1,64 @@
+// Copyright The Shipwright Contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+package validate
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/utils/pointer"
+
+	build "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+)
+
+func TestScheduleRef_ValidatePath(t *testing.T) {
+	testCases := []struct {
+		description string
+		expectError bool
+		b           *build.Build
+	}{{
+		description: "no schedule",
+		expectError: false,
+		b:           &build.Build{},
+	}, {
+		description: "nightly schedule in a time zone",
+		expectError: false,
+		b: &build.Build{Spec: build.BuildSpec{Schedule: &build.BuildSchedule{
+			Cron:     "0 2 * * *",
+			TimeZone: pointer.String("Europe/Berlin"),
+		}}},
+	}, {
+		description: "invalid cron syntax",
+		expectError: true,
+		b: &build.Build{Spec: build.BuildSpec{Schedule: &build.BuildSchedule{
+			Cron: "every night",
+		}}},
+	}, {
+		description: "unknown time zone",
+		expectError: true,
+		b: &build.Build{Spec: build.BuildSpec{Schedule: &build.BuildSchedule{
+			Cron:     "0 2 * * *",
+			TimeZone: pointer.String("Mars/Olympus_Mons"),
+		}}},
+	}, {
+		description: "schedule that never matches",
+		expectError: true,
+		b: &build.Build{Spec: build.BuildSpec{Schedule: &build.BuildSchedule{
+			Cron: "0 0 31 2 *",
+		}}},
+	}}
+
+	for _, tc := range testCases {
+		s := &ScheduleRef{Build: tc.b}
+		err := s.ValidatePath(context.TODO())
+
+		if (tc.expectError && err == nil) || (!tc.expectError && err != nil) {
+			t.Fatalf("%s: expectError='%v', err='%v'", tc.description, tc.expectError, err)
+		}
+
+		if tc.expectError && *tc.b.Status.Reason != build.ScheduleInvalid {
+			t.Fatalf("%s: expected reason %s, got %s", tc.description, build.ScheduleInvalid, *tc.b.Status.Reason)
+		}
+	}
+}
@@ -62,8 +62,16 @@ func (s Credentials) buildCredentialserences() map[string]build.BuildReason {
 	if s.Build.Spec.Source.Credentials != nil && s.Build.Spec.Source.Credentials.Name != "" {
 		secretRefMap[s.Build.Spec.Source.Credentials.Name] = build.SpecSourceSecretRefNotFound
 	}
+	if s.Build.Spec.Source.BundleContainer != nil && s.Build.Spec.Source.BundleContainer.PublicKey != nil && s.Build.Spec.Source.BundleContainer.PublicKey.Secret != "" {
+		secretRefMap[s.Build.Spec.Source.BundleContainer.PublicKey.Secret] = build.SpecSourceSecretRefNotFound
+	}
 	if s.Build.Spec.Builder != nil && s.Build.Spec.Builder.Credentials != nil && s.Build.Spec.Builder.Credentials.Name != "" {
 		secretRefMap[s.Build.Spec.Builder.Credentials.Name] = build.SpecBuilderSecretRefNotFound
 	}
+	for _, source := range s.Build.Spec.Sources {
+		if source.Credentials != nil && source.Credentials.Name != "" {
+			secretRefMap[source.Credentials.Name] = build.SpecSourceSecretRefNotFound
+		}
+	}
 	return secretRefMap
 }
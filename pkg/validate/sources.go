@@ -8,8 +8,23 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"path"
+	"regexp"
+	"strings"
 
 	build "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	"github.com/shipwright-io/build/pkg/reconciler/buildrun/resources/sources"
+)
+
+var (
+	sha256Digest = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
+	// scpLikeURL matches the short SSH syntax of Git, for example git@github.com:shipwright-io/build.git
+	scpLikeURL = regexp.MustCompile(`^[a-zA-Z0-9._-]+@[a-zA-Z0-9.-]+:[^/]`)
+
+	// reservedSourceNames are the names of the steps for spec.source and the LocalCopy upload,
+	// which the steps and results of the other sources must not collide with
+	reservedSourceNames = map[string]struct{}{"default": {}, "local": {}}
 )
 
 // SourcesRef implements RuntimeRef interface to add validations for `build.spec.sources` slice.
@@ -20,10 +35,28 @@ type SourcesRef struct {
 // ValidatePath executes the validation routine, inspecting the `build.spec.sources` path, which
 // contains a slice of BuildSource.
 func (s *SourcesRef) ValidatePath(_ context.Context) error {
+	names := map[string]struct{}{}
+	localCopies := 0
+
 	for _, source := range s.Build.Spec.Sources {
 		if err := s.validateSourceEntry(source); err != nil {
 			return err
 		}
+
+		// step and result names are derived from the sanitized name, which must be unique
+		name := sources.SanitizeSourceName(source.Name)
+		if _, exists := names[name]; exists {
+			return fmt.Errorf("name %q must be unique, it collides with another source named %q after sanitizing", source.Name, name)
+		}
+		names[name] = struct{}{}
+
+		if source.Type == build.LocalCopy {
+			localCopies++
+		}
+	}
+
+	if localCopies > 1 {
+		return fmt.Errorf("only one source of type %s is supported", build.LocalCopy)
 	}
 	return nil
 }
@@ -33,11 +66,67 @@ func (s *SourcesRef) validateSourceEntry(source build.BuildSource) error {
 	if source.Name == "" {
 		return fmt.Errorf("name must be informed")
 	}
+	if err := validateReservedName(source); err != nil {
+		return err
+	}
+	if source.Type == build.Git {
+		return s.validateGitSourceEntry(source)
+	}
+	// the source code of a LocalCopy source is uploaded, it does not have a URL
+	if source.Type != build.LocalCopy {
+		if source.URL == "" {
+			return fmt.Errorf("URL must be informed")
+		}
+		if _, err := url.ParseRequestURI(source.URL); err != nil {
+			return err
+		}
+	}
+	if source.Digest != "" && !sha256Digest.MatchString(source.Digest) {
+		return fmt.Errorf("digest %q must be in the format sha256:<hex>", source.Digest)
+	}
+	return validateTargetPath(source.TargetPath)
+}
+
+// validateGitSourceEntry inspects an additional Git repository, which is cloned into its own
+// sub-directory, because the repository of spec.source is cloned into the source root.
+func (s *SourcesRef) validateGitSourceEntry(source build.BuildSource) error {
 	if source.URL == "" {
 		return fmt.Errorf("URL must be informed")
 	}
-	if _, err := url.ParseRequestURI(source.URL); err != nil {
-		return err
+	if !scpLikeURL.MatchString(source.URL) {
+		if _, err := url.ParseRequestURI(source.URL); err != nil {
+			return err
+		}
+	}
+	if source.Digest != "" || source.Extract != nil {
+		return fmt.Errorf("digest and extract are not supported for sources of type %s", build.Git)
+	}
+	if source.TargetPath == "" || path.Clean(source.TargetPath) == "." {
+		return fmt.Errorf("target path must be informed for sources of type %s", build.Git)
+	}
+	return validateTargetPath(source.TargetPath)
+}
+
+// validateReservedName ensures that a source does not take the name of the spec.source step, a
+// LocalCopy source runs as the `source-local` step and may therefore be named `local`
+func validateReservedName(source build.BuildSource) error {
+	name := sources.SanitizeSourceName(source.Name)
+	if _, reserved := reservedSourceNames[name]; !reserved {
+		return nil
+	}
+	if source.Type == build.LocalCopy && name == "local" {
+		return nil
+	}
+	return fmt.Errorf("name %q is reserved", source.Name)
+}
+
+// validateTargetPath ensures that a target path stays inside of the source directory
+func validateTargetPath(targetPath string) error {
+	if targetPath != "" {
+		cleaned := path.Clean(targetPath)
+		if path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+			return fmt.Errorf("target path %q must be relative and inside of the source directory", targetPath)
+		}
 	}
 	return nil
 }
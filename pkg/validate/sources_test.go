@@ -39,6 +39,149 @@ func TestSourcesRef_ValidatePath(t *testing.T) {
 			Name: "name",
 			URL:  "invalid URL",
 		}}}},
+	}, {
+		description: "valid digest and target path",
+		expectError: false,
+		b: &build.Build{Spec: build.BuildSpec{Sources: []build.BuildSource{{
+			Name:       "name",
+			URL:        "https://shipwright.io/icons/logo.svg",
+			Digest:     "sha256:0d6b4c3e6fc0a1f4ea9ef1c3e88b5fcbd2a1b6e4f6f39c6cd4c8a5b1b0a3e7f2",
+			TargetPath: "assets/icons",
+		}}}},
+	}, {
+		description: "invalid digest",
+		expectError: true,
+		b: &build.Build{Spec: build.BuildSpec{Sources: []build.BuildSource{{
+			Name:   "name",
+			URL:    "https://shipwright.io/icons/logo.svg",
+			Digest: "md5:d41d8cd98f00b204e9800998ecf8427e",
+		}}}},
+	}, {
+		description: "target path outside of the source directory",
+		expectError: true,
+		b: &build.Build{Spec: build.BuildSpec{Sources: []build.BuildSource{{
+			Name:       "name",
+			URL:        "https://shipwright.io/icons/logo.svg",
+			TargetPath: "../outside",
+		}}}},
+	}, {
+		description: "absolute target path",
+		expectError: true,
+		b: &build.Build{Spec: build.BuildSpec{Sources: []build.BuildSource{{
+			Name:       "name",
+			URL:        "https://shipwright.io/icons/logo.svg",
+			TargetPath: "/etc",
+		}}}},
+	}, {
+		description: "LocalCopy source without URL",
+		expectError: false,
+		b: &build.Build{Spec: build.BuildSpec{Sources: []build.BuildSource{{
+			Name:       "local",
+			Type:       build.LocalCopy,
+			TargetPath: "overlay",
+		}}}},
+	}, {
+		description: "more than one LocalCopy source",
+		expectError: true,
+		b: &build.Build{Spec: build.BuildSpec{Sources: []build.BuildSource{{
+			Name: "local",
+			Type: build.LocalCopy,
+		}, {
+			Name: "other",
+			Type: build.LocalCopy,
+		}}}},
+	}, {
+		description: "duplicate source names",
+		expectError: true,
+		b: &build.Build{Spec: build.BuildSpec{Sources: []build.BuildSource{{
+			Name: "name",
+			URL:  "https://shipwright.io/icons/logo.svg",
+		}, {
+			Name: "name",
+			URL:  "https://shipwright.io/icons/favicon.ico",
+		}}}},
+	}, {
+		description: "source names that are equal after sanitizing",
+		expectError: true,
+		b: &build.Build{Spec: build.BuildSpec{Sources: []build.BuildSource{{
+			Name: "Foo_Bar",
+			URL:  "https://shipwright.io/icons/logo.svg",
+		}, {
+			Name: "foo-bar",
+			URL:  "https://shipwright.io/icons/favicon.ico",
+		}}}},
+	}, {
+		description: "HTTP source with the reserved name default",
+		expectError: true,
+		b: &build.Build{Spec: build.BuildSpec{Sources: []build.BuildSource{{
+			Name: "default",
+			URL:  "https://shipwright.io/icons/logo.svg",
+		}}}},
+	}, {
+		description: "HTTP source with the reserved name local",
+		expectError: true,
+		b: &build.Build{Spec: build.BuildSpec{Sources: []build.BuildSource{{
+			Name: "Local",
+			Type: build.HTTP,
+			URL:  "https://shipwright.io/icons/logo.svg",
+		}}}},
+	}, {
+		description: "LocalCopy source with the reserved name default",
+		expectError: true,
+		b: &build.Build{Spec: build.BuildSpec{Sources: []build.BuildSource{{
+			Name: "default",
+			Type: build.LocalCopy,
+		}}}},
+	}, {
+		description: "Git sources with HTTPS and SSH URLs",
+		expectError: false,
+		b: &build.Build{Spec: build.BuildSpec{Sources: []build.BuildSource{{
+			Name:       "docs",
+			Type:       build.Git,
+			URL:        "https://github.com/shipwright-io/website",
+			TargetPath: "docs",
+		}, {
+			Name:       "tools",
+			Type:       build.Git,
+			URL:        "git@github.com:shipwright-io/cli.git",
+			TargetPath: "third_party/tools",
+		}}}},
+	}, {
+		description: "Git source without target path",
+		expectError: true,
+		b: &build.Build{Spec: build.BuildSpec{Sources: []build.BuildSource{{
+			Name: "docs",
+			Type: build.Git,
+			URL:  "https://github.com/shipwright-io/website",
+		}}}},
+	}, {
+		description: "Git source cloned into the source root",
+		expectError: true,
+		b: &build.Build{Spec: build.BuildSpec{Sources: []build.BuildSource{{
+			Name:       "docs",
+			Type:       build.Git,
+			URL:        "https://github.com/shipwright-io/website",
+			TargetPath: "./",
+		}}}},
+	}, {
+		description: "Git source with a reserved name",
+		expectError: true,
+		b: &build.Build{Spec: build.BuildSpec{Sources: []build.BuildSource{{
+			Name:       "default",
+			Type:       build.Git,
+			URL:        "https://github.com/shipwright-io/website",
+			TargetPath: "docs",
+		}}}},
+	}, {
+		description: "Git source with a digest",
+		expectError: true,
+		b: &build.Build{Spec: build.BuildSpec{Sources: []build.BuildSource{{
+			Name:       "docs",
+			Type:       build.Git,
+			URL:        "https://github.com/shipwright-io/website",
+			Digest:     "sha256:0d6b4c3e6fc0a1f4ea9ef1c3e88b5fcbd2a1b6e4f6f39c6cd4c8a5b1b0a3e7f2",
+			TargetPath: "docs",
+		}}}},
 	}}
 
 	for _, tc := range testCases {
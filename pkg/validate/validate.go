@@ -8,10 +8,13 @@ import (
 	"context"
 	"fmt"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	build "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	"github.com/shipwright-io/build/pkg/ctxlog"
 )
 
 const (
@@ -29,11 +32,16 @@ const (
 	Envs = "env"
 	//Retention for validating spec.retention
 	Retention = "retention"
+	// Schedule for validating `spec.schedule`
+	Schedule = "schedule"
 	// OwnerReferences for validating the ownerreferences between a Build
 	// and BuildRun objects
 	OwnerReferences = "ownerreferences"
-	namespace       = "namespace"
-	name            = "name"
+	// EmbeddedBuildSpec for validating that the spec of a Build that is
+	// embedded in a BuildRun only uses settings that apply to it
+	EmbeddedBuildSpec = "embeddedbuildspec"
+	namespace         = "namespace"
+	name              = "name"
 )
 
 // BuildPath is an interface that holds a ValidatePath() function
@@ -67,7 +75,73 @@ func NewValidation(
 		return &Env{Build: build}, nil
 	case Retention:
 		return &Env{Build: build}, nil
+	case Schedule:
+		return &ScheduleRef{Build: build}, nil
+	case EmbeddedBuildSpec:
+		return &EmbeddedBuildSpecRef{Build: build}, nil
 	default:
 		return nil, fmt.Errorf("unknown validation type")
 	}
 }
+
+// specValidations are the validations of the spec of a Build, regardless of
+// whether it exists in the cluster or is embedded in a BuildRun
+var specValidations = []string{
+	SourceURL,
+	Secrets,
+	Strategies,
+	Sources,
+	BuildName,
+	Envs,
+	Retention,
+	Schedule,
+}
+
+// ValidateBuild runs all validations of a Build that exists in the cluster,
+// and records the result in the status of the Build
+func ValidateBuild(ctx context.Context, b *build.Build, client client.Client, scheme *runtime.Scheme) error {
+	return validateBuild(ctx, b, client, scheme, append([]string{OwnerReferences}, specValidations...))
+}
+
+// ValidateEmbeddedBuild runs all validations of the spec of a Build that is
+// embedded in a BuildRun, and records the result in the status of the Build
+func ValidateEmbeddedBuild(ctx context.Context, b *build.Build, client client.Client, scheme *runtime.Scheme) error {
+	return validateBuild(ctx, b, client, scheme, append([]string{EmbeddedBuildSpec}, specValidations...))
+}
+
+// validateBuild runs the validations until one of them sets a failure reason
+// in the status of the Build. An error is only returned for the validations
+// that fail because of an API call, so that the object is reconciled again.
+func validateBuild(ctx context.Context, b *build.Build, client client.Client, scheme *runtime.Scheme, validationTypes []string) error {
+	// Populate the status struct with default values
+	b.Status.Registered = build.ConditionStatusPtr(corev1.ConditionFalse)
+	b.Status.Reason = build.BuildReasonPtr(build.SucceedStatus)
+
+	for _, validationType := range validationTypes {
+		v, err := NewValidation(validationType, b, client, scheme)
+		if err != nil {
+			// when the validation type is unknown
+			return err
+		}
+
+		if err := v.ValidatePath(ctx); err != nil {
+			if validationType == Secrets || validationType == Strategies {
+				return err
+			}
+			if validationType == OwnerReferences {
+				// we do not want to bail out here if the owerreference validation fails, we ignore this error on purpose
+				// In case we just created the Build, we want the Build reconcile logic to continue, in order to
+				// validate the Build references ( e.g secrets, strategies )
+				ctxlog.Info(ctx, "unexpected error during ownership reference validation", namespace, b.Namespace, name, b.Name, "error", err)
+			}
+		}
+
+		if b.Status.Reason == nil || *b.Status.Reason != build.SucceedStatus {
+			return nil
+		}
+	}
+
+	b.Status.Registered = build.ConditionStatusPtr(corev1.ConditionTrue)
+	b.Status.Message = pointer.String(build.AllValidationsSucceeded)
+	return nil
+}
@@ -83,6 +83,26 @@ spec:
       "org.opencontainers.image.url": https://my-company.com/images
 `
 
+// BuildahBuildWithSourceAnnotations defines a Build with
+// a source, strategy, output and the OCI standard source
+// annotations enabled
+const BuildahBuildWithSourceAnnotations = `
+apiVersion: shipwright.io/v1alpha1
+kind: Build
+metadata:
+  name: buildah
+spec:
+  source:
+    url: "https://github.com/shipwright-io/sample-go"
+  strategy:
+    name: buildah
+    kind: ClusterBuildStrategy
+  dockerfile: Dockerfile
+  output:
+    image: image-registry.openshift-image-registry.svc:5000/example/buildpacks-app
+    sourceAnnotations: true
+`
+
 // BuildahBuildWithMultipleAnnotationAndLabel defines a
 // Build with a source, strategy, output,
 // multiple annotations and labels
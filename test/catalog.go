@@ -758,7 +758,7 @@ func (c *Catalog) DefaultBuildRun(buildRunName string, buildName string) *build.
 			Name: buildRunName,
 		},
 		Spec: build.BuildRunSpec{
-			BuildRef: build.BuildRef{
+			BuildRef: &build.BuildRef{
 				Name: buildName,
 			},
 		},
@@ -803,7 +803,7 @@ func (c *Catalog) BuildRunWithBuildSnapshot(buildRunName string, buildName strin
 			},
 		},
 		Spec: build.BuildRunSpec{
-			BuildRef: build.BuildRef{
+			BuildRef: &build.BuildRef{
 				Name: buildName,
 			},
 		},
@@ -828,7 +828,7 @@ func (c *Catalog) BuildRunWithExistingOwnerReferences(buildRunName string, build
 			OwnerReferences: []metav1.OwnerReference{fakeOwnerRef},
 		},
 		Spec: build.BuildRunSpec{
-			BuildRef: build.BuildRef{
+			BuildRef: &build.BuildRef{
 				Name: buildName,
 			},
 		},
@@ -844,7 +844,7 @@ func (c *Catalog) BuildRunWithFakeNamespace(buildRunName string, buildName strin
 			Namespace: "foobarns",
 		},
 		Spec: build.BuildRunSpec{
-			BuildRef: build.BuildRef{
+			BuildRef: &build.BuildRef{
 				Name: buildName,
 			},
 		},
@@ -933,7 +933,7 @@ func (c *Catalog) BuildRunWithSA(buildRunName string, buildName string, saName s
 			Name: buildRunName,
 		},
 		Spec: build.BuildRunSpec{
-			BuildRef: build.BuildRef{
+			BuildRef: &build.BuildRef{
 				Name: buildName,
 			},
 			ServiceAccount: &build.ServiceAccount{
@@ -952,7 +952,7 @@ func (c *Catalog) BuildRunWithoutSA(buildRunName string, buildName string) *buil
 			Name: buildRunName,
 		},
 		Spec: build.BuildRunSpec{
-			BuildRef: build.BuildRef{
+			BuildRef: &build.BuildRef{
 				Name: buildName,
 			},
 			ServiceAccount: &build.ServiceAccount{
@@ -970,7 +970,7 @@ func (c *Catalog) BuildRunWithSAGenerate(buildRunName string, buildName string)
 			Name: buildRunName,
 		},
 		Spec: build.BuildRunSpec{
-			BuildRef: build.BuildRef{
+			BuildRef: &build.BuildRef{
 				Name: buildName,
 			},
 			ServiceAccount: &build.ServiceAccount{
@@ -1035,6 +1035,9 @@ func (c *Catalog) LoadBRWithNameAndRef(name string, buildRef string, d []byte) (
 		return nil, err
 	}
 	b.Name = name
+	if b.Spec.BuildRef == nil {
+		b.Spec.BuildRef = &build.BuildRef{}
+	}
 	b.Spec.BuildRef.Name = buildRef
 	return b, nil
 }
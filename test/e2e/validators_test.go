@@ -330,6 +330,9 @@ func buildRunTestData(ns string, identifier string, filePath string) (*buildv1al
 
 	buildRun.SetNamespace(ns)
 	buildRun.SetName(identifier)
+	if buildRun.Spec.BuildRef == nil {
+		return nil, fmt.Errorf("the BuildRun in %s does not reference a Build", filePath)
+	}
 	buildRun.Spec.BuildRef.Name = identifier
 
 	serviceAccountName := os.Getenv(EnvVarServiceAccountName)